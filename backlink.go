@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/sorg/modules/slinkcheck"
+	"github.com/brandur/sorg/modules/sredirect"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Name of the file in the target directory that the backlinks that pages
+// were last rendered with are persisted to, like dependencyCacheFilename.
+const backlinksFilename = ".backlinks.json"
+
+// collectBacklinks extracts the internal links from the body of every
+// article, atom, fragment, and newsletter issue to produce the backlinks of
+// each one. Links to the old path of something that's been redirected count
+// towards wherever it redirects to, and content linking to itself isn't a
+// backlink. Each content's backlinks are sorted newest first.
+func collectBacklinks(articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, redirects []*sredirect.Redirect,
+) (contentLinkGraph, error) {
+	redirectsByFrom := make(map[string]string, len(redirects))
+	for _, redirect := range redirects {
+		redirectsByFrom[redirect.From] = redirect.To
+	}
+
+	graph := make(contentLinkGraph)
+
+	add := func(link *contentLink, bodies ...string) error {
+		seen := make(map[string]struct{})
+
+		for _, body := range bodies {
+			doc, err := slinkcheck.Parse(strings.NewReader(body))
+			if err != nil {
+				return xerrors.Errorf("error extracting links from %s: %w", link.URL, err)
+			}
+
+			for _, href := range doc.Links {
+				target, ok := internalLinkPath(link.URL, href)
+				if !ok {
+					continue
+				}
+
+				if to, ok := redirectsByFrom[target]; ok {
+					if toPath, ok := internalLinkPath("/", to); ok {
+						target = toPath
+					}
+				}
+
+				if target == link.URL {
+					continue
+				}
+
+				if _, ok := seen[target]; ok {
+					continue
+				}
+				seen[target] = struct{}{}
+
+				graph[target] = append(graph[target], link)
+			}
+		}
+
+		return nil
+	}
+
+	for _, article := range articles {
+		err := add(&contentLink{
+			Kind:        "Article",
+			PublishedAt: article.PublishedAt,
+			Title:       article.Title,
+			URL:         "/" + article.Slug,
+		}, string(article.Content), string(article.Footnotes))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, atom := range atoms {
+		err := add(&contentLink{
+			Kind:        "Atom",
+			PublishedAt: atom.PublishedAt,
+			Title:       atom.displayTitle(),
+			URL:         "/atoms/" + atom.Slug,
+		}, string(atom.DescriptionHTML))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fragment := range fragments {
+		err := add(&contentLink{
+			Kind:        "Fragment",
+			PublishedAt: fragment.PublishedAt,
+			Title:       fragment.Title,
+			URL:         "/fragments/" + fragment.Slug,
+		}, string(fragment.Content), string(fragment.Footnotes))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			err := add(&contentLink{
+				Kind:        nb.newsletter.Name,
+				PublishedAt: issue.PublishedAt,
+				Title:       issue.Title,
+				URL:         "/" + nb.newsletter.Slug + "/" + issue.Slug,
+			}, string(issue.Content))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, links := range graph {
+		slices.SortStableFunc(links, func(a, b *contentLink) int { return b.PublishedAt.Compare(a.PublishedAt) })
+	}
+
+	return graph, nil
+}
+
+// internalLinkPath gets the path on the site that a link found in the content
+// at the given path points to, without any query or fragment. Returns false if
+// the link is to another site, or isn't a link to a page at all, like a
+// `mailto:` link or one to a fragment in the same page.
+func internalLinkPath(contentPath, href string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	// A link to our own site by its absolute URL is internal like any other.
+	case u.Scheme == "http" || u.Scheme == "https":
+		siteURL, err := url.Parse(conf.AbsoluteURL)
+		if err != nil || u.Host != siteURL.Host {
+			return "", false
+		}
+
+	case u.Scheme != "" || u.Host != "":
+		return "", false
+	}
+
+	if u.Path == "" {
+		return "", false
+	}
+
+	linkPath := (&url.URL{Path: contentPath}).ResolveReference(&url.URL{Path: u.Path}).Path
+	if linkPath != "/" {
+		linkPath = strings.TrimSuffix(linkPath, "/")
+	}
+
+	return linkPath, true
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"html/template"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/sorg/modules/snewsletter"
+	"github.com/brandur/sorg/modules/sredirect"
+)
+
+func TestCollectBacklinks(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.AbsoluteURL = "https://example.com"
+
+	atomTitle := "Atom"
+
+	articles := []*Article{
+		{
+			Content: template.HTML(`<p><a href="/fragments/queues">Queues</a>, ` +
+				`<a href="https://example.com/fragments/queues/">again</a>, ` +
+				`<a href="/postgres">itself</a>, and <a href="#section">a section</a>.</p>`),
+			Footnotes:   template.HTML(`<p><a href="https://postgresql.org">Postgres</a></p>`),
+			PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "postgres",
+			Title:       "Postgres",
+		},
+	}
+	atoms := []*Atom{
+		{
+			DescriptionHTML: template.HTML(`<p><a href="/mvuss">Old link</a></p>`),
+			PublishedAt:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			Slug:            "2026-02-01",
+			Title:           &atomTitle,
+		},
+	}
+	fragments := []*Fragment{
+		{
+			Content:     template.HTML(`<p><a href="../postgres">Postgres</a></p>`),
+			PublishedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "queues",
+			Title:       "Queues",
+		},
+	}
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{
+					Content:     template.HTML(`<p><a href="https://example.com/fragments/queues?ref=nanoglyphs">Queues</a></p>`),
+					PublishedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+					Slug:        "001",
+					Title:       "First",
+				},
+			},
+			newsletter: &Newsletter{Name: "Nanoglyph", Slug: "nanoglyphs"},
+		},
+	}
+	redirects := []*sredirect.Redirect{{From: "/mvuss", To: "/minimum-viable-unit"}}
+
+	graph, err := collectBacklinks(articles, atoms, fragments, newsletterBuilds, redirects)
+	assert.NoError(t, err)
+
+	urls := func(links []*contentLink) []string {
+		var urls []string
+		for _, link := range links {
+			urls = append(urls, link.URL)
+		}
+		return urls
+	}
+
+	// Newest first, and only once per linking page.
+	assert.Equal(t, []string{"/nanoglyphs/001", "/postgres"}, urls(graph["/fragments/queues"]))
+	assert.Equal(t, "Nanoglyph", graph["/fragments/queues"][0].Kind)
+
+	assert.Equal(t, []string{"/fragments/queues"}, urls(graph["/postgres"]))
+
+	// Links to a redirect count towards where it redirects to.
+	assert.Equal(t, []string{"/atoms/2026-02-01"}, urls(graph["/minimum-viable-unit"]))
+	assert.Equal(t, "Atom", graph["/minimum-viable-unit"][0].Title)
+	assert.NotContains(t, graph, "/mvuss")
+
+	assert.Len(t, graph, 3)
+}
+
+func TestInternalLinkPath(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.AbsoluteURL = "https://example.com"
+
+	for _, tc := range []struct {
+		href     string
+		expected string
+	}{
+		{"/fragments/queues", "/fragments/queues"},
+		{"/fragments/queues/", "/fragments/queues"},
+		{"/fragments/queues#section", "/fragments/queues"},
+		{"/", "/"},
+		{"idempotency-keys", "/fragments/idempotency-keys"},
+		{"../postgres", "/postgres"},
+		{"https://example.com/postgres?ref=feed", "/postgres"},
+		{"http://example.com/postgres", "/postgres"},
+		{"https://postgresql.org/docs", ""},
+		{"//postgresql.org/docs", ""},
+		{"mailto:brandur@example.com", ""},
+		{"#section", ""},
+	} {
+		t.Run(tc.href, func(t *testing.T) {
+			linkPath, ok := internalLinkPath("/fragments/queues", tc.href)
+			assert.Equal(t, tc.expected != "", ok)
+			assert.Equal(t, tc.expected, linkPath)
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base32"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -28,7 +30,6 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/brandur/modulir"
-	"github.com/brandur/modulir/modules/matom"
 	"github.com/brandur/modulir/modules/mfile"
 	"github.com/brandur/modulir/modules/mimage"
 	"github.com/brandur/modulir/modules/mmarkdown"
@@ -36,9 +37,16 @@ import (
 	"github.com/brandur/modulir/modules/mtemplate"
 	"github.com/brandur/modulir/modules/mtoc"
 	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/sassets"
+	"github.com/brandur/sorg/modules/scard"
 	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/scompress"
+	"github.com/brandur/sorg/modules/sminify"
 	"github.com/brandur/sorg/modules/snewsletter"
+	"github.com/brandur/sorg/modules/splaintext"
 	"github.com/brandur/sorg/modules/squantified"
+	"github.com/brandur/sorg/modules/sredirect"
+	"github.com/brandur/sorg/modules/sstats"
 	"github.com/brandur/sorg/modules/stemplate"
 )
 
@@ -52,11 +60,23 @@ import (
 //
 //////////////////////////////////////////////////////////////////////////////
 
-// A set of tag constants to hopefully help ensure that this set doesn't grow
-// very much.
-const (
-	tagPostgres Tag = "postgres"
-)
+// Name of the file in the target directory that the dependency registry is
+// persisted to between builds. It lives alongside build output so that it's
+// removed along with it, and a cleaned target always gets a full build.
+const dependencyCacheFilename = ".dependencies.json"
+
+// Name of the file in the target directory that tracks which built files
+// have been compressed, like dependencyCacheFilename.
+const compressManifestFilename = ".compressed.json"
+
+// Location of newsletter definitions, relative to the source directory.
+const newslettersFilename = "content/newsletters.toml"
+
+// Location of the series allowlist, relative to the source directory.
+const seriesFilename = "content/series.toml"
+
+// Location of the tag allowlist, relative to the source directory.
+const tagsFilename = "content/tags.toml"
 
 //////////////////////////////////////////////////////////////////////////////
 //
@@ -73,17 +93,24 @@ const (
 // reparsing all the source material. In each case we try to only reparse the
 // sources if those source files actually changed.
 var (
-	articles     []*Article
-	atoms        []*Atom
-	dependencies = NewDependencyRegistry()
-	fragments    []*Fragment
-	nanoglyphs   []*snewsletter.Issue
-	passages     []*snewsletter.Issue
-	pages        = make(map[string]*Page)
-	photos       []*Photo
-	photosOther  []*Photo
-	sequences    []*SequenceEntry
-	tweets       []*squantified.Tweet
+	articles          []*Article
+	atoms             []*Atom
+	backlinks         contentLinkGraph // as pages were last rendered with
+	compressManifest  *scompress.Manifest
+	dependencies      = NewDependencyRegistry()
+	fragments         []*Fragment
+	minifier          *sminify.Minifier                       // nil unless minification is enabled
+	newsletterIssues  = make(map[string][]*snewsletter.Issue) // keyed by newsletter slug
+	newsletters       []*Newsletter
+	pages             = make(map[string]*Page)
+	photos            []*Photo
+	photosOther       []*Photo
+	redirects         []*sredirect.Redirect
+	related           contentLinkGraph // as pages were last rendered with
+	sequences         []*SequenceEntry
+	seriesDefinitions []*SeriesDefinition
+	tagDefinitions    []*TagDefinition
+	tweets            []*squantified.Tweet
 )
 
 // Time zone to show articles / fragments / etc. publishing times in.
@@ -112,6 +139,7 @@ var validate = validator.New()
 func init() {
 	mmarkdownext.FuncMap = scommon.TextTemplateFuncMap
 	stemplate.LocalLocation = localLocation
+	validate.RegisterTagNameFunc(tomlFieldName)
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -134,10 +162,46 @@ func build(c *modulir.Context) []error {
 
 	c.Log.Debugf("Running build loop")
 
-	// This is where we stored "versioned" assets like compiled JS and CSS.
-	// These assets have a release number that we can increment and by
-	// extension quickly invalidate.
-	versionedAssetsDir := path.Join(c.TargetDir, "assets", Release)
+	// Restore dependencies and content hashes from the last build so that a
+	// cold start only rebuilds what changed since then. A cache that can't be
+	// loaded isn't fatal, and only means that everything gets rebuilt.
+	if c.FirstRun {
+		err := dependencies.load(c, path.Join(c.TargetDir, dependencyCacheFilename), dependencyCacheVersion())
+		if err != nil {
+			c.Log.Errorf("Error loading dependency cache (rebuilding everything): %v", err)
+		}
+
+		// Likewise for the backlinks that pages were last rendered with. A
+		// graph that can't be loaded only means that every page with
+		// backlinks gets rendered again.
+		backlinks, err = loadContentLinkGraph(path.Join(c.TargetDir, backlinksFilename))
+		if err != nil {
+			c.Log.Errorf("Error loading backlinks (rendering pages with backlinks): %v", err)
+			backlinks = make(contentLinkGraph)
+		}
+
+		related, err = loadContentLinkGraph(path.Join(c.TargetDir, relatedFilename))
+		if err != nil {
+			c.Log.Errorf("Error loading related content (rendering pages with related content): %v", err)
+			related = make(contentLinkGraph)
+		}
+	}
+
+	// Minification stats are reported for each build loop.
+	if conf.Minify && conf.SorgEnv != sorgEnvDevelopment {
+		if minifier == nil {
+			minifier = sminify.New()
+		}
+		minifier.Reset()
+	}
+
+	if conf.Compress && compressManifest == nil {
+		var err error
+		compressManifest, err = scompress.LoadManifest(path.Join(c.TargetDir, compressManifestFilename))
+		if err != nil {
+			return []error{err}
+		}
+	}
 
 	// A set of source paths that rebuild everything when any one of them
 	// changes. These are dependencies that are included in more or less
@@ -145,6 +209,14 @@ func build(c *modulir.Context) []error {
 	// sources.
 	universalSources = nil
 
+	// Maps assets to their fingerprinted names for the `Asset` template
+	// function, along with the sources that they're fingerprinted from. A
+	// change to any of those sources changes every view.
+	var (
+		assetSources []string
+		assets       = make(map[string]string)
+	)
+
 	// Generate a set of JavaScript sources to add to universal sources.
 	{
 		javaScriptSources, err := mfile.ReadDirCached(c, c.SourceDir+"/content/javascripts",
@@ -152,7 +224,12 @@ func build(c *modulir.Context) []error {
 		if err != nil {
 			return []error{err}
 		}
+		assetSources = append(assetSources, javaScriptSources...)
 		universalSources = append(universalSources, javaScriptSources...)
+
+		if err := fingerprintAssets(c, "javascripts", javaScriptSources, assets); err != nil {
+			return []error{err}
+		}
 	}
 
 	// Generate a list of partial views to add to universal sources.
@@ -180,7 +257,59 @@ func build(c *modulir.Context) []error {
 		if err != nil {
 			return []error{err}
 		}
+		assetSources = append(assetSources, stylesheetSources...)
 		universalSources = append(universalSources, stylesheetSources...)
+
+		if err := fingerprintAssets(c, "stylesheets", stylesheetSources, assets); err != nil {
+			return []error{err}
+		}
+	}
+
+	dependencies.setAssetSources(assetSources)
+	stemplate.Assets = assets
+
+	// Read newsletter definitions. This happens up front rather than in a job
+	// because the jobs to enqueue for newsletters depend on them.
+	newslettersChanged := dependencies.changed(c, c.SourceDir+"/"+newslettersFilename)
+	if newslettersChanged || c.FirstRun {
+		var err error
+		newsletters, err = loadNewsletters(c)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Read the tag allowlist, which is needed to check the tags of content
+	// as it's loaded.
+	tagsChanged := dependencies.changed(c, c.SourceDir+"/"+tagsFilename)
+	if tagsChanged || c.FirstRun {
+		var err error
+		tagDefinitions, err = loadTagDefinitions(c)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Read the series allowlist, which is needed to group content into
+	// series once it's loaded.
+	seriesChanged := dependencies.changed(c, c.SourceDir+"/"+seriesFilename)
+	if seriesChanged || c.FirstRun {
+		var err error
+		seriesDefinitions, err = loadSeriesDefinitions(c)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Read redirects, which are combined with content aliases once all
+	// content is loaded.
+	redirectsChanged := dependencies.changed(c, c.SourceDir+"/"+redirectsFilename)
+	if redirectsChanged || c.FirstRun {
+		var err error
+		redirects, err = loadRedirects(c)
+		if err != nil {
+			return []error{err}
+		}
 	}
 
 	//
@@ -216,15 +345,17 @@ func build(c *modulir.Context) []error {
 			c.TargetDir + "/articles",
 			c.TargetDir + "/atoms",
 			c.TargetDir + "/fragments",
-			c.TargetDir + "/nanoglyphs",
-			c.TargetDir + "/passages",
 			c.TargetDir + "/photos",
 			c.TargetDir + "/reading",
 			c.TargetDir + "/runs",
 			c.TargetDir + "/sequences",
+			c.TargetDir + "/series",
+			c.TargetDir + "/tags",
 			c.TargetDir + "/twitter",
 			scommon.TempDir,
-			versionedAssetsDir,
+		}
+		for _, newsletter := range newsletters {
+			commonDirs = append(commonDirs, c.TargetDir+"/"+newsletter.Slug)
 		}
 		for _, dir := range commonDirs {
 			err := mfile.EnsureDir(c, dir)
@@ -242,14 +373,12 @@ func build(c *modulir.Context) []error {
 		commonSymlinks := [][2]string{
 			{c.SourceDir + "/content/fonts", c.TargetDir + "/assets/fonts"},
 			{c.SourceDir + "/content/images", c.TargetDir + "/assets/images"},
-			{c.SourceDir + "/content/javascripts", versionedAssetsDir + "/javascripts"},
 
 			// For backwards compatibility as many emails with this style of path
 			// have already gone out.
 			{c.SourceDir + "/content/images/passages", c.TargetDir + "/assets/passages"},
 
 			{c.SourceDir + "/content/photographs", c.TargetDir + "/photographs"},
-			{c.SourceDir + "/content/stylesheets", versionedAssetsDir + "/stylesheets"},
 			{c.SourceDir + "/content/videos", c.TargetDir + "/videos"},
 		}
 		for _, link := range commonSymlinks {
@@ -300,17 +429,16 @@ func build(c *modulir.Context) []error {
 
 	{
 		c.AddJob("atoms", func() (bool, error) {
-			// Constrain descriptions to 2217 bytes as specified by Spring '83
-			// even though they're also posted off Spring '83.
-			const maxBytesLength = 2217
-
 			source := c.SourceDir + "/content/atoms/_meta.toml"
 
-			if !c.Changed(source) {
+			// Always load on the first run because there's nothing in memory
+			// yet, even if the source hasn't changed since the last build.
+			sourceChanged := dependencies.changed(c, source)
+			if !sourceChanged && !c.FirstRun {
 				return false, nil
 			}
 
-			atomsChanged = true
+			atomsChanged = sourceChanged
 
 			var atomsWrapper AtomWrapper
 			err := mtoml.ParseFile(c, source, &atomsWrapper)
@@ -349,9 +477,8 @@ func build(c *modulir.Context) []error {
 
 				atom.changed = true
 
-				if len([]byte(atom.DescriptionHTML)) > maxBytesLength && !atom.LengthExempted {
-					return true, xerrors.Errorf("atom's length is greater than %d bytes (was %d): %q",
-						maxBytesLength, len([]byte(atom.DescriptionHTML)), atom.Description[0:100])
+				if err := atom.validateLength(); err != nil {
+					return true, err
 				}
 			}
 
@@ -394,33 +521,37 @@ func build(c *modulir.Context) []error {
 	}
 
 	//
-	// Nanoglyphs
+	// Newsletters
 	//
 
-	var nanoglyphsChanged bool
-	var nanoglyphsMu sync.Mutex
+	newsletterBuilds := make([]*newsletterBuild, len(newsletters))
 
-	{
-		sources, err := mfile.ReadDirCached(c, c.SourceDir+"/content/nanoglyphs", nil)
+	for i, newsletter := range newsletters {
+		nb := &newsletterBuild{
+			changedIssues:     make(map[string]struct{}),
+			definitionChanged: newslettersChanged,
+			issues:            newsletterIssues[newsletter.Slug],
+			newsletter:        newsletter,
+		}
+		newsletterBuilds[i] = nb
+
+		sources, err := mfile.ReadDirCached(c, c.SourceDir+"/"+newsletter.ContentDir, nil)
 		if err != nil {
 			return []error{err}
 		}
 
 		if conf.Drafts {
-			drafts, err := mfile.ReadDirCached(c, c.SourceDir+"/content/nanoglyphs-drafts", nil)
+			drafts, err := mfile.ReadDirCached(c, c.SourceDir+"/"+newsletter.ContentDirDrafts, nil)
 			if err != nil {
 				return []error{err}
 			}
 			sources = append(sources, drafts...)
 		}
 
-		for _, s := range sources {
-			source := s
-
-			name := "nanoglyph: " + filepath.Base(source)
+		for _, source := range sources {
+			name := newsletter.Kind + ": " + filepath.Base(source)
 			c.AddJob(name, func() (bool, error) {
-				return renderNanoglyph(ctx, c, source,
-					&nanoglyphs, &nanoglyphsChanged, &nanoglyphsMu)
+				return renderNewsletterIssue(c, nb, source)
 			})
 		}
 	}
@@ -431,11 +562,15 @@ func build(c *modulir.Context) []error {
 
 	var pagesMu sync.RWMutex
 
+	// Sources of published pages (i.e. not drafts) for the sitemap.
+	var pageSources []string
+
 	{
 		sources, err := mfile.ReadDirCached(c, c.SourceDir+"/pages", &mfile.ReadDirOptions{RecurseDirs: true})
 		if err != nil {
 			return []error{err}
 		}
+		pageSources = sources
 
 		if conf.Drafts {
 			drafts, err := mfile.ReadDirCached(c, c.SourceDir+"/pages-drafts", &mfile.ReadDirOptions{RecurseDirs: true})
@@ -455,38 +590,6 @@ func build(c *modulir.Context) []error {
 		}
 	}
 
-	//
-	// Passages
-	//
-
-	var passagesChanged bool
-	var passagesMu sync.Mutex
-
-	{
-		sources, err := mfile.ReadDirCached(c, c.SourceDir+"/content/passages", nil)
-		if err != nil {
-			return []error{err}
-		}
-
-		if conf.Drafts {
-			drafts, err := mfile.ReadDirCached(c, c.SourceDir+"/content/passages-drafts", nil)
-			if err != nil {
-				return []error{err}
-			}
-			sources = append(sources, drafts...)
-		}
-
-		for _, s := range sources {
-			source := s
-
-			name := "passage: " + filepath.Base(source)
-			c.AddJob(name, func() (bool, error) {
-				return renderPassage(ctx, c, source,
-					&passages, &passagesChanged, &passagesMu)
-			})
-		}
-	}
-
 	//
 	// Photos (read `_meta.toml`)
 	//
@@ -497,7 +600,8 @@ func build(c *modulir.Context) []error {
 		c.AddJob("photos _meta.toml", func() (bool, error) {
 			source := c.SourceDir + "/content/photographs/_meta.toml"
 
-			if !c.Changed(source) {
+			sourceChanged := dependencies.changed(c, source)
+			if !sourceChanged && !c.FirstRun {
 				return false, nil
 			}
 
@@ -508,7 +612,7 @@ func build(c *modulir.Context) []error {
 			}
 
 			photos = photosWrapper.Photos
-			photosChanged = true
+			photosChanged = sourceChanged
 			return true, nil
 		})
 	}
@@ -521,7 +625,7 @@ func build(c *modulir.Context) []error {
 		c.AddJob("photos (other) _meta.toml", func() (bool, error) {
 			source := c.SourceDir + "/content/photographs/_other_meta.toml"
 
-			if !c.Changed(source) {
+			if !dependencies.changed(c, source) && !c.FirstRun {
 				return false, nil
 			}
 
@@ -580,11 +684,12 @@ func build(c *modulir.Context) []error {
 		c.AddJob("sequences", func() (bool, error) {
 			source := c.SourceDir + "/content/sequences/_meta.toml"
 
-			if !c.Changed(source) {
+			sourceChanged := dependencies.changed(c, source)
+			if !sourceChanged && !c.FirstRun {
 				return false, nil
 			}
 
-			sequenceChanged = true
+			sequenceChanged = sourceChanged
 
 			var sequenceWrapper SequenceWrapper
 			err := mtoml.ParseFile(c, source, &sequenceWrapper)
@@ -639,7 +744,8 @@ func build(c *modulir.Context) []error {
 		c.AddJob("twitter data/twitter.toml", func() (bool, error) {
 			source := scommon.DataDir + "/twitter.toml"
 
-			if !c.Changed(source) {
+			sourceChanged := dependencies.changed(c, source)
+			if !sourceChanged && !c.FirstRun {
 				return false, nil
 			}
 
@@ -649,7 +755,7 @@ func build(c *modulir.Context) []error {
 				return true, err
 			}
 
-			tweetsChanged = true
+			tweetsChanged = sourceChanged
 			return true, nil
 		})
 	}
@@ -674,15 +780,165 @@ func build(c *modulir.Context) []error {
 	{
 		slices.SortFunc(articles, func(a, b *Article) int { return b.PublishedAt.Compare(a.PublishedAt) })
 		slices.SortFunc(fragments, func(a, b *Fragment) int { return b.PublishedAt.Compare(a.PublishedAt) })
-		slices.SortFunc(nanoglyphs, func(a, b *snewsletter.Issue) int { return b.PublishedAt.Compare(a.PublishedAt) })
-		slices.SortFunc(passages, func(a, b *snewsletter.Issue) int { return b.PublishedAt.Compare(a.PublishedAt) })
 		slices.SortFunc(photos, func(a, b *Photo) int { return b.OccurredAt.Compare(a.OccurredAt) })
+
+		for _, nb := range newsletterBuilds {
+			slices.SortFunc(nb.issues, func(a, b *snewsletter.Issue) int { return b.PublishedAt.Compare(a.PublishedAt) })
+			newsletterIssues[nb.newsletter.Slug] = nb.issues
+		}
+	}
+
+	// Content whose scheduled time has come since the last build is rendered
+	// as if it changed, even though its source didn't, and the loop is
+	// scheduled to rebuild again when the next content is due.
+	{
+		publishedThrough := dependencies.publishedThrough
+
+		for _, article := range articles {
+			if newlyPublished(c, article.PublishedAt, publishedThrough) {
+				article.changed = true
+				articlesChanged = true
+			}
+		}
+
+		for _, atom := range atoms {
+			if newlyPublished(c, atom.PublishedAt, publishedThrough) {
+				atom.changed = true
+				atomsChanged = true
+			}
+		}
+
+		for _, fragment := range fragments {
+			if newlyPublished(c, fragment.PublishedAt, publishedThrough) {
+				fragment.changed = true
+				fragmentsChanged = true
+			}
+		}
+
+		for _, nb := range newsletterBuilds {
+			for _, issue := range nb.issues {
+				if newlyPublished(c, issue.PublishedAt, publishedThrough) {
+					nb.changed = true
+					nb.changedIssues[issue.Slug] = struct{}{}
+				}
+			}
+		}
+
+		for _, entry := range sequences {
+			if newlyPublished(c, entry.PublishedAt, publishedThrough) {
+				entry.changed = true
+				sequenceChanged = true
+			}
+		}
+
+		scheduleRebuild(c, nextScheduledAt(c, articles, atoms, fragments, newsletterBuilds, sequences))
+	}
+
+	// Scheduled content is left out of everything from here on unless drafts
+	// are enabled. The globals keep it so that it's still loaded once its time
+	// comes.
+	articles, atoms, fragments, sequences := articles, atoms, fragments, sequences
+	var scheduledPaths map[string]struct{}
+	if !conf.Drafts {
+		scheduledPaths = scheduledContentPaths(c, articles, fragments, newsletterBuilds)
+
+		articles = withoutScheduled(c, articles, func(a *Article) time.Time { return a.PublishedAt })
+		atoms = withoutScheduled(c, atoms, func(a *Atom) time.Time { return a.PublishedAt })
+		fragments = withoutScheduled(c, fragments, func(f *Fragment) time.Time { return f.PublishedAt })
+		sequences = withoutScheduled(c, sequences, func(e *SequenceEntry) time.Time { return e.PublishedAt })
+
+		for _, nb := range newsletterBuilds {
+			nb.issues = withoutScheduled(c, nb.issues, func(i *snewsletter.Issue) time.Time { return i.PublishedAt })
+		}
 	}
 
+	// Cards that were held back while their content was scheduled are drawn
+	// now that it's been published.
+	for name, scheduled := range publishedScheduledCards(c) {
+		c.AddJob("card: "+name, func() (bool, error) {
+			_, err := renderCard(c, name, scheduled.card, scheduled.imageSource)
+			return true, err
+		})
+	}
+
+	// Discover tags in use now that all content is loaded, which also checks
+	// every tag against the allowlist.
+	var tagBuilds []*tagBuild
+	{
+		var err error
+		tagBuilds, err = groupTaggedContent(tagDefinitions, articles, atoms, fragments, newsletterBuilds)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Likewise for series, which also checks every series against its
+	// allowlist.
+	var seriesBuilds []*seriesBuild
+	{
+		var err error
+		seriesBuilds, err = groupSeriesContent(seriesDefinitions, articles, fragments)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Extract links between content and find related content now that all of
+	// it is loaded. The page of any content whose backlinks or related content
+	// changed is rendered again below.
+	var (
+		newBacklinks contentLinkGraph
+		newRelated   contentLinkGraph
+	)
+	{
+		allRedirects, err := collectRedirects(redirects, articles, fragments, newsletterBuilds)
+		if err != nil {
+			return []error{err}
+		}
+
+		newBacklinks, err = collectBacklinks(articles, atoms, fragments, newsletterBuilds, allRedirects)
+		if err != nil {
+			return []error{err}
+		}
+
+		newRelated, err = collectRelated(articles, fragments, newsletterBuilds, scheduledPaths)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Pages in a series link to the rest of the series, so any change to
+	// articles or fragments renders every series again. Series are small
+	// enough that this is cheap.
+	seriesContentChanged := articlesChanged || fragmentsChanged || seriesChanged
+	navs := seriesNavs(seriesBuilds)
+
 	//
 	// Articles
 	//
 
+	// Each article
+	{
+		for _, article := range articles {
+			contentPath := "/" + article.Slug
+			nav := navs[contentPath]
+
+			if !article.changed && (nav == nil || !seriesContentChanged) &&
+				!newBacklinks.changed(backlinks, contentPath) && !newRelated.changed(related, contentPath) {
+				continue
+			}
+			c.AddJob("article page: "+article.Slug, func() (bool, error) {
+				err := renderArticleShow(ctx, c, article, nav, newBacklinks[contentPath], newRelated[contentPath])
+				if err != nil {
+					return true, err
+				}
+
+				article.changed = false
+				return true, nil
+			})
+		}
+	}
+
 	// Index
 	{
 		c.AddJob("articles index", func() (bool, error) {
@@ -691,7 +947,7 @@ func build(c *modulir.Context) []error {
 		})
 	}
 
-	// Feed (all)
+	// Feed
 	{
 		c.AddJob("articles feed", func() (bool, error) {
 			return renderArticlesFeed(c, articles, nil,
@@ -699,14 +955,6 @@ func build(c *modulir.Context) []error {
 		})
 	}
 
-	// Feed (Postgres)
-	{
-		c.AddJob("articles feed (postgres)", func() (bool, error) {
-			return renderArticlesFeed(c, articles, tagPointer(tagPostgres),
-				articlesChanged)
-		})
-	}
-
 	//
 	// Atoms (index / fetch + resize)
 	//
@@ -728,7 +976,14 @@ func build(c *modulir.Context) []error {
 	// Atoms feed
 	{
 		c.AddJob("atoms: feed", func() (bool, error) {
-			return renderAtomFeed(ctx, c, atoms, atomsChanged)
+			return renderAtomFeed(ctx, c, atoms, nil, atomsChanged)
+		})
+	}
+
+	// Atoms Spring '83 board
+	{
+		c.AddJob("atoms: spring83 board", func() (bool, error) {
+			return renderSpring83Board(c, atoms, atomsChanged)
 		})
 	}
 
@@ -737,16 +992,23 @@ func build(c *modulir.Context) []error {
 		for i, a := range atoms {
 			atom := a
 
-			if !atom.changed {
+			contentPath := "/atoms/" + atom.Slug
+			backlinksChanged := newBacklinks.changed(backlinks, contentPath)
+
+			if !atom.changed && !backlinksChanged {
 				continue
 			}
 
 			// Atom page
 			name := "atom: " + atom.Slug
 			c.AddJob(name, func() (bool, error) {
-				return renderAtom(ctx, c, atom, i, atomsChanged)
+				return renderAtom(ctx, c, atom, i, newBacklinks[contentPath], atomsChanged || backlinksChanged)
 			})
 
+			if !atom.changed {
+				continue
+			}
+
 			// Photo fetch + resize
 			for i := range atom.Photos {
 				photo := atom.Photos[i]
@@ -776,6 +1038,28 @@ func build(c *modulir.Context) []error {
 	// Fragments
 	//
 
+	// Each fragment
+	{
+		for _, fragment := range fragments {
+			contentPath := "/fragments/" + fragment.Slug
+			nav := navs[contentPath]
+
+			if !fragment.changed && (nav == nil || !seriesContentChanged) &&
+				!newBacklinks.changed(backlinks, contentPath) && !newRelated.changed(related, contentPath) {
+				continue
+			}
+			c.AddJob("fragment page: "+fragment.Slug, func() (bool, error) {
+				err := renderFragmentShow(ctx, c, fragment, nav, newBacklinks[contentPath], newRelated[contentPath])
+				if err != nil {
+					return true, err
+				}
+
+				fragment.changed = false
+				return true, nil
+			})
+		}
+	}
+
 	// Index
 	{
 		c.AddJob("fragments index", func() (bool, error) {
@@ -787,11 +1071,19 @@ func build(c *modulir.Context) []error {
 	// Feed
 	{
 		c.AddJob("fragments feed", func() (bool, error) {
-			return renderFragmentsFeed(c, fragments,
+			return renderFragmentsFeed(c, fragments, nil,
 				fragmentsChanged)
 		})
 	}
 
+	// Feed of revisions to articles and fragments
+	{
+		c.AddJob("updated feed", func() (bool, error) {
+			return renderUpdatedFeed(c, articles, fragments,
+				articlesChanged || fragmentsChanged)
+		})
+	}
+
 	// Possible photo in each fragment
 	{
 		for _, fragment := range fragments {
@@ -814,48 +1106,38 @@ func build(c *modulir.Context) []error {
 
 	{
 		c.AddJob("home", func() (bool, error) {
-			return renderHome(ctx, c, articles, fragments, nanoglyphs, sequences,
-				articlesChanged, fragmentsChanged, nanoglyphsChanged, photosChanged, sequenceChanged)
+			return renderHome(ctx, c, articles, fragments, newsletterBuilds, sequences,
+				articlesChanged, fragmentsChanged, photosChanged, sequenceChanged)
 		})
 	}
 
 	//
-	// Nanoglyphs
+	// Newsletters
 	//
 
-	// Index
-	{
-		c.AddJob("nanoglyphs index", func() (bool, error) {
-			return renderNanoglyphsIndex(ctx, c, nanoglyphs,
-				nanoglyphsChanged)
-		})
-	}
+	for _, nb := range newsletterBuilds {
+		// Each issue
+		for _, issue := range nb.issues {
+			contentPath := "/" + nb.newsletter.Slug + "/" + issue.Slug
 
-	// Feed
-	{
-		c.AddJob("nanoglyphs feed", func() (bool, error) {
-			return renderNanoglyphsFeed(c, nanoglyphs,
-				nanoglyphsChanged)
-		})
-	}
+			_, changed := nb.changedIssues[issue.Slug]
+			if !changed && !newBacklinks.changed(backlinks, contentPath) {
+				continue
+			}
 
-	//
-	// Passages
-	//
+			c.AddJob(nb.newsletter.Slug+" page: "+issue.Slug, func() (bool, error) {
+				return true, renderNewsletterIssueShow(ctx, c, nb, issue, newBacklinks[contentPath])
+			})
+		}
 
-	// Index
-	{
-		c.AddJob("passages index", func() (bool, error) {
-			return renderPassagesIndex(ctx, c, passages,
-				passagesChanged)
+		// Index
+		c.AddJob(nb.newsletter.Slug+" index", func() (bool, error) {
+			return renderNewsletterIndex(ctx, c, nb)
 		})
-	}
 
-	// Feed
-	{
-		c.AddJob("passages feed", func() (bool, error) {
-			return renderPassagesFeed(c, passages,
-				passagesChanged)
+		// Feed
+		c.AddJob(nb.newsletter.Slug+" feed", func() (bool, error) {
+			return renderNewsletterFeed(c, nb, nil)
 		})
 	}
 
@@ -952,6 +1234,132 @@ func build(c *modulir.Context) []error {
 		}
 	}
 
+	//
+	// Series
+	//
+
+	{
+		for _, sb := range seriesBuilds {
+			c.AddJob("series: "+sb.definition.Name, func() (bool, error) {
+				return renderSeries(ctx, c, sb, seriesContentChanged)
+			})
+		}
+	}
+
+	//
+	// Tags
+	//
+
+	{
+		// A tag's index page includes content of every kind, so it changes
+		// along with any of it.
+		contentChanged := articlesChanged || atomsChanged || fragmentsChanged || tagsChanged
+		for _, nb := range newsletterBuilds {
+			contentChanged = contentChanged || nb.changed || nb.definitionChanged
+		}
+
+		for _, tb := range tagBuilds {
+			tag := &tb.definition.Tag
+
+			// Index
+			c.AddJob("tag: "+string(*tag), func() (bool, error) {
+				return renderTag(ctx, c, tb, contentChanged)
+			})
+
+			// Feeds
+			if tb.hasFeed(tagFeedName("articles", *tag)) {
+				c.AddJob("articles feed ("+string(*tag)+")", func() (bool, error) {
+					return renderArticlesFeed(c, articles, tag, articlesChanged)
+				})
+			}
+			if tb.hasFeed(tagFeedName("atoms", *tag)) {
+				c.AddJob("atoms: feed ("+string(*tag)+")", func() (bool, error) {
+					return renderAtomFeed(ctx, c, atoms, tag, atomsChanged)
+				})
+			}
+			if tb.hasFeed(tagFeedName("fragments", *tag)) {
+				c.AddJob("fragments feed ("+string(*tag)+")", func() (bool, error) {
+					return renderFragmentsFeed(c, fragments, tag, fragmentsChanged)
+				})
+			}
+			for _, nb := range newsletterBuilds {
+				if tb.hasFeed(tagFeedName(nb.newsletter.Slug, *tag)) {
+					c.AddJob(nb.newsletter.Slug+" feed ("+string(*tag)+")", func() (bool, error) {
+						return renderNewsletterFeed(c, nb, tag)
+					})
+				}
+			}
+		}
+	}
+
+	//
+	// Redirects
+	//
+
+	{
+		contentChanged := articlesChanged || fragmentsChanged || redirectsChanged
+		for _, nb := range newsletterBuilds {
+			contentChanged = contentChanged || nb.changed || nb.definitionChanged
+		}
+
+		c.AddJob("redirects", func() (bool, error) {
+			return renderRedirects(c, redirects, articles, fragments, newsletterBuilds, scheduledPaths,
+				contentChanged)
+		})
+	}
+
+	//
+	// Search
+	//
+
+	{
+		contentChanged := articlesChanged || atomsChanged || fragmentsChanged || sequenceChanged
+		for _, nb := range newsletterBuilds {
+			contentChanged = contentChanged || nb.changed || nb.definitionChanged
+		}
+
+		c.AddJob("search index", func() (bool, error) {
+			return renderSearchIndex(c, articles, atoms, fragments, newsletterBuilds, sequences,
+				contentChanged)
+		})
+
+		c.AddJob("search", func() (bool, error) {
+			return renderSearch(ctx, c)
+		})
+	}
+
+	//
+	// Stats
+	//
+
+	{
+		contentChanged := articlesChanged || fragmentsChanged
+		for _, nb := range newsletterBuilds {
+			contentChanged = contentChanged || nb.changed || nb.definitionChanged
+		}
+
+		c.AddJob("content stats", func() (bool, error) {
+			return renderContentStats(c, articles, fragments, newsletterBuilds, contentChanged)
+		})
+	}
+
+	//
+	// Sitemaps
+	//
+
+	{
+		contentChanged := articlesChanged || atomsChanged || fragmentsChanged || photosChanged ||
+			sequenceChanged || tagsChanged || dependencies.changedAny(c, pageSources...)
+		for _, nb := range newsletterBuilds {
+			contentChanged = contentChanged || nb.changed || nb.definitionChanged
+		}
+
+		c.AddJob("sitemaps", func() (bool, error) {
+			return renderSitemaps(c, articles, atoms, fragments, newsletterBuilds, pageSources, photos,
+				sequences, tagDefinitions, contentChanged)
+		})
+	}
+
 	//
 	// Twitter indexes
 	//
@@ -991,6 +1399,105 @@ func build(c *modulir.Context) []error {
 		}
 	}
 
+	//
+	//
+	//
+	// PHASE 3
+	//
+	//
+	//
+
+	if errors := c.Wait(); errors != nil {
+		return errors
+	}
+
+	//
+	// Atom cards
+	//
+	// Drawn in their own phase because an atom's card includes its first
+	// photo, which is fetched in the phase before.
+	//
+
+	{
+		for _, atom := range atoms {
+			if !atom.changed {
+				continue
+			}
+
+			c.AddJob(fmt.Sprintf("atom %q card", atom.Slug), func() (bool, error) {
+				return renderAtomCard(c, atom, atomsChanged)
+			})
+		}
+	}
+
+	//
+	//
+	//
+	// PHASE 4
+	//
+	//
+	//
+
+	if errors := c.Wait(); errors != nil {
+		return errors
+	}
+
+	//
+	// Compression
+	//
+	// Happens last because it covers the output of every other job.
+	//
+
+	if conf.Compress {
+		if err := compressTargetFiles(c, compressManifest); err != nil {
+			return []error{err}
+		}
+	}
+
+	//
+	//
+	//
+	// PHASE 5
+	//
+	//
+	//
+
+	if errors := c.Wait(); errors != nil {
+		return errors
+	}
+
+	if minifier != nil {
+		logMinifyStats(c, minifier)
+	}
+
+	if conf.Compress {
+		err := compressManifest.Save(path.Join(c.TargetDir, compressManifestFilename))
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	// Only persist dependencies after a successful build. Otherwise, hashes
+	// could be saved for sources whose pages failed to render, and they'd be
+	// skipped by the next cold start.
+	dependencies.publishedThrough = c.Stats.Start
+	err := dependencies.save(path.Join(c.TargetDir, dependencyCacheFilename), dependencyCacheVersion())
+	if err != nil {
+		return []error{err}
+	}
+
+	// Backlinks are persisted for the same reason, and compared against on
+	// the next build loop to find pages whose backlinks changed.
+	backlinks = newBacklinks
+	if err := backlinks.save(path.Join(c.TargetDir, backlinksFilename)); err != nil {
+		return []error{err}
+	}
+
+	related = newRelated
+	if err := related.save(path.Join(c.TargetDir, relatedFilename)); err != nil {
+		return []error{err}
+	}
+
 	return nil
 }
 
@@ -1006,10 +1513,18 @@ func build(c *modulir.Context) []error {
 
 // Article represents an article to be rendered.
 type Article struct {
+	// Aliases are old paths that the article used to live at, like
+	// `/old-slug`, which redirect to it.
+	Aliases []string `toml:"aliases,omitempty" validate:"dive,startswith=/"`
+
 	// Attributions are any attributions for content that may be included in
 	// the article (like an image in the header for example).
 	Attributions template.HTML `toml:"attributions,omitempty"`
 
+	// Changelog is an optional history of revisions to the article, sorted
+	// newest first once the article's rendered.
+	Changelog []*ChangelogEntry `toml:"changelog,omitempty" validate:"dive"`
+
 	// Content is the HTML content of the article. It isn't included as TOML
 	// frontmatter, and is rather split out of an article's Markdown file,
 	// rendered, and then added separately.
@@ -1040,10 +1555,30 @@ type Article struct {
 	// PublishedAt is when the article was published.
 	PublishedAt time.Time `toml:"published_at" validate:"required"`
 
+	// Related are paths of content to pin to the top of the article's related
+	// content, like `/fragments/queues`, in order.
+	Related []string `toml:"related,omitempty" validate:"dive,startswith=/"`
+
+	// RelatedExclude are paths of content to never show in the article's
+	// related content.
+	RelatedExclude []string `toml:"related_exclude,omitempty" validate:"dive,startswith=/"`
+
+	// Series is the name of an optional series that the article is part of.
+	// It must be in the allowlist in `content/series.toml`.
+	Series string `toml:"series,omitempty"`
+
+	// SeriesIndex is the article's position in its series. It's optional,
+	// and series are ordered by publishing date without it.
+	SeriesIndex int `toml:"series_index,omitempty" validate:"omitempty,gt=0"`
+
 	// Slug is a unique identifier for the article that also helps determine
 	// where it's addressable by URL.
 	Slug string `toml:"-"`
 
+	// Stats are statistics about the article's rendered content and
+	// footnotes like its word count.
+	Stats *sstats.Stats `toml:"-"`
+
 	// Tags are the set of tags that the article is tagged with.
 	Tags []Tag `toml:"tags,omitempty"`
 
@@ -1054,6 +1589,18 @@ type Article struct {
 	// included as TOML frontmatter, but rather calculated from the article's
 	// content, rendered, and then added separately.
 	TOC template.HTML `toml:"-"`
+
+	// UpdatedAt is when the article was last updated, if it has been since
+	// it was published. Revisions in the changelog count as updates too.
+	UpdatedAt time.Time `toml:"updated_at,omitempty"`
+
+	// card is the article's Twitter card, kept so that the article can be
+	// rendered once all content is loaded.
+	card *twitterCard
+
+	// changed is whether the article or its view changed since the article
+	// was last rendered.
+	changed bool
 }
 
 // publishingInfo produces a brief spiel about publication which is intended to
@@ -1065,9 +1612,23 @@ func (a *Article) publishingInfo() map[string]string {
 	info["Published"] = a.PublishedAt.In(localLocation).Format("January 2, 2006")
 	info["Location"] = a.Location
 
+	if updatedAt := a.lastUpdatedAt(); !updatedAt.IsZero() {
+		info["Updated"] = updatedAt.In(localLocation).Format("January 2, 2006")
+	}
+
+	if a.Stats != nil {
+		info["Reading time"] = a.Stats.Summary()
+	}
+
 	return info
 }
 
+// lastUpdatedAt gets when the article was last updated, or a zero time if it
+// hasn't been since it was published.
+func (a *Article) lastUpdatedAt() time.Time {
+	return lastUpdatedAt(a.UpdatedAt, a.Changelog)
+}
+
 // taggedWith returns true if the given tag is in this article's set of tags
 // and false otherwise.
 func (a *Article) taggedWith(tag Tag) bool {
@@ -1076,7 +1637,10 @@ func (a *Article) taggedWith(tag Tag) bool {
 
 func (a *Article) validate(source string) error {
 	if err := validate.Struct(a); err != nil {
-		return xerrors.Errorf("error validating article %q: %+v", source, err)
+		return xerrors.Errorf("error validating article %q: %w", source, err)
+	}
+	if err := checkUpdates(a.PublishedAt, a.UpdatedAt, a.Changelog); err != nil {
+		return xerrors.Errorf("error validating article %q: %w", source, err)
 	}
 	return nil
 }
@@ -1087,7 +1651,7 @@ type AtomWrapper struct {
 
 func (w *AtomWrapper) validate() error {
 	if err := validate.Struct(w); err != nil {
-		return xerrors.Errorf("error validating sequences: %+v", err)
+		return xerrors.Errorf("error validating atoms: %w", err)
 	}
 	return nil
 }
@@ -1115,6 +1679,9 @@ type Atom struct {
 	// timestamp.
 	Slug string `toml:"-" validate:"-"`
 
+	// Tags are the set of tags that the atom is tagged with.
+	Tags []Tag `toml:"tags,omitempty" validate:"-"`
+
 	// Title is a title for the atom, but is optional. Atoms don't need and
 	// mostly don't have titles.
 	Title *string `toml:"title" validate:"-"`
@@ -1247,14 +1814,46 @@ func buildContributionGrid(atoms []*Atom) *ContributionGrid {
 	return grid
 }
 
+// Constrain atom descriptions to 2217 bytes as specified by Spring '83 even
+// though they're also posted off Spring '83.
+const atomMaxBytesLength = 2217
+
 func (a *Atom) Equal(other *Atom) bool {
 	return a.Description == other.Description &&
 		slices.EqualFunc(a.Photos, other.Photos, func(a, b *Photo) bool { return a.Equal(b) }) &&
 		a.PublishedAt.Equal(other.PublishedAt) &&
+		slices.Equal(a.Tags, other.Tags) &&
 		a.Title == other.Title &&
 		slices.EqualFunc(a.Videos, other.Videos, func(a, b *AtomVideo) bool { return a.Equal(b) })
 }
 
+// displayTitle is the atom's title, or one derived from its slug for the
+// majority of atoms that don't have one. Twitter doesn't play nicely showing
+// "<" or ">", so those are avoided.
+func (a *Atom) displayTitle() string {
+	if a.Title == nil {
+		return "Atom #" + a.Slug
+	}
+	return *a.Title
+}
+
+// taggedWith returns true if the given tag is in this atom's set of tags and
+// false otherwise.
+func (a *Atom) taggedWith(tag Tag) bool {
+	return slices.Contains(a.Tags, tag)
+}
+
+// validateLength checks that the atom's rendered description fits within
+// Spring '83's length limit unless it's been exempted. DescriptionHTML must
+// already be rendered.
+func (a *Atom) validateLength() error {
+	if len([]byte(a.DescriptionHTML)) > atomMaxBytesLength && !a.LengthExempted {
+		return xerrors.Errorf("atom's length is greater than %d bytes (was %d): %q",
+			atomMaxBytesLength, len([]byte(a.DescriptionHTML)), a.Description[0:100])
+	}
+	return nil
+}
+
 type AtomVideo struct {
 	URL []string `toml:"url" validate:"required"`
 }
@@ -1276,10 +1875,18 @@ func (v *AtomVideo) Equal(other *AtomVideo) bool {
 // Fragment represents a fragment (that is, a short "stream of consciousness"
 // style article) to be rendered.
 type Fragment struct {
+	// Aliases are old paths that the fragment used to live at, like
+	// `/fragments/old-slug`, which redirect to it.
+	Aliases []string `toml:"aliases,omitempty" validate:"dive,startswith=/"`
+
 	// Attributions are any attributions for content that may be included in
 	// the article (like an image in the header for example).
 	Attributions template.HTML `toml:"attributions,omitempty"`
 
+	// Changelog is an optional history of revisions to the fragment, sorted
+	// newest first once the fragment's rendered.
+	Changelog []*ChangelogEntry `toml:"changelog,omitempty" validate:"dive"`
+
 	// Content is the HTML content of the fragment. It isn't included as TOML
 	// frontmatter, and is rather split out of an fragment's Markdown file,
 	// rendered, and then added separately.
@@ -1310,12 +1917,47 @@ type Fragment struct {
 	// PublishedAt is when the fragment was published.
 	PublishedAt time.Time `toml:"published_at" validate:"required"`
 
+	// Related are paths of content to pin to the top of the fragment's related
+	// content, like `/fragments/queues`, in order.
+	Related []string `toml:"related,omitempty" validate:"dive,startswith=/"`
+
+	// RelatedExclude are paths of content to never show in the fragment's
+	// related content.
+	RelatedExclude []string `toml:"related_exclude,omitempty" validate:"dive,startswith=/"`
+
+	// Series is the name of an optional series that the fragment is part
+	// of. It must be in the allowlist in `content/series.toml`.
+	Series string `toml:"series,omitempty"`
+
+	// SeriesIndex is the fragment's position in its series. It's optional,
+	// and series are ordered by publishing date without it.
+	SeriesIndex int `toml:"series_index,omitempty" validate:"omitempty,gt=0"`
+
 	// Slug is a unique identifier for the fragment that also helps determine
 	// where it's addressable by URL.
 	Slug string `toml:"-"`
 
+	// Stats are statistics about the fragment's rendered content and
+	// footnotes like its word count.
+	Stats *sstats.Stats `toml:"-"`
+
+	// Tags are the set of tags that the fragment is tagged with.
+	Tags []Tag `toml:"tags,omitempty"`
+
 	// Title is the fragment's title.
 	Title string `toml:"title" validate:"required"`
+
+	// UpdatedAt is when the fragment was last updated, if it has been since
+	// it was published. Revisions in the changelog count as updates too.
+	UpdatedAt time.Time `toml:"updated_at,omitempty"`
+
+	// card is the fragment's Twitter card, kept so that the fragment can be
+	// rendered once all content is loaded.
+	card *twitterCard
+
+	// changed is whether the fragment or its view changed since the
+	// fragment was last rendered.
+	changed bool
 }
 
 // PublishingInfo produces a brief spiel about publication which is intended to
@@ -1330,13 +1972,110 @@ func (f *Fragment) publishingInfo() map[string]string {
 		info["Location"] = f.Location
 	}
 
+	if updatedAt := f.lastUpdatedAt(); !updatedAt.IsZero() {
+		info["Updated"] = updatedAt.In(localLocation).Format("January 2, 2006")
+	}
+
+	if f.Stats != nil {
+		info["Reading time"] = f.Stats.Summary()
+	}
+
 	return info
 }
 
+// lastUpdatedAt gets when the fragment was last updated, or a zero time if it
+// hasn't been since it was published.
+func (f *Fragment) lastUpdatedAt() time.Time {
+	return lastUpdatedAt(f.UpdatedAt, f.Changelog)
+}
+
+// taggedWith returns true if the given tag is in this fragment's set of tags
+// and false otherwise.
+func (f *Fragment) taggedWith(tag Tag) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
 func (f *Fragment) validate(source string) error {
 	if err := validate.Struct(f); err != nil {
-		return xerrors.Errorf("error validating fragment %q: %+v", source, err)
+		return xerrors.Errorf("error validating fragment %q: %w", source, err)
+	}
+	if err := checkUpdates(f.PublishedAt, f.UpdatedAt, f.Changelog); err != nil {
+		return xerrors.Errorf("error validating fragment %q: %w", source, err)
+	}
+	return nil
+}
+
+// Newsletter is the definition of an email newsletter like Nanoglyph, read
+// from `content/newsletters.toml`. Its issues are built into the site and
+// sent with the `send` command.
+type Newsletter struct {
+	// ContentDir is the directory containing the newsletter's published
+	// issues, relative to the source directory.
+	ContentDir string `toml:"content_dir" validate:"required"`
+
+	// ContentDirDrafts is the directory containing drafts of the
+	// newsletter's issues, relative to the source directory.
+	ContentDirDrafts string `toml:"content_dir_drafts" validate:"required"`
+
+	// EmailTitleFormat is a format string for an issue's email subject line.
+	// It's given the issue's number and title.
+	EmailTitleFormat string `toml:"email_title_format" validate:"required"`
+
+	// FeedID is the ID of the newsletter's Atom feed.
+	FeedID string `toml:"feed_id" validate:"required"`
+
+	// HomeTitleFormat is a format string for an issue's title on the home
+	// page, given the issue's number and title. Issues only appear on the
+	// home page if it's set.
+	HomeTitleFormat string `toml:"home_title_format"`
+
+	// IndexView is the view for the newsletter's index of issues.
+	IndexView string `toml:"index_view" validate:"required"`
+
+	// Kind is a short, singular name for the newsletter like "nanoglyph"
+	// that's used to label its issues and name build jobs.
+	Kind string `toml:"kind" validate:"required"`
+
+	// MailAddress is the address of the newsletter's live mailing list.
+	MailAddress string `toml:"mail_address" validate:"required,email"`
+
+	// MailAddressStaging is the address of the newsletter's staging mailing
+	// list.
+	MailAddressStaging string `toml:"mail_address_staging" validate:"required,email"`
+
+	// Name is the newsletter's display name like "Nanoglyph".
+	Name string `toml:"name" validate:"required"`
+
+	// Slug determines where the newsletter lives. Issues are rendered to
+	// `/<slug>/<issue>`, its index to `/<slug>`, and its feed to
+	// `/<slug>.atom`.
+	Slug string `toml:"slug" validate:"required"`
+
+	// TitleFormat is a format string for an issue's title on the web and in
+	// feeds. It's given the issue's number and title.
+	TitleFormat string `toml:"title_format" validate:"required"`
+
+	// View is the view for a single issue, used on the web and in email.
+	View string `toml:"view" validate:"required"`
+}
+
+type NewsletterWrapper struct {
+	Newsletters []*Newsletter `toml:"newsletters" validate:"required,dive"`
+}
+
+func (w *NewsletterWrapper) validate() error {
+	if err := validate.Struct(w); err != nil {
+		return xerrors.Errorf("error validating newsletters: %w", err)
 	}
+
+	slugs := make(map[string]struct{}, len(w.Newsletters))
+	for _, newsletter := range w.Newsletters {
+		if _, ok := slugs[newsletter.Slug]; ok {
+			return xerrors.Errorf("duplicate newsletter slug: %s", newsletter.Slug)
+		}
+		slugs[newsletter.Slug] = struct{}{}
+	}
+
 	return nil
 }
 
@@ -1457,7 +2196,7 @@ type PhotoWrapper struct {
 
 func (w *PhotoWrapper) validate() error {
 	if err := validate.Struct(w); err != nil {
-		return xerrors.Errorf("error validating photos: %+v", err)
+		return xerrors.Errorf("error validating photos: %w", err)
 	}
 	return nil
 }
@@ -1468,7 +2207,7 @@ type SequenceWrapper struct {
 
 func (w *SequenceWrapper) validate() error {
 	if err := validate.Struct(w); err != nil {
-		return xerrors.Errorf("error validating sequences: %+v", err)
+		return xerrors.Errorf("error validating sequences: %w", err)
 	}
 
 	entrySlugs := make(map[string]struct{})
@@ -1535,13 +2274,6 @@ func (e *SequenceEntry) Equal(other *SequenceEntry) bool {
 		e.Title == other.Title
 }
 
-// Tag is a symbol assigned to an article to categorize it.
-//
-// This feature is not meanted to be overused. It's really just for tagging
-// a few particular things so that we can generate content-specific feeds for
-// certain aggregates (so far just Planet Postgres).
-type Tag string
-
 // articleYear holds a collection of articles grouped by year.
 type articleYear struct {
 	Year     int
@@ -1554,6 +2286,24 @@ type fragmentYear struct {
 	Fragments []*Fragment
 }
 
+// newsletterBuild holds the state of a single newsletter during a build.
+type newsletterBuild struct {
+	// changed is whether any of the newsletter's issues changed.
+	changed bool
+
+	// changedIssues are the slugs of the issues that changed, whose pages
+	// are rendered once all content is loaded.
+	changedIssues map[string]struct{}
+
+	// definitionChanged is whether newsletter definitions changed, which
+	// rebuilds everything for every newsletter.
+	definitionChanged bool
+
+	issues     []*snewsletter.Issue
+	mu         sync.Mutex
+	newsletter *Newsletter
+}
+
 // readingYear holds a collection of readings grouped by year.
 type readingYear struct {
 	Year     int
@@ -1601,6 +2351,25 @@ func atomSlug(publishedAt time.Time) string {
 	return lexicographicBase32Encoding.EncodeToString(i.Bytes())
 }
 
+// dependencyCacheVersion produces a version for the dependency cache that
+// changes along with the sorg executable or its configuration, either of which
+// could change the output of every page and should invalidate the cache.
+//
+// Memoized because the executable is hashed in its entirety.
+var dependencyCacheVersion = sync.OnceValue(func() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%+v\n", conf)
+
+	if executable, err := os.Executable(); err == nil {
+		if f, err := os.Open(executable); err == nil {
+			_, _ = io.Copy(hash, f)
+			f.Close()
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+})
+
 func extCanonical(originalURL string) string {
 	u, err := url.Parse(originalURL)
 	if err != nil {
@@ -1769,6 +2538,28 @@ func fetchVideo(ctx context.Context, c *modulir.Context, targetDir string, video
 	return true, nil
 }
 
+// fingerprintAssets writes each of the given asset sources to a fingerprinted
+// name under the target's `assets/<dir>`, and adds them to the assets map
+// keyed by `<dir>/<name>`.
+//
+// Unlike assets behind a symlink, fingerprinted ones can be cached forever
+// because a changed asset gets a new URL. Old versions are left in place so
+// that pages which are still cached somewhere keep working.
+func fingerprintAssets(c *modulir.Context, dir string, sources []string, assets map[string]string) error {
+	targetDir := path.Join(c.TargetDir, "assets", dir)
+
+	for _, source := range sources {
+		fingerprintedName, err := sassets.Fingerprint(source, targetDir)
+		if err != nil {
+			return xerrors.Errorf("error fingerprinting asset '%s': %w", source, err)
+		}
+
+		assets[dir+"/"+filepath.Base(source)] = dir + "/" + fingerprintedName
+	}
+
+	return nil
+}
+
 // Gets a map of local values for use while rendering a template and includes
 // a few "special" values that are globally relevant to all templates.
 func getLocals(locals map[string]any) map[string]any {
@@ -1777,7 +2568,6 @@ func getLocals(locals map[string]any) map[string]any {
 		"EnableGoatCounter": conf.EnableGoatCounter,
 		"GoogleAnalyticsID": conf.GoogleAnalyticsID,
 		"LocalFonts":        conf.LocalFonts,
-		"Release":           Release,
 		"SorgEnv":           conf.SorgEnv,
 		"TitleSuffix":       scommon.TitleSuffix,
 		"TwitterCard":       nil,
@@ -1856,18 +2646,62 @@ func insertOrReplaceFragment(fragments *[]*Fragment, fragment *Fragment) {
 		}
 	}
 
-	*fragments = append(*fragments, fragment)
+	*fragments = append(*fragments, fragment)
+}
+
+func insertOrReplaceNewsletter(issues *[]*snewsletter.Issue, issue *snewsletter.Issue) {
+	for i, s := range *issues {
+		if issue.Slug == s.Slug {
+			(*issues)[i] = issue
+			return
+		}
+	}
+
+	*issues = append(*issues, issue)
+}
+
+// loadNewsletters reads and validates newsletter definitions.
+func loadNewsletters(c *modulir.Context) ([]*Newsletter, error) {
+	var newslettersWrapper NewsletterWrapper
+	err := mtoml.ParseFile(c, c.SourceDir+"/"+newslettersFilename, &newslettersWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := newslettersWrapper.validate(); err != nil {
+		return nil, err
+	}
+
+	return newslettersWrapper.Newsletters, nil
 }
 
-func insertOrReplaceNewsletter(issues *[]*snewsletter.Issue, issue *snewsletter.Issue) {
-	for i, s := range *issues {
-		if issue.Slug == s.Slug {
-			(*issues)[i] = issue
-			return
-		}
+// logMinifyStats logs the bytes saved by minification in each section of the
+// site during the last build loop.
+func logMinifyStats(c *modulir.Context, minifier *sminify.Minifier) {
+	for _, stats := range minifier.Stats() {
+		c.Log.Infof("Minified %-12s %5d document(s): %8.1f KB -> %8.1f KB (saved %.1f KB, %.1f%%)",
+			stats.Section, stats.NumDocuments,
+			float64(stats.BytesIn)/1024, float64(stats.BytesOut)/1024,
+			float64(stats.Saved())/1024, stats.SavedPercent())
 	}
+}
 
-	*issues = append(*issues, issue)
+// minifySection gets the section of the site that a target file belongs to
+// for reporting minification stats, which is the first directory of its path
+// like `twitter` for `twitter/index.html`. Files at the top level like
+// articles and the home page are reported under `/`.
+func minifySection(targetDir, target string) string {
+	rel, err := filepath.Rel(targetDir, target)
+	if err != nil {
+		return "/"
+	}
+
+	section, _, ok := strings.Cut(filepath.ToSlash(rel), "/")
+	if !ok {
+		return "/"
+	}
+
+	return section
 }
 
 func mustLocation(locationName string) *time.Location {
@@ -1913,11 +2747,25 @@ func pathAsImage(extensionlessPath string) (string, bool) {
 func renderArticle(ctx context.Context, c *modulir.Context, source string,
 	articles *[]*Article, articlesChanged *bool, mu *sync.Mutex,
 ) (bool, error) {
-	sourceChanged := c.Changed(source)
+	sourceChanged := dependencies.changed(c, source)
 
 	sourceTmpl := scommon.ViewsDir + "/articles/show.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !sourceChanged && !viewsChanged {
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
+
+	// The hook image is drawn onto the article's card, so a changed one means
+	// that the card has to be drawn again.
+	var hookImageSource string
+	hookImageDir := path.Join(c.SourceDir, "content", "images", scommon.ExtractSlug(source))
+	hookImageFormat, ok := pathAsImage(path.Join(hookImageDir, "hook"))
+	if ok {
+		hookImageSource = path.Join(hookImageDir, "hook."+hookImageFormat)
+	}
+	hookImageChanged := cardImageChanged(c, hookImageSource)
+
+	// On the first run the article is always parsed so that it's available to
+	// indexes and feeds, but it's only rendered if it's changed since the last
+	// build.
+	if !sourceChanged && !viewsChanged && !hookImageChanged && !c.FirstRun {
 		return false, nil
 	}
 
@@ -1935,6 +2783,8 @@ func renderArticle(ctx context.Context, c *modulir.Context, source string,
 	article.Draft = scommon.IsDraft(source)
 	article.Slug = scommon.ExtractSlug(source)
 
+	renderChangelog(c, article.Changelog)
+
 	content, err := mmarkdownext.Render(string(data), &mmarkdownext.RenderOptions{
 		TemplateData: map[string]any{
 			"Ctx": ctx,
@@ -1951,6 +2801,7 @@ func renderArticle(ctx context.Context, c *modulir.Context, source string,
 
 	article.Content = template.HTML(content)
 	article.Footnotes = template.HTML(footnotes) // may be empty
+	article.Stats = sstats.Compute(content + footnotes)
 
 	toc, err := mtoc.RenderFromHTML(string(article.Content))
 	if err != nil {
@@ -1968,46 +2819,69 @@ func renderArticle(ctx context.Context, c *modulir.Context, source string,
 		article.Hook = template.HTML(mtemplate.CollapseParagraphs(hook))
 	}
 
-	format, ok := pathAsImage(
-		path.Join(c.SourceDir, "content", "images", article.Slug, "hook"),
-	)
-	if ok {
-		article.HookImageURL = "/assets/images/" + article.Slug + "/hook." + format
+	if hookImageSource != "" {
+		article.HookImageURL = "/assets/images/" + article.Slug + "/hook." + hookImageFormat
 	}
 
 	card := &twitterCard{
 		Title:       article.Title,
 		Description: string(article.Hook),
 	}
-	format, ok = pathAsImage(
+	format, ok := pathAsImage(
 		path.Join(c.SourceDir, "content", "images", article.Slug, "twitter@2x"),
 	)
 	if ok {
 		card.ImageURL = "/assets/images/" + article.Slug + "/twitter@2x." + format
+	} else {
+		// No hand-made card, so draw one.
+		card.ImageURL = cardURL(article.Slug)
+
+		err := renderContentCard(c, article.Slug, &scard.Card{
+			Date:  article.PublishedAt,
+			Hook:  splaintext.Text(string(article.Hook)),
+			Title: article.Title,
+		}, hookImageSource, cardStale(c, article.Slug, sourceChanged || hookImageChanged))
+		if err != nil {
+			return true, err
+		}
 	}
 
-	locals := getLocals(map[string]any{
-		"Article":        article,
-		"PublishingInfo": article.publishingInfo(),
-		"TwitterCard":    card,
-	})
-
-	err = dependencies.renderGoTemplate(ctx, c, sourceTmpl, path.Join(c.TargetDir, article.Slug), locals)
-	if err != nil {
-		return true, err
-	}
+	// The article's page is rendered once all content is loaded because it
+	// shows its series and backlinks.
+	article.card = card
+	article.changed = sourceChanged || viewsChanged
 
 	mu.Lock()
 	insertOrReplaceArticle(articles, &article)
-	*articlesChanged = true
+	*articlesChanged = *articlesChanged || sourceChanged || viewsChanged
 	mu.Unlock()
 
 	return true, nil
 }
 
+// renderArticleShow renders an article's page. series is navigation within
+// the article's series, or nil if it's not in one.
+func renderArticleShow(ctx context.Context, c *modulir.Context, article *Article, series *seriesNav,
+	backlinks, related []*contentLink,
+) error {
+	locals := getLocals(map[string]any{
+		"Article":        article,
+		"Backlinks":      backlinks,
+		"PublishingInfo": article.publishingInfo(),
+		"Related":        related,
+		"Scheduled":      isScheduled(c, article.PublishedAt),
+		"Series":         series,
+		"TwitterCard":    article.card,
+		"UpdatedAt":      article.lastUpdatedAt(),
+	})
+
+	return dependencies.renderGoTemplate(ctx, c, scommon.ViewsDir+"/articles/show.tmpl.html",
+		path.Join(c.TargetDir, article.Slug), locals)
+}
+
 func renderArticlesIndex(ctx context.Context, c *modulir.Context, articles []*Article, articlesChanged bool) (bool, error) {
 	sourceTmpl := scommon.ViewsDir + "/articles/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
 	if !articlesChanged && !viewsChanged {
 		return false, nil
 	}
@@ -2029,61 +2903,28 @@ func renderArticlesFeed(_ *modulir.Context, articles []*Article, tag *Tag, artic
 
 	name := "articles"
 	if tag != nil {
-		name = fmt.Sprintf("articles-%s", *tag)
+		name = tagFeedName(name, *tag)
 	}
-	atomPath := name + ".atom"
-
 	title := "Articles" + scommon.TitleSuffix
 	if tag != nil {
 		title = fmt.Sprintf("Articles%s (%s)", scommon.TitleSuffix, *tag)
 	}
 
-	feed := &matom.Feed{
-		Title: title,
+	f := &feed{
 		ID:    "tag:" + scommon.AtomTag + ",2013:/" + name,
-
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/" + atomPath},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
-	}
-
-	if len(articles) > 0 {
-		feed.Updated = articles[0].PublishedAt
+		Name:  name,
+		Title: title,
 	}
 
-	for i, article := range articles {
+	for _, article := range articles {
 		if tag != nil && !article.taggedWith(*tag) {
 			continue
 		}
 
-		if i >= conf.NumAtomEntries {
-			break
-		}
-
-		entry := &matom.Entry{
-			Title:     article.Title,
-			Summary:   string(article.Hook),
-			Content:   &matom.EntryContent{Content: string(article.Content), Type: "html"},
-			Published: article.PublishedAt,
-			Updated:   article.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/" + article.Slug},
-			ID:        "tag:" + scommon.AtomTag + "," + article.PublishedAt.Format("2006-01-02") + ":" + article.Slug,
-
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
-		}
-		feed.Entries = append(feed.Entries, entry)
-	}
-
-	filename := path.Join(conf.TargetDir, atomPath)
-	f, err := os.Create(filename)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filename, err)
+		f.Entries = append(f.Entries, articleFeedEntry(article))
 	}
-	defer f.Close()
 
-	return true, feed.Encode(f, "  ")
+	return true, writeFeed(f)
 }
 
 // Number of atoms on the atom index page (the rest are on the archive page
@@ -2094,7 +2935,7 @@ func renderAtomArchive(ctx context.Context, c *modulir.Context, atoms []*Atom, a
 ) (bool, error) {
 	source := scommon.ViewsDir + "/atoms/index.tmpl.html"
 
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !atomsChanged && !viewsChanged {
 		return false, nil
 	}
@@ -2112,38 +2953,32 @@ func renderAtomArchive(ctx context.Context, c *modulir.Context, atoms []*Atom, a
 	return true, nil
 }
 
-func renderAtom(ctx context.Context, c *modulir.Context, atom *Atom, atomIndex int, atomsChanged bool,
+func renderAtom(ctx context.Context, c *modulir.Context, atom *Atom, atomIndex int, backlinks []*contentLink,
+	atomsChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/atoms/show.tmpl.html"
 
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !atomsChanged && !viewsChanged {
 		return false, nil
 	}
 
-	var title string
-	if atom.Title == nil {
-		// Twitter doesn't play nicely showing "<" or ">", so don't put those in
-		title = "Atom #" + atom.Slug
-	} else {
-		title = *atom.Title
-	}
+	title := atom.displayTitle()
 
+	// The card image itself is drawn by a separate job once the atom's photos
+	// have been fetched.
 	card := &twitterCard{
 		Description: truncateString(simplifyMarkdownForSummary(atom.Description), 200),
-		ImageURL:    "", // empty defaults to site favicon
+		ImageURL:    cardURL("atoms/" + atom.Slug),
 		Title:       title,
 	}
-	if len(atom.Photos) > 0 {
-		photo := atom.Photos[0]
-		card.ImageURL = fmt.Sprintf("/photographs/atoms/%s/%s_large@2x%s",
-			atom.Slug, photo.Slug, photo.TargetExt())
-	}
 
 	locals := getLocals(map[string]any{
 		"Atom":        atom,
 		"AtomIndex":   atomIndex,
+		"Backlinks":   backlinks,
 		"IndexMax":    maxAtomsIndex,
+		"Scheduled":   isScheduled(c, atom.PublishedAt),
 		"Title":       title,
 		"TwitterCard": card,
 	})
@@ -2156,6 +2991,27 @@ func renderAtom(ctx context.Context, c *modulir.Context, atom *Atom, atomIndex i
 	return true, nil
 }
 
+func renderAtomCard(c *modulir.Context, atom *Atom, atomsChanged bool) (bool, error) {
+	name := "atoms/" + atom.Slug
+	if !cardStale(c, name, atomsChanged) {
+		return false, nil
+	}
+
+	var imageSource string
+	if len(atom.Photos) > 0 {
+		photo := atom.Photos[0]
+		imageSource = fmt.Sprintf("%s/content/photographs/atoms/%s/%s_large%s",
+			c.SourceDir, atom.Slug, photo.Slug, photo.TargetExt())
+	}
+
+	_, err := renderCard(c, name, &scard.Card{
+		Date:  atom.PublishedAt,
+		Hook:  splaintext.Text(string(atom.DescriptionHTML)),
+		Title: atom.displayTitle(),
+	}, imageSource)
+	return true, err
+}
+
 var markdownLinkRE = regexp.MustCompile(`\[(.*?)\]\(.*?\)`)
 
 func simplifyMarkdownForSummary(str string) string {
@@ -2174,32 +3030,33 @@ func truncateString(str string, maxLength int) string {
 
 // Renders an Atom feed for atoms. The entries slice is assumed to be
 // pre-sorted.
-func renderAtomFeed(ctx context.Context, c *modulir.Context, atoms []*Atom, atomsChanged bool,
+func renderAtomFeed(ctx context.Context, c *modulir.Context, atoms []*Atom, tag *Tag, atomsChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/atoms/_atom_atom.tmpl.html"
 
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !atomsChanged && !viewsChanged {
 		return false, nil
 	}
 
-	feed := &matom.Feed{
-		Title: "Atoms " + scommon.TitleSuffix,
-		ID:    "tag:" + scommon.AtomTag + ",2019:atoms",
-
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/atoms.atom"},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
+	name := "atoms"
+	if tag != nil {
+		name = tagFeedName(name, *tag)
+	}
+	title := "Atoms " + scommon.TitleSuffix
+	if tag != nil {
+		title = fmt.Sprintf("Atoms%s (%s)", scommon.TitleSuffix, *tag)
 	}
 
-	if len(atoms) > 0 {
-		feed.Updated = atoms[0].PublishedAt
+	f := &feed{
+		ID:    "tag:" + scommon.AtomTag + ",2019:" + name,
+		Name:  name,
+		Title: title,
 	}
 
-	for i, atom := range atoms {
-		if i >= conf.NumAtomEntries {
-			break
+	for _, atom := range atoms {
+		if tag != nil && !atom.taggedWith(*tag) {
+			continue
 		}
 
 		locals := getLocals(map[string]any{
@@ -2212,41 +3069,34 @@ func renderAtomFeed(ctx context.Context, c *modulir.Context, atoms []*Atom, atom
 			return true, err
 		}
 
-		title := "Atom #" + atom.Slug
-		if atom.Title != nil {
-			title = *atom.Title
-		}
-
-		entry := &matom.Entry{
-			Title:     title,
-			Content:   &matom.EntryContent{Content: contentBuf.String(), Type: "html"},
-			Published: atom.PublishedAt,
-			Updated:   atom.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/atoms/" + atom.Slug},
-			ID: "tag:" + scommon.AtomTag + "," + atom.PublishedAt.Format("2006-01-02") +
-				":atoms:" + atom.Slug,
+		title := atom.displayTitle()
 
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
+		var image string
+		if len(atom.Photos) > 0 {
+			photo := atom.Photos[0]
+			image = fmt.Sprintf("/photographs/atoms/%s/%s_large@2x%s",
+				atom.Slug, photo.Slug, photo.TargetExt())
 		}
-		feed.Entries = append(feed.Entries, entry)
-	}
 
-	filePath := path.Join(conf.TargetDir, "atoms.atom")
-	f, err := os.Create(filePath)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filePath, err)
+		f.Entries = append(f.Entries, &feedEntry{
+			Content:     contentBuf.String(),
+			ID:          feedEntryID(atom.PublishedAt, "atoms:"+atom.Slug),
+			Image:       image,
+			PublishedAt: atom.PublishedAt,
+			Tags:        tagStrings(atom.Tags),
+			Title:       title,
+			URL:         conf.AbsoluteURL + "/atoms/" + atom.Slug,
+		})
 	}
-	defer f.Close()
 
-	return true, feed.Encode(f, "  ")
+	return true, writeFeed(f)
 }
 
 func renderAtomIndex(ctx context.Context, c *modulir.Context, atoms []*Atom, atomsChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/atoms/index.tmpl.html"
 
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !atomsChanged && !viewsChanged {
 		return false, nil
 	}
@@ -2274,11 +3124,15 @@ func renderAtomIndex(ctx context.Context, c *modulir.Context, atoms []*Atom, ato
 func renderFragment(ctx context.Context, c *modulir.Context, source string,
 	fragments *[]*Fragment, fragmentsChanged *bool, mu *sync.Mutex,
 ) (bool, error) {
-	sourceChanged := c.Changed(source)
+	sourceChanged := dependencies.changed(c, source)
 
 	sourceTmpl := scommon.ViewsDir + "/fragments/show.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !sourceChanged && !viewsChanged {
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
+
+	// On the first run the fragment is always parsed so that it's available
+	// to indexes and feeds, but it's only rendered if it's changed since the
+	// last build.
+	if !sourceChanged && !viewsChanged && !c.FirstRun {
 		return false, nil
 	}
 
@@ -2296,6 +3150,8 @@ func renderFragment(ctx context.Context, c *modulir.Context, source string,
 	fragment.Draft = scommon.IsDraft(source)
 	fragment.Slug = scommon.ExtractSlug(source)
 
+	renderChangelog(c, fragment.Changelog)
+
 	content, err := mmarkdownext.Render(string(data), &mmarkdownext.RenderOptions{
 		TemplateData: map[string]any{
 			"Ctx": ctx,
@@ -2312,6 +3168,7 @@ func renderFragment(ctx context.Context, c *modulir.Context, source string,
 
 	fragment.Content = template.HTML(content)
 	fragment.Footnotes = template.HTML(footnotes) // may be empty
+	fragment.Stats = sstats.Compute(content + footnotes)
 
 	if fragment.Hook != "" {
 		hook, err := mmarkdownext.Render(string(fragment.Hook), nil)
@@ -2331,84 +3188,104 @@ func renderFragment(ctx context.Context, c *modulir.Context, source string,
 	)
 	if ok {
 		card.ImageURL = "/assets/images/fragments/" + fragment.Slug + "/twitter@2x." + format
+	} else {
+		// No hand-made card, so draw one.
+		cardName := "fragments/" + fragment.Slug
+		card.ImageURL = cardURL(cardName)
+
+		// A remote image is fetched to a local vista in a later phase, so it
+		// won't be available the first time that the fragment is built, in
+		// which case the card is drawn without it and drawn again once it's
+		// there.
+		var imageSource string
+		switch {
+		case fragment.ImageURL != "":
+			imageSource = c.SourceDir + "/content/photographs/fragments/" + fragment.Slug + "/vista.jpg"
+		case fragment.Image != "":
+			imageSource, _ = cardImageSource(c, fragment.Image)
+		}
+
+		err := renderContentCard(c, cardName, &scard.Card{
+			Date:  fragment.PublishedAt,
+			Hook:  splaintext.Text(string(fragment.Hook)),
+			Title: fragment.Title,
+		}, imageSource, cardStale(c, cardName, sourceChanged || cardImageChanged(c, imageSource)))
+		if err != nil {
+			return true, err
+		}
 	}
 
-	locals := getLocals(map[string]any{
-		"Fragment":       fragment,
-		"PublishingInfo": fragment.publishingInfo(),
-		"TwitterCard":    card,
-	})
-
-	err = dependencies.renderGoTemplate(ctx, c, sourceTmpl, path.Join(c.TargetDir, "fragments", fragment.Slug), locals)
-	if err != nil {
-		return true, err
-	}
+	// The fragment's page is rendered once all content is loaded because it
+	// shows its series and backlinks.
+	fragment.card = card
+	fragment.changed = sourceChanged || viewsChanged
 
 	mu.Lock()
 	insertOrReplaceFragment(fragments, &fragment)
-	*fragmentsChanged = true
+	*fragmentsChanged = *fragmentsChanged || sourceChanged || viewsChanged
 	mu.Unlock()
 
 	return true, nil
 }
 
-func renderFragmentsFeed(_ *modulir.Context, fragments []*Fragment,
+func renderFragmentsFeed(_ *modulir.Context, fragments []*Fragment, tag *Tag,
 	fragmentsChanged bool,
 ) (bool, error) {
 	if !fragmentsChanged {
 		return false, nil
 	}
 
-	feed := &matom.Feed{
-		Title: "Fragments" + scommon.TitleSuffix,
-		ID:    "tag:" + scommon.AtomTag + ",2013:/fragments",
-
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/fragments.atom"},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
+	name := "fragments"
+	if tag != nil {
+		name = tagFeedName(name, *tag)
+	}
+	title := "Fragments" + scommon.TitleSuffix
+	if tag != nil {
+		title = fmt.Sprintf("Fragments%s (%s)", scommon.TitleSuffix, *tag)
 	}
 
-	if len(fragments) > 0 {
-		feed.Updated = fragments[0].PublishedAt
+	f := &feed{
+		ID:    "tag:" + scommon.AtomTag + ",2013:/" + name,
+		Name:  name,
+		Title: title,
 	}
 
-	for i, fragment := range fragments {
-		if i >= conf.NumAtomEntries {
-			break
+	for _, fragment := range fragments {
+		if tag != nil && !fragment.taggedWith(*tag) {
+			continue
 		}
 
-		entry := &matom.Entry{
-			Title:     fragment.Title,
-			Summary:   string(fragment.Hook),
-			Content:   &matom.EntryContent{Content: string(fragment.Content), Type: "html"},
-			Published: fragment.PublishedAt,
-			Updated:   fragment.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/fragments/" + fragment.Slug},
-			ID: "tag:" + scommon.AtomTag + "," + fragment.PublishedAt.Format("2006-01-02") +
-				":fragments/" + fragment.Slug,
-
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
-		}
-		feed.Entries = append(feed.Entries, entry)
+		f.Entries = append(f.Entries, fragmentFeedEntry(fragment))
 	}
 
-	filename := conf.TargetDir + "/fragments.atom"
-	f, err := os.Create(filename)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filename, err)
-	}
-	defer f.Close()
+	return true, writeFeed(f)
+}
+
+// renderFragmentShow renders a fragment's page. series is navigation within
+// the fragment's series, or nil if it's not in one.
+func renderFragmentShow(ctx context.Context, c *modulir.Context, fragment *Fragment, series *seriesNav,
+	backlinks, related []*contentLink,
+) error {
+	locals := getLocals(map[string]any{
+		"Backlinks":      backlinks,
+		"Fragment":       fragment,
+		"PublishingInfo": fragment.publishingInfo(),
+		"Related":        related,
+		"Scheduled":      isScheduled(c, fragment.PublishedAt),
+		"Series":         series,
+		"TwitterCard":    fragment.card,
+		"UpdatedAt":      fragment.lastUpdatedAt(),
+	})
 
-	return true, feed.Encode(f, "  ")
+	return dependencies.renderGoTemplate(ctx, c, scommon.ViewsDir+"/fragments/show.tmpl.html",
+		path.Join(c.TargetDir, "fragments", fragment.Slug), locals)
 }
 
 func renderFragmentsIndex(ctx context.Context, c *modulir.Context, fragments []*Fragment,
 	fragmentsChanged bool,
 ) (bool, error) {
 	sourceTmpl := scommon.ViewsDir + "/fragments/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
 	if !fragmentsChanged && !viewsChanged {
 		return false, nil
 	}
@@ -2423,199 +3300,115 @@ func renderFragmentsIndex(ctx context.Context, c *modulir.Context, fragments []*
 		path.Join(c.TargetDir, "fragments/index.html"), locals)
 }
 
-func renderNanoglyph(ctx context.Context, c *modulir.Context, source string,
-	issues *[]*snewsletter.Issue, nanoglyphsChanged *bool, mu *sync.Mutex,
-) (bool, error) {
-	sourceChanged := c.Changed(source)
-	sourceTmpl := scommon.ViewsDir + "/nanoglyphs/show.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !sourceChanged && !viewsChanged {
-		return false, nil
-	}
+func renderNewsletterIssue(c *modulir.Context, nb *newsletterBuild, source string) (bool, error) {
+	newsletter := nb.newsletter
 
-	issue, err := snewsletter.Render(c, filepath.Dir(source), filepath.Base(source),
-		"", false)
-	if err != nil {
-		return true, err
-	}
+	sourceChanged := dependencies.changed(c, source)
+	sourceTmpl := c.SourceDir + "/" + newsletter.View
+	viewsChanged := nb.definitionChanged || dependencies.viewChanged(c, sourceTmpl)
 
-	format, ok := pathAsImage(
-		path.Join(c.SourceDir, "content", "images", "nanoglyphs", issue.Slug, "hook"),
-	)
+	// The hook image is drawn onto the issue's card.
+	var hookImageSource string
+	hookImageDir := path.Join(c.SourceDir, "content", "images", newsletter.Slug, scommon.ExtractSlug(source))
+	hookImageFormat, ok := pathAsImage(path.Join(hookImageDir, "hook"))
 	if ok {
-		issue.HookImageURL = "/assets/images/nanoglyphs/" + issue.Slug + "/hook." + format
+		hookImageSource = path.Join(hookImageDir, "hook."+hookImageFormat)
 	}
+	hookImageChanged := cardImageChanged(c, hookImageSource)
 
-	var card *twitterCard
-	if issue.ImageURL != "" {
-		card = &twitterCard{
-			Title:       fmt.Sprintf("Nanoglyph %s — %s", issue.Number, issue.Title),
-			Description: string(issue.Hook),
-			ImageURL:    issue.ImageURL,
-		}
+	// On the first run the issue is always parsed so that it's available to
+	// indexes and feeds, but it's only rendered if it's changed since the last
+	// build.
+	if !sourceChanged && !viewsChanged && !hookImageChanged && !c.FirstRun {
+		return false, nil
 	}
 
-	locals := getLocals(map[string]any{
-		"InEmail":     false,
-		"Issue":       issue,
-		"TwitterCard": card,
-		"URLPrefix":   "", // Relative prefix for the web version
-	})
-
-	err = dependencies.renderGoTemplate(ctx, c, sourceTmpl, path.Join(c.TargetDir, "nanoglyphs", issue.Slug), locals)
+	issue, err := snewsletter.Render(c, filepath.Dir(source), filepath.Base(source),
+		"", false)
 	if err != nil {
 		return true, err
 	}
 
-	mu.Lock()
-	insertOrReplaceNewsletter(issues, issue)
-	*nanoglyphsChanged = true
-	mu.Unlock()
-
-	return true, nil
-}
-
-func renderNanoglyphsFeed(_ *modulir.Context, issues []*snewsletter.Issue, nanoglyphsChanged bool) (bool, error) {
-	if !nanoglyphsChanged {
-		return false, nil
-	}
-
-	name := "nanoglyphs"
-	filename := name + ".atom"
-
-	feed := &matom.Feed{
-		Title: "Nanoglyph" + scommon.TitleSuffix,
-		ID:    "tag:" + scommon.AtomTag + ",2013:/" + name,
-
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/" + filename},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
-	}
-
-	if len(articles) > 0 {
-		feed.Updated = issues[0].PublishedAt
+	if hookImageSource != "" {
+		issue.HookImageURL = "/assets/images/" + newsletter.Slug + "/" + issue.Slug + "/hook." + hookImageFormat
 	}
 
-	for i, issue := range issues {
-		if i >= conf.NumAtomEntries {
-			break
-		}
-
-		content := issue.Content
-		if issue.ImageURL != "" {
-			content = template.HTML(fmt.Sprintf(`<p><img src="%s" alt="%s" /></p>`, issue.ImageURL, issue.ImageAlt)) + content
-		}
+	if issue.ImageURL == "" {
+		// No main image for the issue, so draw a card.
+		cardName := newsletter.Slug + "/" + issue.Slug
 
-		entry := &matom.Entry{
-			Title:     fmt.Sprintf("Nanoglyph %s — %s", issue.Number, issue.Title),
-			Content:   &matom.EntryContent{Content: string(content), Type: "html"},
-			Published: issue.PublishedAt,
-			Updated:   issue.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/nanoglyphs/" + issue.Slug},
-			ID:        "tag:" + scommon.AtomTag + "," + issue.PublishedAt.Format("2006-01-02") + ":" + issue.Slug,
-
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
+		err := renderContentCard(c, cardName, &scard.Card{
+			Date:  issue.PublishedAt,
+			Hook:  splaintext.Text(string(issue.Hook)),
+			Title: fmt.Sprintf(newsletter.TitleFormat, issue.Number, issue.Title),
+		}, hookImageSource, cardStale(c, cardName, sourceChanged || hookImageChanged))
+		if err != nil {
+			return true, err
 		}
-		feed.Entries = append(feed.Entries, entry)
-	}
-
-	filePath := path.Join(conf.TargetDir, filename)
-	f, err := os.Create(filePath)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filePath, err)
 	}
-	defer f.Close()
-
-	return true, feed.Encode(f, "  ")
-}
 
-func renderNanoglyphsIndex(ctx context.Context, c *modulir.Context, issues []*snewsletter.Issue,
-	nanoglyphsChanged bool,
-) (bool, error) {
-	sourceTmpl := scommon.ViewsDir + "/nanoglyphs/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !nanoglyphsChanged && !viewsChanged {
-		return false, nil
+	// The issue's page is rendered once all content is loaded because it
+	// shows its backlinks.
+	nb.mu.Lock()
+	insertOrReplaceNewsletter(&nb.issues, issue)
+	nb.changed = nb.changed || sourceChanged || viewsChanged
+	if sourceChanged || viewsChanged {
+		nb.changedIssues[issue.Slug] = struct{}{}
 	}
+	nb.mu.Unlock()
 
-	locals := getLocals(map[string]any{
-		"Issues":    issues,
-		"URLPrefix": "", // Relative prefix for the web version
-	})
-
-	return true, dependencies.renderGoTemplate(ctx, c, sourceTmpl,
-		path.Join(c.TargetDir, "nanoglyphs/index.html"), locals)
+	return true, nil
 }
 
-func renderPassage(ctx context.Context, c *modulir.Context, source string,
-	issues *[]*snewsletter.Issue, passagesChanged *bool, mu *sync.Mutex,
-) (bool, error) {
-	sourceChanged := c.Changed(source)
-	sourceTmpl := scommon.ViewsDir + "/passages/show.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !sourceChanged && !viewsChanged {
-		return false, nil
-	}
+// renderNewsletterIssueShow renders the web version of a newsletter issue.
+func renderNewsletterIssueShow(ctx context.Context, c *modulir.Context, nb *newsletterBuild,
+	issue *snewsletter.Issue, backlinks []*contentLink,
+) error {
+	newsletter := nb.newsletter
 
-	issue, err := snewsletter.Render(c, filepath.Dir(source), filepath.Base(source),
-		"", false)
-	if err != nil {
-		return true, err
+	card := &twitterCard{
+		Title:       fmt.Sprintf(newsletter.TitleFormat, issue.Number, issue.Title),
+		Description: string(issue.Hook),
+		ImageURL:    issue.ImageURL,
 	}
-
-	format, ok := pathAsImage(
-		path.Join(c.SourceDir, "content", "images", "passages", issue.Slug, "hook"),
-	)
-	if ok {
-		issue.HookImageURL = "/assets/images/passages/" + issue.Slug + "/hook." + format
+	if card.ImageURL == "" {
+		card.ImageURL = cardURL(newsletter.Slug + "/" + issue.Slug)
 	}
 
 	locals := getLocals(map[string]any{
-		"InEmail":   false,
-		"Issue":     issue,
-		"URLPrefix": "", // Relative prefix for the web version
+		"Backlinks":   backlinks,
+		"InEmail":     false,
+		"Issue":       issue,
+		"Scheduled":   isScheduled(c, issue.PublishedAt),
+		"TwitterCard": card,
+		"URLPrefix":   "", // Relative prefix for the web version
 	})
 
-	err = dependencies.renderGoTemplate(ctx, c, sourceTmpl, path.Join(c.TargetDir, "passages", issue.Slug), locals)
-	if err != nil {
-		return true, err
-	}
-
-	mu.Lock()
-	insertOrReplaceNewsletter(issues, issue)
-	*passagesChanged = true
-	mu.Unlock()
-
-	return true, nil
+	return dependencies.renderGoTemplate(ctx, c, c.SourceDir+"/"+newsletter.View,
+		path.Join(c.TargetDir, newsletter.Slug, issue.Slug), locals)
 }
 
-func renderPassagesFeed(_ *modulir.Context, issues []*snewsletter.Issue, passagesChanged bool) (bool, error) {
-	if !passagesChanged {
+func renderNewsletterFeed(_ *modulir.Context, nb *newsletterBuild, tag *Tag) (bool, error) {
+	if !nb.changed && !nb.definitionChanged {
 		return false, nil
 	}
 
-	name := "passages"
-	filename := name + ".atom"
-
-	feed := &matom.Feed{
-		Title: "Passages & Glass" + scommon.TitleSuffix,
-		ID:    "tag:" + scommon.AtomTag + ",2013:/" + name,
+	newsletter := nb.newsletter
 
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/" + filename},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
+	f := &feed{
+		ID:    newsletter.FeedID,
+		Name:  newsletter.Slug,
+		Title: newsletter.Name + scommon.TitleSuffix,
 	}
-
-	if len(articles) > 0 {
-		feed.Updated = issues[0].PublishedAt
+	if tag != nil {
+		f.ID = tagFeedName(f.ID, *tag)
+		f.Name = tagFeedName(f.Name, *tag)
+		f.Title = fmt.Sprintf("%s%s (%s)", newsletter.Name, scommon.TitleSuffix, *tag)
 	}
 
-	for i, issue := range issues {
-		if i >= conf.NumAtomEntries {
-			break
+	for _, issue := range nb.issues {
+		if tag != nil && !slices.Contains(issueTags(issue), *tag) {
+			continue
 		}
 
 		content := issue.Content
@@ -2623,53 +3416,41 @@ func renderPassagesFeed(_ *modulir.Context, issues []*snewsletter.Issue, passage
 			content = template.HTML(fmt.Sprintf(`<p><img src="%s" alt="%s" /></p>`, issue.ImageURL, issue.ImageAlt)) + content
 		}
 
-		entry := &matom.Entry{
-			Title:     fmt.Sprintf("Passages & Glass %s — %s", issue.Number, issue.Title),
-			Content:   &matom.EntryContent{Content: string(content), Type: "html"},
-			Published: issue.PublishedAt,
-			Updated:   issue.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/passages/" + issue.Slug},
-			ID:        "tag:" + scommon.AtomTag + "," + issue.PublishedAt.Format("2006-01-02") + ":" + issue.Slug,
-
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
-		}
-		feed.Entries = append(feed.Entries, entry)
-	}
-
-	filePath := path.Join(conf.TargetDir, filename)
-	f, err := os.Create(filePath)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filePath, err)
+		f.Entries = append(f.Entries, &feedEntry{
+			Content:     string(content),
+			ID:          feedEntryID(issue.PublishedAt, issue.Slug),
+			Image:       issue.ImageURL,
+			PublishedAt: issue.PublishedAt,
+			Tags:        issue.Tags,
+			Title:       fmt.Sprintf(newsletter.TitleFormat, issue.Number, issue.Title),
+			URL:         conf.AbsoluteURL + "/" + newsletter.Slug + "/" + issue.Slug,
+		})
 	}
-	defer f.Close()
 
-	return true, feed.Encode(f, "  ")
+	return true, writeFeed(f)
 }
 
-func renderPassagesIndex(ctx context.Context, c *modulir.Context, issues []*snewsletter.Issue,
-	passagesChanged bool,
-) (bool, error) {
-	sourceTmpl := scommon.ViewsDir + "/passages/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !passagesChanged && !viewsChanged {
+func renderNewsletterIndex(ctx context.Context, c *modulir.Context, nb *newsletterBuild) (bool, error) {
+	sourceTmpl := c.SourceDir + "/" + nb.newsletter.IndexView
+	viewsChanged := nb.definitionChanged || dependencies.viewChanged(c, sourceTmpl)
+	if !nb.changed && !viewsChanged {
 		return false, nil
 	}
 
 	locals := getLocals(map[string]any{
-		"Issues":    issues,
+		"Issues":    nb.issues,
 		"URLPrefix": "", // Relative prefix for the web version
 	})
 
 	return true, dependencies.renderGoTemplate(ctx, c, sourceTmpl,
-		path.Join(c.TargetDir, "passages/index.html"), locals)
+		path.Join(c.TargetDir, nb.newsletter.Slug, "index.html"), locals)
 }
 
 // RecentWriting is a unified entry for the home page that can represent an
-// article, fragment, or nanoglyph issue.
+// article, fragment, or newsletter issue.
 type RecentWriting struct {
 	Hook        template.HTML
-	Kind        string // "article", "fragment", or "nanoglyph"
+	Kind        string // "article", "fragment", or a newsletter's kind like "nanoglyph"
 	KindURL     string // index page for the kind, e.g. "/articles"
 	PublishedAt time.Time
 	Title       string
@@ -2677,12 +3458,24 @@ type RecentWriting struct {
 }
 
 func renderHome(ctx context.Context, c *modulir.Context,
-	articles []*Article, fragments []*Fragment, nanoglyphs []*snewsletter.Issue, sequences []*SequenceEntry,
-	articlesChanged, fragmentsChanged, nanoglyphsChanged, photosChanged, sequencesChanged bool,
+	articles []*Article, fragments []*Fragment, newsletterBuilds []*newsletterBuild, sequences []*SequenceEntry,
+	articlesChanged, fragmentsChanged, photosChanged, sequencesChanged bool,
 ) (bool, error) {
 	sourceTmpl := scommon.ViewsDir + "/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(sourceTmpl)...)
-	if !articlesChanged && !fragmentsChanged && !nanoglyphsChanged && !photosChanged && !sequencesChanged && !viewsChanged {
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
+
+	// Only newsletters with a home title format are shown on the home page.
+	var homeNewsletterBuilds []*newsletterBuild
+	var newslettersChanged bool
+	for _, nb := range newsletterBuilds {
+		if nb.newsletter.HomeTitleFormat == "" {
+			continue
+		}
+		homeNewsletterBuilds = append(homeNewsletterBuilds, nb)
+		newslettersChanged = newslettersChanged || nb.changed || nb.definitionChanged
+	}
+
+	if !articlesChanged && !fragmentsChanged && !newslettersChanged && !photosChanged && !sequencesChanged && !viewsChanged {
 		return false, nil
 	}
 
@@ -2714,18 +3507,20 @@ func renderHome(ctx context.Context, c *modulir.Context,
 			URL:         "/fragments/" + f.Slug,
 		})
 	}
-	for _, n := range nanoglyphs {
-		if n.Draft {
-			continue
+	for _, nb := range homeNewsletterBuilds {
+		for _, n := range nb.issues {
+			if n.Draft {
+				continue
+			}
+			writings = append(writings, RecentWriting{
+				Hook:        n.Hook,
+				Kind:        nb.newsletter.Kind,
+				KindURL:     "/" + nb.newsletter.Slug,
+				PublishedAt: n.PublishedAt,
+				Title:       fmt.Sprintf(nb.newsletter.HomeTitleFormat, n.Number, n.Title),
+				URL:         "/" + nb.newsletter.Slug + "/" + n.Slug,
+			})
 		}
-		writings = append(writings, RecentWriting{
-			Hook:        n.Hook,
-			Kind:        "nanoglyph",
-			KindURL:     "/nanoglyphs",
-			PublishedAt: n.PublishedAt,
-			Title:       "ⓝ " + n.Number + " — " + n.Title,
-			URL:         "/nanoglyphs/" + n.Slug,
-		})
 	}
 	slices.SortFunc(writings, func(a, b RecentWriting) int {
 		return b.PublishedAt.Compare(a.PublishedAt)
@@ -2755,17 +3550,21 @@ func renderPage(ctx context.Context, c *modulir.Context,
 
 	// Other dependencies a page might have if it say, included an external
 	// Markdown file. These are added the first time a page is rendered (and
-	// watched), and updated on every subsequent run.
+	// watched), and updated on every subsequent run. Before a page's first
+	// render in this process, fall back to dependencies restored from the
+	// dependency cache (if there was one).
 	var pageDependencies []string
 
 	mu.RLock()
 	pageMeta, ok := meta[pagePath]
 	if ok {
 		pageDependencies = pageMeta.dependencies
+	} else {
+		pageDependencies = dependencies.getDependencies(source)
 	}
 	mu.RUnlock()
 
-	viewsChanged := c.ChangedAny(append(
+	viewsChanged := dependencies.changedAny(c, append(
 		[]string{
 			scommon.MainLayout,
 			source,
@@ -2822,13 +3621,9 @@ func renderPage(ctx context.Context, c *modulir.Context,
 
 func renderReading(ctx context.Context, c *modulir.Context) (bool, error) {
 	source := scommon.ViewsDir + "/reading/index.tmpl.html"
-	viewsChanged := c.ChangedAny(
-		append([]string{
-			c.SourceDir + "/content/reading/_meta.toml",
-		},
-			dependencies.getDependencies(source)...,
-		)...)
-	if !c.FirstRun && !viewsChanged {
+	viewsChanged := dependencies.changed(c, c.SourceDir+"/content/reading/_meta.toml")
+	viewsChanged = dependencies.viewChanged(c, source) || viewsChanged
+	if !viewsChanged {
 		return false, nil
 	}
 
@@ -2855,7 +3650,7 @@ func renderPhotoIndex(ctx context.Context, c *modulir.Context, photos []*Photo,
 	photosChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/photos/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !photosChanged && !viewsChanged {
 		return false, nil
 	}
@@ -2897,6 +3692,8 @@ Disallow:
 User-agent: *
 Disallow: /photographs/
 Disallow: /photos
+
+Sitemap: ` + conf.AbsoluteURL + `/sitemap.xml
 `
 	}
 
@@ -2915,8 +3712,8 @@ Disallow: /photos
 
 func renderRuns(ctx context.Context, c *modulir.Context) (bool, error) {
 	source := scommon.ViewsDir + "/runs/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
-	if !c.FirstRun && !viewsChanged {
+	viewsChanged := dependencies.viewChanged(c, source)
+	if !viewsChanged {
 		return false, nil
 	}
 
@@ -2936,30 +3733,18 @@ func renderSequenceFeed(ctx context.Context, c *modulir.Context,
 	entries []*SequenceEntry, sequencesChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/sequences/_entry_atom.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !sequencesChanged && !viewsChanged {
 		return false, nil
 	}
 
-	feed := &matom.Feed{
-		Title: "Sequences" + scommon.TitleSuffix,
+	f := &feed{
 		ID:    "tag:" + scommon.AtomTag + ",2019:sequences",
-
-		Links: []*matom.Link{
-			{Rel: "self", Type: "application/atom+xml", Href: "https://brandur.org/sequences.atom"},
-			{Rel: "alternate", Type: "text/html", Href: "https://brandur.org"},
-		},
-	}
-
-	if len(entries) > 0 {
-		feed.Updated = entries[0].PublishedAt
+		Name:  "sequences",
+		Title: "Sequences" + scommon.TitleSuffix,
 	}
 
-	for i, entry := range entries {
-		if i >= conf.NumAtomEntries {
-			break
-		}
-
+	for _, entry := range entries {
 		locals := getLocals(map[string]any{
 			"Entry": entry,
 		})
@@ -2970,36 +3755,31 @@ func renderSequenceFeed(ctx context.Context, c *modulir.Context,
 			return true, err
 		}
 
-		entry := &matom.Entry{
-			Title:     entry.Slug + " — " + entry.Title,
-			Content:   &matom.EntryContent{Content: contentBuf.String(), Type: "html"},
-			Published: entry.PublishedAt,
-			Updated:   entry.PublishedAt,
-			Link:      &matom.Link{Href: conf.AbsoluteURL + "/sequences/" + entry.Slug},
-			ID: "tag:" + scommon.AtomTag + "," + entry.PublishedAt.Format("2006-01-02") +
-				":sequences:" + entry.Slug,
-
-			AuthorName: scommon.AtomAuthorName,
-			AuthorURI:  conf.AbsoluteURL,
+		var image string
+		if len(entry.Photos) > 0 {
+			photo := entry.Photos[0]
+			image = fmt.Sprintf("/photographs/sequences/%s_large@2x%s",
+				photo.Slug, photo.TargetExt())
 		}
-		feed.Entries = append(feed.Entries, entry)
-	}
 
-	filePath := path.Join(conf.TargetDir, "sequences.atom")
-	f, err := os.Create(filePath)
-	if err != nil {
-		return true, xerrors.Errorf("error creating file '%s': %w", filePath, err)
+		f.Entries = append(f.Entries, &feedEntry{
+			Content:     contentBuf.String(),
+			ID:          feedEntryID(entry.PublishedAt, "sequences:"+entry.Slug),
+			Image:       image,
+			PublishedAt: entry.PublishedAt,
+			Title:       entry.Slug + " — " + entry.Title,
+			URL:         conf.AbsoluteURL + "/sequences/" + entry.Slug,
+		})
 	}
-	defer f.Close()
 
-	return true, feed.Encode(f, "  ")
+	return true, writeFeed(f)
 }
 
 func renderSequenceEntry(ctx context.Context, c *modulir.Context, entry *SequenceEntry, sequencesChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/sequences/show.tmpl.html"
 
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !sequencesChanged && !viewsChanged {
 		return false, nil
 	}
@@ -3019,6 +3799,7 @@ func renderSequenceEntry(ctx context.Context, c *modulir.Context, entry *Sequenc
 
 	locals := getLocals(map[string]any{
 		"Entry":       entry,
+		"Scheduled":   isScheduled(c, entry.PublishedAt),
 		"Title":       title,
 		"TwitterCard": card,
 	})
@@ -3035,7 +3816,7 @@ func renderSequencesIndex(ctx context.Context, c *modulir.Context, entries []*Se
 	sequenceChanged bool,
 ) (bool, error) {
 	source := scommon.ViewsDir + "/sequences/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !sequenceChanged && !viewsChanged {
 		return false, nil
 	}
@@ -3054,7 +3835,7 @@ func renderSequencesIndex(ctx context.Context, c *modulir.Context, entries []*Se
 
 func renderTwitter(ctx context.Context, c *modulir.Context, tweets []*squantified.Tweet, tweetsChanged, withReplies bool) (bool, error) {
 	source := scommon.ViewsDir + "/twitter/index.tmpl.html"
-	viewsChanged := c.ChangedAny(dependencies.getDependencies(source)...)
+	viewsChanged := dependencies.viewChanged(c, source)
 	if !tweetsChanged && !viewsChanged {
 		return false, nil
 	}
@@ -3099,9 +3880,3 @@ func renderTwitter(ctx context.Context, c *modulir.Context, tweets []*squantifie
 
 	return true, nil
 }
-
-// Gets a pointer to a tag just to work around the fact that you can take the
-// address of a constant like `tagPostgres`.
-func tagPointer(tag Tag) *Tag {
-	return &tag
-}
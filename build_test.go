@@ -3,13 +3,18 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/joeshaw/envdecode"
 	"github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scommon"
 )
 
 func init() {
@@ -30,6 +35,18 @@ func TestExtImageTarget(t *testing.T) {
 	require.Equal(t, ".webp", extImageTarget(".heic"))
 }
 
+func TestFingerprintAssets(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	assets := make(map[string]string)
+	err := fingerprintAssets(c, "stylesheets", []string{"./content/stylesheets/quotes.css"}, assets)
+	require.NoError(t, err)
+
+	fingerprintedName := assets["stylesheets/quotes.css"]
+	require.Regexp(t, `^stylesheets/quotes\.[0-9a-f]{10}\.css$`, fingerprintedName)
+	require.FileExists(t, c.TargetDir+"/assets/"+fingerprintedName)
+}
+
 func TestLexicographicBase32(t *testing.T) {
 	// Should only incorporate lower case characters.
 	require.Equal(t, lexicographicBase32, strings.ToLower(lexicographicBase32))
@@ -45,6 +62,60 @@ func TestLexicographicBase32(t *testing.T) {
 	}
 }
 
+func TestLoadNewsletters(t *testing.T) {
+	c := modulir.NewContext(&modulir.Args{Log: &modulir.Logger{Level: modulir.LevelInfo}, SourceDir: "."})
+
+	newsletters, err := loadNewsletters(c)
+	require.NoError(t, err)
+
+	var slugs []string
+	for _, newsletter := range newsletters {
+		slugs = append(slugs, newsletter.Slug)
+	}
+	require.Equal(t, []string{"nanoglyphs", "passages"}, slugs)
+}
+
+func TestNewsletterWrapperValidate(t *testing.T) {
+	newsletter := func(slug string) *Newsletter {
+		return &Newsletter{
+			ContentDir:         "content/" + slug,
+			ContentDirDrafts:   "content/" + slug + "-drafts",
+			EmailTitleFormat:   "%s — %s",
+			FeedID:             "tag:brandur.org,2013:/" + slug,
+			IndexView:          "views/" + slug + "/index.tmpl.html",
+			Kind:               slug,
+			MailAddress:        slug + "@list.brandur.org",
+			MailAddressStaging: slug + "-staging@list.brandur.org",
+			Name:               slug,
+			Slug:               slug,
+			TitleFormat:        "%s — %s",
+			View:               "views/" + slug + "/show.tmpl.html",
+		}
+	}
+
+	require.NoError(t, (&NewsletterWrapper{Newsletters: []*Newsletter{
+		newsletter("a"), newsletter("b"),
+	}}).validate())
+
+	require.EqualError(t, (&NewsletterWrapper{Newsletters: []*Newsletter{
+		newsletter("a"), newsletter("a"),
+	}}).validate(), "duplicate newsletter slug: a")
+
+	{
+		invalid := newsletter("a")
+		invalid.MailAddress = ""
+		require.ErrorContains(t, (&NewsletterWrapper{Newsletters: []*Newsletter{invalid}}).validate(),
+			"mail_address")
+	}
+}
+
+func TestMinifySection(t *testing.T) {
+	require.Equal(t, "/", minifySection("public", "public/postgres"))
+	require.Equal(t, "/", minifySection("public", "public/index.html"))
+	require.Equal(t, "twitter", minifySection("public", "public/twitter/index.html"))
+	require.Equal(t, "nanoglyphs", minifySection("public", "public/nanoglyphs/001-first"))
+}
+
 func TestPagePathKey(t *testing.T) {
 	require.Equal(t, "about", pagePathKey("./pages/about.ace"))
 	require.Equal(t, "about", pagePathKey("./pages-drafts/about.ace"))
@@ -56,6 +127,74 @@ func TestPagePathKey(t *testing.T) {
 	require.Equal(t, "really/deep/about", pagePathKey("./pages-drafts/really/deep/about.ace"))
 }
 
+func TestRenderArticleAssetChanged(t *testing.T) {
+	ctx := t.Context()
+
+	var (
+		cachePath  = filepath.Join(t.TempDir(), dependencyCacheFilename)
+		sourceDir  = t.TempDir()
+		source     = filepath.Join(sourceDir, "content", "articles", "asset-changed.md")
+		sourceTmpl = scommon.ViewsDir + "/articles/show.tmpl.html"
+		stylesheet = filepath.Join(sourceDir, "content", "stylesheets", "site.css")
+		targetDir  = t.TempDir()
+	)
+	require.NoError(t, os.MkdirAll(filepath.Dir(source), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(stylesheet), 0o755))
+	require.NoError(t, os.WriteFile(source, []byte(`+++
+hook = "Hook."
+location = "Berlin"
+published_at = 2026-01-01T00:00:00Z
+title = "Asset changed"
++++
+
+Content.
+`), 0o600))
+	require.NoError(t, os.WriteFile(stylesheet, []byte("body {}"), 0o600))
+
+	originalDependencies := dependencies
+	t.Cleanup(func() { dependencies = originalDependencies })
+
+	// Renders the article as a new process would on its first run with the
+	// cache from the last one, and returns whether its page would be rendered.
+	render := func() bool {
+		t.Helper()
+
+		dependencies = NewDependencyRegistry()
+		c := modulir.NewContext(&modulir.Args{
+			Log:       &modulir.Logger{Level: modulir.LevelInfo},
+			SourceDir: sourceDir,
+			TargetDir: targetDir,
+		})
+		c.Stats.Start = time.Now()
+		require.NoError(t, dependencies.load(c, cachePath, "v1"))
+		dependencies.setAssetSources([]string{stylesheet})
+
+		var (
+			articles        []*Article
+			articlesChanged bool
+			mu              sync.Mutex
+		)
+		executed, err := renderArticle(ctx, c, source, &articles, &articlesChanged, &mu)
+		require.NoError(t, err)
+		require.True(t, executed)
+		require.Len(t, articles, 1)
+
+		// Stands in for rendering the article's page, which records the
+		// view's dependencies.
+		dependencies.setDependencies(ctx, c, sourceTmpl, []string{sourceTmpl})
+		require.NoError(t, dependencies.save(cachePath, "v1"))
+
+		return articles[0].changed
+	}
+
+	require.True(t, render())
+	require.False(t, render())
+
+	require.NoError(t, os.WriteFile(stylesheet, []byte("body { margin: 0; }"), 0o600))
+	require.True(t, render())
+	require.False(t, render())
+}
+
 func TestSimplifyMarkdownForSummary(t *testing.T) {
 	require.Equal(t, "check that links are removed", simplifyMarkdownForSummary("check that [links](/link) are removed"))
 	require.Equal(t, "double new lines are gone", simplifyMarkdownForSummary("double new\n\nlines are gone"))
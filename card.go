@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg" // register JPEG decoder for card images
+	_ "image/png"  // register PNG decoder for card images
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	_ "golang.org/x/image/webp" // register WebP decoder for card images
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scard"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// cardMark is the site mark drawn onto every card.
+const cardMark = "brandur.org"
+
+var (
+	// scheduledCards are cards for scheduled content, keyed by name, which
+	// are held back until the content is published because they'd give away
+	// its title and hook. They're kept between build loops because content
+	// usually gets published by a rebuild in which its source didn't change.
+	scheduledCards   = make(map[string]*scheduledCard)
+	scheduledCardsMu sync.Mutex
+)
+
+// scheduledCard is a card held back until its content is published.
+type scheduledCard struct {
+	card        *scard.Card
+	imageSource string
+}
+
+// cardImageSource maps a site path to an image like
+// `/assets/images/fragments/airpods/vista.jpg` to where it can be found in the
+// source directory. Returns false for paths that aren't served out of the
+// source directory (i.e. ones that are remote).
+func cardImageSource(c *modulir.Context, sitePath string) (string, bool) {
+	// Mirrors the symlinks set up at the beginning of a build.
+	prefixes := [][2]string{
+		{"/assets/images/", "/content/images/"},
+		{"/photographs/", "/content/photographs/"},
+	}
+
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(sitePath, prefix[0]); ok {
+			return c.SourceDir + prefix[1] + rest, true
+		}
+	}
+
+	return "", false
+}
+
+// cardStale returns true if the card with the given name should be drawn,
+// which is the case if the content it's for has changed or it's never been
+// drawn before.
+func cardStale(c *modulir.Context, name string, contentChanged bool) bool {
+	if contentChanged {
+		return true
+	}
+
+	_, err := os.Stat(path.Join(c.TargetDir, cardURL(name)))
+	return err != nil
+}
+
+// cardImageChanged returns true if an image that's drawn onto a card has
+// changed since the last build. An image that doesn't exist (yet) hasn't, so
+// that content without one isn't drawn again on every build.
+func cardImageChanged(c *modulir.Context, imageSource string) bool {
+	if imageSource == "" {
+		return false
+	}
+
+	if _, err := os.Stat(imageSource); err != nil {
+		return false
+	}
+
+	return dependencies.changed(c, imageSource)
+}
+
+// cardURL is the URL of the card for a piece of content, like
+// `/cards/fragments/airpods.png` for the name `fragments/airpods`.
+func cardURL(name string) string {
+	return "/cards/" + name + ".png"
+}
+
+// loadCardImage decodes an image to be drawn onto a card. Returns nil without
+// an error if there's no image at the path, which is the case for photos that
+// haven't been fetched yet.
+func loadCardImage(source string) (image.Image, error) {
+	f, err := os.Open(source)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil //nolint:nilnil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error opening card image '%s': %w", source, err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, xerrors.Errorf("error decoding card image '%s': %w", source, err)
+	}
+
+	return img, nil
+}
+
+// publishedScheduledCards removes cards from scheduledCards whose content has
+// been published since they were held back and returns them, keyed by name.
+func publishedScheduledCards(c *modulir.Context) map[string]*scheduledCard {
+	scheduledCardsMu.Lock()
+	defer scheduledCardsMu.Unlock()
+
+	published := make(map[string]*scheduledCard)
+	for name, scheduled := range scheduledCards {
+		if !isScheduled(c, scheduled.card.Date) {
+			published[name] = scheduled
+			delete(scheduledCards, name)
+		}
+	}
+
+	return published
+}
+
+// renderContentCard draws the card for a piece of content published at
+// card.Date if it's stale. The card for content that's scheduled is held back
+// in scheduledCards instead, unless drafts are enabled, and any copy of it
+// left by an earlier build is removed so that it isn't deployed.
+func renderContentCard(c *modulir.Context, name string, card *scard.Card, imageSource string,
+	stale bool,
+) error {
+	scheduled := isScheduled(c, card.Date) && !conf.Drafts
+
+	scheduledCardsMu.Lock()
+	delete(scheduledCards, name)
+	if scheduled {
+		scheduledCards[name] = &scheduledCard{card: card, imageSource: imageSource}
+	}
+	scheduledCardsMu.Unlock()
+
+	if scheduled {
+		target := path.Join(c.TargetDir, cardURL(name))
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return xerrors.Errorf("error removing card '%s': %w", target, err)
+		}
+		return nil
+	}
+
+	if !stale {
+		return nil
+	}
+
+	_, err := renderCard(c, name, card, imageSource)
+	return err
+}
+
+// renderCard draws a card, optionally with an image found at imageSource, and
+// writes it to the target directory at the location given by cardURL(name).
+// Returns the card's URL.
+func renderCard(c *modulir.Context, name string, card *scard.Card, imageSource string) (string, error) {
+	if imageSource != "" {
+		img, err := loadCardImage(imageSource)
+		if err != nil {
+			return "", err
+		}
+		card.Image = img
+	}
+
+	if card.Mark == "" {
+		card.Mark = cardMark
+	}
+
+	var buf bytes.Buffer
+	if err := scard.Encode(&buf, card); err != nil {
+		return "", xerrors.Errorf("error rendering card '%s': %w", name, err)
+	}
+
+	url := cardURL(name)
+	target := path.Join(c.TargetDir, url)
+
+	if err := os.MkdirAll(path.Dir(target), 0o755); err != nil {
+		return "", xerrors.Errorf("error creating directory '%s': %w", path.Dir(target), err)
+	}
+
+	// Readable by everyone because a server may run as another user.
+	if err := os.WriteFile(target, buf.Bytes(), 0o644); err != nil { //nolint:gosec
+		return "", xerrors.Errorf("error writing card '%s': %w", target, err)
+	}
+
+	return url, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scard"
+)
+
+func TestCardImageSource(t *testing.T) {
+	c := &modulir.Context{SourceDir: "."}
+
+	{
+		source, ok := cardImageSource(c, "/assets/images/fragments/airpods/vista.jpg")
+		assert.True(t, ok)
+		assert.Equal(t, "./content/images/fragments/airpods/vista.jpg", source)
+	}
+
+	{
+		source, ok := cardImageSource(c, "/photographs/fragments/plaque/plaque.jpg")
+		assert.True(t, ok)
+		assert.Equal(t, "./content/photographs/fragments/plaque/plaque.jpg", source)
+	}
+
+	{
+		_, ok := cardImageSource(c, "https://example.com/image.jpg")
+		assert.False(t, ok)
+	}
+}
+
+func TestCardImageChanged(t *testing.T) {
+	originalDependencies := dependencies
+	t.Cleanup(func() { dependencies = originalDependencies })
+
+	var (
+		cachePath = path.Join(t.TempDir(), dependencyCacheFilename)
+		dir       = t.TempDir()
+	)
+
+	// Checks for changes as a new process would on its first run with the
+	// cache from the last one.
+	imageChanged := func(imageSource string) bool {
+		t.Helper()
+
+		dependencies = NewDependencyRegistry()
+		c := modulir.NewContext(&modulir.Args{Log: &modulir.Logger{Level: modulir.LevelInfo}})
+		assert.NoError(t, dependencies.load(c, cachePath, "v1"))
+
+		changed := cardImageChanged(c, imageSource)
+		assert.NoError(t, dependencies.save(cachePath, "v1"))
+		return changed
+	}
+
+	assert.False(t, imageChanged(""))
+
+	// An image that doesn't exist yet hasn't changed.
+	source := path.Join(dir, "hook.png")
+	assert.False(t, imageChanged(source))
+
+	writeTestPNG(t, dir, "hook.png")
+	assert.True(t, imageChanged(source))
+	assert.False(t, imageChanged(source))
+
+	assert.NoError(t, os.WriteFile(source, []byte("changed"), 0o600))
+	assert.True(t, imageChanged(source))
+	assert.False(t, imageChanged(source))
+}
+
+func TestCardStale(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	assert.True(t, cardStale(c, "fragments/a", false))
+	assert.True(t, cardStale(c, "fragments/a", true))
+
+	assert.NoError(t, os.MkdirAll(path.Join(c.TargetDir, "cards/fragments"), 0o755))
+	assert.NoError(t, os.WriteFile(path.Join(c.TargetDir, "cards/fragments/a.png"), nil, 0o600))
+
+	assert.False(t, cardStale(c, "fragments/a", false))
+	assert.True(t, cardStale(c, "fragments/a", true))
+}
+
+func TestCardURL(t *testing.T) {
+	assert.Equal(t, "/cards/fragments/airpods.png", cardURL("fragments/airpods"))
+}
+
+func TestLoadCardImage(t *testing.T) {
+	dir := t.TempDir()
+
+	{
+		img, err := loadCardImage(path.Join(dir, "missing.png"))
+		assert.NoError(t, err)
+		assert.Nil(t, img)
+	}
+
+	{
+		source := writeTestPNG(t, dir, "image.png")
+		img, err := loadCardImage(source)
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 10, 5), img.Bounds())
+	}
+
+	{
+		source := path.Join(dir, "bad.png")
+		assert.NoError(t, os.WriteFile(source, []byte("not an image"), 0o600))
+		_, err := loadCardImage(source)
+		assert.ErrorContains(t, err, "error decoding card image")
+	}
+}
+
+func TestRenderCard(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	url, err := renderCard(c, "fragments/a", &scard.Card{
+		Date:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Hook:  "A hook.",
+		Title: "A title",
+	}, writeTestPNG(t, t.TempDir(), "image.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/cards/fragments/a.png", url)
+
+	f, err := os.Open(path.Join(c.TargetDir, url))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, scard.Width, scard.Height), img.Bounds())
+}
+
+func TestRenderContentCard(t *testing.T) {
+	originalScheduledCards := scheduledCards
+	scheduledCards = make(map[string]*scheduledCard)
+	t.Cleanup(func() { scheduledCards = originalScheduledCards })
+
+	c := &modulir.Context{Stats: &modulir.Stats{Start: time.Now()}, TargetDir: t.TempDir()}
+	target := path.Join(c.TargetDir, cardURL("fragments/a"))
+
+	// Published content gets its card drawn when it's stale.
+	{
+		published := &scard.Card{Date: c.Stats.Start.Add(-time.Hour), Hook: "A hook.", Title: "A title"}
+
+		assert.NoError(t, renderContentCard(c, "fragments/a", published, "", false))
+		assert.NoFileExists(t, target)
+
+		assert.NoError(t, renderContentCard(c, "fragments/a", published, "", true))
+		assert.FileExists(t, target)
+		assert.Empty(t, publishedScheduledCards(c))
+	}
+
+	// Scheduled content doesn't, and a card that was drawn before is
+	// removed.
+	scheduled := &scard.Card{Date: c.Stats.Start.Add(time.Hour), Hook: "A hook.", Title: "A title"}
+	assert.NoError(t, renderContentCard(c, "fragments/a", scheduled, "", true))
+	assert.NoFileExists(t, target)
+	assert.Empty(t, publishedScheduledCards(c))
+
+	// The card is given back once its content is published.
+	c.Stats.Start = c.Stats.Start.Add(2 * time.Hour)
+	published := publishedScheduledCards(c)
+	assert.Len(t, published, 1)
+	assert.Equal(t, scheduled, published["fragments/a"].card)
+	assert.Empty(t, publishedScheduledCards(c))
+}
+
+func writeTestPNG(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 5))
+	img.SetRGBA(0, 0, color.RGBA{0xff, 0x00, 0x00, 0xff})
+
+	source := path.Join(dir, name)
+	f, err := os.Create(source)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.NoError(t, png.Encode(f, img))
+	return source
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mmarkdown"
+	"github.com/brandur/sorg/modules/scommon"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ChangelogEntry is a single revision in the changelog of an article or
+// fragment, from a `[[changelog]]` table in its frontmatter.
+type ChangelogEntry struct {
+	// Description is a Markdown description of what changed.
+	Description string `toml:"description" validate:"required"`
+
+	// DescriptionHTML is the description rendered to HTML.
+	DescriptionHTML template.HTML `toml:"-" validate:"-"`
+
+	// UpdatedAt is when the revision was made.
+	UpdatedAt time.Time `toml:"updated_at" validate:"required"`
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// checkUpdates returns an error if content published at the given time claims
+// to have been updated before it was published.
+func checkUpdates(publishedAt, updatedAt time.Time, changelog []*ChangelogEntry) error {
+	if !updatedAt.IsZero() && updatedAt.Before(publishedAt) {
+		return xerrors.Errorf("updated_at %v is before published_at %v", updatedAt, publishedAt)
+	}
+
+	for _, entry := range changelog {
+		if entry.UpdatedAt.Before(publishedAt) {
+			return xerrors.Errorf("changelog entry updated_at %v is before published_at %v",
+				entry.UpdatedAt, publishedAt)
+		}
+	}
+
+	return nil
+}
+
+// lastUpdatedAt gets when content was last updated, which is the latest of its
+// `updated_at` and the times in its changelog, or a zero time if it's never
+// been updated.
+func lastUpdatedAt(updatedAt time.Time, changelog []*ChangelogEntry) time.Time {
+	for _, entry := range changelog {
+		if entry.UpdatedAt.After(updatedAt) {
+			updatedAt = entry.UpdatedAt
+		}
+	}
+
+	return updatedAt
+}
+
+// renderChangelog renders the descriptions of a changelog's entries and sorts
+// it newest first, which is the order it's shown in.
+func renderChangelog(c *modulir.Context, changelog []*ChangelogEntry) {
+	for _, entry := range changelog {
+		entry.DescriptionHTML = template.HTML(string(mmarkdown.Render(c, []byte(entry.Description))))
+	}
+
+	slices.SortStableFunc(changelog, func(a, b *ChangelogEntry) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+}
+
+// renderUpdatedFeed renders a site-wide feed of revisions to articles and
+// fragments, newest first.
+func renderUpdatedFeed(_ *modulir.Context, articles []*Article, fragments []*Fragment,
+	contentChanged bool,
+) (bool, error) {
+	if !contentChanged {
+		return false, nil
+	}
+
+	f := &feed{
+		ID:      "tag:" + scommon.AtomTag + ",2026:/updated",
+		Name:    "updated",
+		Title:   "Recently Updated" + scommon.TitleSuffix,
+		Entries: updatedFeedEntries(articles, fragments),
+	}
+
+	return true, writeFeed(f)
+}
+
+// updatedFeedEntries produces an entry for every revision to the given articles
+// and fragments, newest first.
+//
+// Each entry in a changelog is its own feed entry with its description as a
+// summary. An `updated_at` that isn't also in the changelog gets an entry
+// too. Entries are identified by the time of the revision so that every
+// revision shows up in feed readers as something new.
+func updatedFeedEntries(articles []*Article, fragments []*Fragment) []*feedEntry {
+	var entries []*feedEntry
+
+	addRevisions := func(entry *feedEntry, name string, updatedAt time.Time, changelog []*ChangelogEntry) {
+		addRevision := func(revisedAt time.Time, summary string) {
+			revision := *entry
+			revision.ID = feedEntryID(revisedAt, "updated:"+name+":"+revisedAt.UTC().Format(time.RFC3339))
+			revision.Summary = summary
+			revision.UpdatedAt = revisedAt
+			entries = append(entries, &revision)
+		}
+
+		for _, changelogEntry := range changelog {
+			addRevision(changelogEntry.UpdatedAt, string(changelogEntry.DescriptionHTML))
+		}
+
+		if !updatedAt.IsZero() && !slices.ContainsFunc(changelog, func(changelogEntry *ChangelogEntry) bool {
+			return changelogEntry.UpdatedAt.Equal(updatedAt)
+		}) {
+			addRevision(updatedAt, entry.Summary)
+		}
+	}
+
+	for _, article := range articles {
+		addRevisions(articleFeedEntry(article), article.Slug, article.UpdatedAt, article.Changelog)
+	}
+	for _, fragment := range fragments {
+		addRevisions(fragmentFeedEntry(fragment), "fragments/"+fragment.Slug, fragment.UpdatedAt,
+			fragment.Changelog)
+	}
+
+	// Ties are broken by ID so that the feed's archive pages are stable.
+	slices.SortFunc(entries, func(a, b *feedEntry) int {
+		if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+
+	return entries
+}
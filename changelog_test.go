@@ -0,0 +1,93 @@
+package main
+
+import (
+	"html/template"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestCheckUpdates(t *testing.T) {
+	publishedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, checkUpdates(publishedAt, time.Time{}, nil))
+	assert.NoError(t, checkUpdates(publishedAt, publishedAt.AddDate(0, 1, 0),
+		[]*ChangelogEntry{{Description: "Fixed a typo.", UpdatedAt: publishedAt}}))
+
+	assert.EqualError(t, checkUpdates(publishedAt, publishedAt.AddDate(0, 0, -1), nil),
+		"updated_at 2025-12-31 00:00:00 +0000 UTC is before published_at 2026-01-01 00:00:00 +0000 UTC")
+	assert.EqualError(t, checkUpdates(publishedAt, time.Time{},
+		[]*ChangelogEntry{{Description: "Fixed a typo.", UpdatedAt: publishedAt.AddDate(0, 0, -1)}}),
+		"changelog entry updated_at 2025-12-31 00:00:00 +0000 UTC is before published_at 2026-01-01 00:00:00 +0000 UTC")
+}
+
+func TestLastUpdatedAt(t *testing.T) {
+	updatedAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, lastUpdatedAt(time.Time{}, nil).IsZero())
+	assert.Equal(t, updatedAt, lastUpdatedAt(updatedAt, nil))
+	assert.Equal(t, updatedAt, lastUpdatedAt(time.Time{}, []*ChangelogEntry{
+		{UpdatedAt: updatedAt.AddDate(0, 0, -1)},
+		{UpdatedAt: updatedAt},
+	}))
+	assert.Equal(t, updatedAt, lastUpdatedAt(updatedAt, []*ChangelogEntry{
+		{UpdatedAt: updatedAt.AddDate(0, 0, -1)},
+	}))
+}
+
+func TestUpdatedFeedEntries(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.AbsoluteURL = "https://example.com"
+
+	publishedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	articles := []*Article{
+		{
+			Changelog: []*ChangelogEntry{
+				{DescriptionHTML: template.HTML("<p>Added benchmarks.</p>"), UpdatedAt: publishedAt.AddDate(0, 2, 0)},
+				{DescriptionHTML: template.HTML("<p>Fixed a typo.</p>"), UpdatedAt: publishedAt.AddDate(0, 1, 0)},
+			},
+			Hook:        template.HTML("Postgres job queues."),
+			PublishedAt: publishedAt,
+			Slug:        "postgres-queues",
+			Title:       "Postgres Job Queues",
+			UpdatedAt:   publishedAt.AddDate(0, 2, 0),
+		},
+		{
+			PublishedAt: publishedAt,
+			Slug:        "never-updated",
+			Title:       "Never Updated",
+		},
+	}
+	fragments := []*Fragment{
+		{
+			Hook:        template.HTML("Wireless earphones."),
+			PublishedAt: publishedAt,
+			Slug:        "airpods",
+			Title:       "AirPods",
+			UpdatedAt:   publishedAt.AddDate(0, 1, 15),
+		},
+	}
+
+	entries := updatedFeedEntries(articles, fragments)
+	assert.Len(t, entries, 3)
+
+	// Newest revision first, and an `updated_at` that's also in the changelog
+	// only appears once.
+	assert.Equal(t, "tag:brandur.org,2026-03-01:updated:postgres-queues:2026-03-01T00:00:00Z", entries[0].ID)
+	assert.Equal(t, "<p>Added benchmarks.</p>", entries[0].Summary)
+	assert.Equal(t, publishedAt.AddDate(0, 2, 0), entries[0].UpdatedAt)
+	assert.Equal(t, "https://example.com/postgres-queues", entries[0].URL)
+
+	// An `updated_at` without a changelog entry uses the hook as a summary.
+	assert.Equal(t, "Wireless earphones.", entries[1].Summary)
+	assert.Equal(t, "https://example.com/fragments/airpods", entries[1].URL)
+
+	assert.Equal(t, "<p>Fixed a typo.</p>", entries[2].Summary)
+	assert.Equal(t, publishedAt, entries[2].PublishedAt)
+}
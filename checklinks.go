@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/slinkcheck"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// checkLinks checks every link in the site built to TARGET_DIR, printing a
+// report of broken ones grouped by the content source that they came from.
+// Exits with a non-zero status if any were found.
+//
+// Internal links must resolve to a built file, and their fragments (if any)
+// to an ID in it. External links are only checked if external is true.
+func checkLinks(c *modulir.Context, external bool, concurrency int) {
+	newsletters, err := loadNewsletters(c)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	var checker *slinkcheck.Checker
+	if external {
+		cache, err := slinkcheck.LoadCache(linkCacheFilename)
+		if err != nil {
+			scommon.ExitWithError(err)
+		}
+
+		checker = &slinkcheck.Checker{
+			Cache:       cache,
+			CacheTTL:    linkCacheTTL,
+			Concurrency: concurrency,
+			HTTPClient:  &http.Client{Timeout: 15 * time.Second},
+			UserAgent:   "sorg link checker (+" + conf.AbsoluteURL + ")",
+		}
+	}
+
+	if external {
+		c.Log.Infof("Checking external links along with internal ones (this may take a while)")
+	}
+
+	brokenLinks, err := findBrokenLinks(context.Background(), c, newsletters, checker)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	if checker != nil {
+		if err := checker.Cache.Save(linkCacheFilename); err != nil {
+			scommon.ExitWithError(err)
+		}
+	}
+
+	var lastSource string
+	for _, link := range brokenLinks {
+		if link.Source != lastSource {
+			if lastSource != "" {
+				fmt.Println()
+			}
+			fmt.Println(link.Source)
+			lastSource = link.Source
+		}
+
+		fmt.Printf("    %s: %s (%s)\n", link.Page, link.Href, link.Problem)
+	}
+
+	if len(brokenLinks) > 0 {
+		fmt.Println()
+		scommon.ExitWithError(xerrors.Errorf("found %d broken link(s)", len(brokenLinks)))
+	}
+
+	c.Log.Infof("No broken links")
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// brokenLink is a link in the built site that doesn't resolve.
+type brokenLink struct {
+	// Href is the link as it appears in the page.
+	Href string
+
+	// Page is the path on the site of the page containing the link.
+	Page string
+
+	// Problem describes why the link is broken.
+	Problem string
+
+	// Source is the content source that the page was built from, or the
+	// built file itself for pages like indexes that aren't built from any
+	// one source.
+	Source string
+}
+
+// externalLink is an occurrence of an external URL, which are all checked
+// together once every page has been read.
+type externalLink struct {
+	Href   string
+	Page   string
+	Source string
+	URL    string
+}
+
+// linkChecker finds broken links in a built site.
+type linkChecker struct {
+	c           *modulir.Context
+	docs        map[string]*slinkcheck.Document // keyed by file in the target directory
+	newsletters []*Newsletter
+	siteHost    string
+}
+
+// check checks every link in a page, returning those that are internal and
+// broken, and those that are external to be checked later.
+func (lc *linkChecker) check(file string) ([]*brokenLink, []*externalLink, error) {
+	doc, err := lc.document(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := sitePath(lc.c.TargetDir, file)
+	source := lc.source(page, file)
+	base := &url.URL{Path: page}
+
+	var (
+		brokenLinks   []*brokenLink
+		externalLinks []*externalLink
+	)
+
+	for _, href := range doc.Links {
+		broken := func(problem string) {
+			brokenLinks = append(brokenLinks, &brokenLink{Href: href, Page: page, Problem: problem, Source: source})
+		}
+
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			broken("malformed URL")
+			continue
+		}
+
+		switch {
+		// A link to our own site by its absolute URL is checked like any
+		// other internal one.
+		case (u.Scheme == "http" || u.Scheme == "https") && u.Host == lc.siteHost:
+			u = &url.URL{Path: u.Path, RawQuery: u.RawQuery, Fragment: u.Fragment}
+
+		case u.Scheme == "http" || u.Scheme == "https":
+			externalLinks = append(externalLinks, &externalLink{Href: href, Page: page, Source: source, URL: u.String()})
+			continue
+
+		// Protocol-relative, like `//fonts.googleapis.com`.
+		case u.Scheme == "" && u.Host != "":
+			u.Scheme = "https"
+			externalLinks = append(externalLinks, &externalLink{Href: href, Page: page, Source: source, URL: u.String()})
+			continue
+
+		// Other schemes like `mailto:` can't be checked.
+		case u.Scheme != "":
+			continue
+		}
+
+		target := file
+		if u.Path != "" {
+			var ok bool
+			target, ok = resolveSitePath(lc.c.TargetDir, base.ResolveReference(u).Path)
+			if !ok {
+				broken("no such page")
+				continue
+			}
+		}
+
+		if u.Fragment == "" || !isHTMLFile(target) {
+			continue
+		}
+
+		targetDoc, err := lc.document(target)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !targetDoc.HasID(u.Fragment) {
+			broken("no such anchor")
+		}
+	}
+
+	return brokenLinks, externalLinks, nil
+}
+
+// document parses an HTML file in the target directory, caching the result
+// because popular pages are the target of many links.
+func (lc *linkChecker) document(file string) (*slinkcheck.Document, error) {
+	if doc, ok := lc.docs[file]; ok {
+		return doc, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, xerrors.Errorf("error opening file '%s': %w", file, err)
+	}
+	defer f.Close()
+
+	doc, err := slinkcheck.Parse(f)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing file '%s': %w", file, err)
+	}
+
+	lc.docs[file] = doc
+	return doc, nil
+}
+
+// source finds the content source that a page was built from, falling back
+// to the page's built file if there isn't one.
+func (lc *linkChecker) source(page, file string) string {
+	var candidates []string
+
+	slug := path.Base(page)
+	switch dir := path.Dir(page); dir {
+	case "/":
+		candidates = []string{
+			"content/articles/" + slug + ".md",
+			"content/drafts/" + slug + ".md",
+		}
+
+	case "/atoms":
+		if slug != "archive" {
+			candidates = []string{"content/atoms/_meta.toml"}
+		}
+
+	case "/fragments":
+		candidates = []string{
+			"content/fragments/" + slug + ".md",
+			"content/fragments-drafts/" + slug + ".md",
+		}
+
+	case "/sequences":
+		candidates = []string{"content/sequences/_meta.toml"}
+
+	default:
+		for _, newsletter := range lc.newsletters {
+			if dir == "/"+newsletter.Slug {
+				candidates = []string{
+					newsletter.ContentDir + "/" + slug + ".md",
+					newsletter.ContentDirDrafts + "/" + slug + ".md",
+				}
+			}
+		}
+	}
+
+	// Pages can be nested, and have a few different extensions.
+	if page != "/" {
+		for _, dir := range []string{"pages", "pages-drafts"} {
+			matches, _ := filepath.Glob(path.Join(lc.c.SourceDir, dir, page+".*"))
+			for _, match := range matches {
+				rel, _ := filepath.Rel(lc.c.SourceDir, match)
+				candidates = append(candidates, rel)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(path.Join(lc.c.SourceDir, candidate)); err == nil {
+			return candidate
+		}
+	}
+
+	return filepath.Clean(file)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Where external links that checked out are cached between runs, and for how
+// long.
+const (
+	linkCacheFilename = scommon.TempDir + "/link-cache.json"
+	linkCacheTTL      = 7 * 24 * time.Hour
+)
+
+// findBrokenLinks checks every link in every HTML page in the target
+// directory. External links are only checked if checker is non-nil. Broken
+// links are returned sorted by source, page, and link.
+func findBrokenLinks(ctx context.Context, c *modulir.Context, newsletters []*Newsletter,
+	checker *slinkcheck.Checker,
+) ([]*brokenLink, error) {
+	siteURL, err := url.Parse(conf.AbsoluteURL)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing absolute URL: %w", err)
+	}
+
+	lc := &linkChecker{
+		c:           c,
+		docs:        make(map[string]*slinkcheck.Document),
+		newsletters: newsletters,
+		siteHost:    siteURL.Host,
+	}
+
+	var (
+		brokenLinks   []*brokenLink
+		externalLinks []*externalLink
+	)
+
+	// Symlinks to directories of assets like images aren't followed because
+	// they don't contain any pages.
+	err = filepath.WalkDir(c.TargetDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || !isHTMLFile(file) {
+			return nil
+		}
+
+		pageBrokenLinks, pageExternalLinks, err := lc.check(file)
+		if err != nil {
+			return err
+		}
+
+		brokenLinks = append(brokenLinks, pageBrokenLinks...)
+		externalLinks = append(externalLinks, pageExternalLinks...)
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("error walking target directory: %w", err)
+	}
+
+	if checker != nil {
+		urls := make([]string, len(externalLinks))
+		for i, link := range externalLinks {
+			urls[i] = link.URL
+		}
+
+		results := checker.Check(ctx, urls)
+
+		for _, link := range externalLinks {
+			result := results[link.URL]
+			if !result.Broken() {
+				continue
+			}
+
+			problem := "status " + strconv.Itoa(result.StatusCode)
+			if result.Err != nil {
+				problem = result.Err.Error()
+			}
+
+			brokenLinks = append(brokenLinks, &brokenLink{
+				Href:    link.Href,
+				Page:    link.Page,
+				Problem: problem,
+				Source:  link.Source,
+			})
+		}
+	}
+
+	slices.SortStableFunc(brokenLinks, func(a, b *brokenLink) int {
+		return strings.Compare(a.Source+"\x00"+a.Page+"\x00"+a.Href, b.Source+"\x00"+b.Page+"\x00"+b.Href)
+	})
+
+	return brokenLinks, nil
+}
+
+// isHTMLFile returns true if a file in the target directory is an HTML page.
+// Many pages are written without an extension, so those are sniffed.
+func isHTMLFile(file string) bool {
+	switch path.Ext(file) {
+	case ".html":
+		return true
+	case "":
+	default:
+		return false
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	return strings.HasPrefix(http.DetectContentType(buf[:n]), "text/html")
+}
+
+// resolveSitePath finds the file in the target directory that's served at a
+// path on the site, the same way as the local server and our hosting do:
+// pages may omit a `.html` extension, and directories are served from their
+// `index.html`.
+func resolveSitePath(targetDir, sitePath string) (string, bool) {
+	file := path.Join(targetDir, path.Clean("/"+sitePath))
+
+	for _, candidate := range []string{file, file + ".html", file + "/index.html"} {
+		// Stat follows symlinks, so assets behind symlinked directories are
+		// found too.
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// sitePath is the path on the site that a file in the target directory is
+// served at, like `/articles` for `articles/index.html`.
+func sitePath(targetDir, file string) string {
+	rel, err := filepath.Rel(targetDir, file)
+	if err != nil {
+		rel = file
+	}
+
+	rel = strings.TrimSuffix(filepath.ToSlash(rel), ".html")
+	if path.Base(rel) == "index" {
+		rel = path.Dir(rel)
+	}
+
+	return path.Clean("/" + rel)
+}
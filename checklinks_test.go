@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/slinkcheck"
+)
+
+func TestFindBrokenLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := &modulir.Context{SourceDir: t.TempDir(), TargetDir: t.TempDir()}
+
+	writeTestFile(t, c.SourceDir, "content/articles/postgres.md", "")
+	writeTestFile(t, c.SourceDir, "content/nanoglyphs/001-first.md", "")
+
+	writeTestFile(t, c.TargetDir, "postgres", `<!DOCTYPE html>
+<h2 id="queues">Queues</h2>
+<a href="/fragments/queues">ok</a>
+<a href="/fragments/queues#locks">ok</a>
+<a href="/fragments">ok</a>
+<a href="/assets/app.css">ok</a>
+<a href="#queues">ok</a>
+<a href="#top">ok</a>
+<a href="`+conf.AbsoluteURL+`/fragments/queues">ok</a>
+<a href="mailto:brandur@example.com">ok</a>
+<a href="`+server.URL+`/ok">ok</a>
+<a href="/missing">broken</a>
+<a href="#missing">broken</a>
+<a href="/fragments/queues#missing">broken</a>
+<a href="`+conf.AbsoluteURL+`/missing">broken</a>
+<a href="`+server.URL+`/missing">broken</a>
+`)
+	writeTestFile(t, c.TargetDir, "fragments/index.html", `<!DOCTYPE html>
+<a href="fragments/queues">ok</a>
+<a href="/postgres#missing">broken</a>
+`)
+	writeTestFile(t, c.TargetDir, "fragments/queues", `<!DOCTYPE html>
+<h2 id="locks">Locks</h2>
+`)
+	writeTestFile(t, c.TargetDir, "nanoglyphs/001-first", `<!DOCTYPE html>
+<a href="/postgres#nope">broken</a>
+`)
+	writeTestFile(t, c.TargetDir, "assets/app.css", `body { color: black; }`)
+
+	newsletters := []*Newsletter{
+		{ContentDir: "content/nanoglyphs", ContentDirDrafts: "content/nanoglyphs-drafts", Slug: "nanoglyphs"},
+	}
+
+	{
+		brokenLinks, err := findBrokenLinks(context.Background(), c, newsletters, nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []*brokenLink{
+			{Href: "/postgres#missing", Page: "/fragments", Problem: "no such anchor", Source: path.Join(c.TargetDir, "fragments/index.html")},
+			{Href: "#missing", Page: "/postgres", Problem: "no such anchor", Source: "content/articles/postgres.md"},
+			{Href: "/fragments/queues#missing", Page: "/postgres", Problem: "no such anchor", Source: "content/articles/postgres.md"},
+			{Href: "/missing", Page: "/postgres", Problem: "no such page", Source: "content/articles/postgres.md"},
+			{Href: conf.AbsoluteURL + "/missing", Page: "/postgres", Problem: "no such page", Source: "content/articles/postgres.md"},
+			{Href: "/postgres#nope", Page: "/nanoglyphs/001-first", Problem: "no such anchor", Source: "content/nanoglyphs/001-first.md"},
+		}, brokenLinks)
+	}
+
+	{
+		brokenLinks, err := findBrokenLinks(context.Background(), c, newsletters, &slinkcheck.Checker{})
+		assert.NoError(t, err)
+
+		var externalBrokenLinks []*brokenLink
+		for _, link := range brokenLinks {
+			if link.Href == server.URL+"/missing" {
+				externalBrokenLinks = append(externalBrokenLinks, link)
+			}
+		}
+		assert.Equal(t, []*brokenLink{
+			{Href: server.URL + "/missing", Page: "/postgres", Problem: "status 404", Source: "content/articles/postgres.md"},
+		}, externalBrokenLinks)
+		assert.Len(t, brokenLinks, 7)
+	}
+}
+
+func TestIsHTMLFile(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.True(t, isHTMLFile(writeTestFile(t, dir, "index.html", "")))
+	assert.True(t, isHTMLFile(writeTestFile(t, dir, "postgres", "<!DOCTYPE html><p>Hello.</p>")))
+	assert.False(t, isHTMLFile(writeTestFile(t, dir, "robots", "User-agent: *")))
+	assert.False(t, isHTMLFile(writeTestFile(t, dir, "app.css", "<!DOCTYPE html>")))
+	assert.False(t, isHTMLFile(path.Join(dir, "missing")))
+}
+
+func TestResolveSitePath(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestFile(t, dir, "postgres", "")
+	writeTestFile(t, dir, "about.html", "")
+	writeTestFile(t, dir, "articles/index.html", "")
+
+	for sitePath, expected := range map[string]string{
+		"/postgres":       "postgres",
+		"/about":          "about.html",
+		"/articles":       "articles/index.html",
+		"/articles/":      "articles/index.html",
+		"/../../postgres": "postgres",
+	} {
+		file, ok := resolveSitePath(dir, sitePath)
+		assert.True(t, ok, sitePath)
+		assert.Equal(t, path.Join(dir, expected), file, sitePath)
+	}
+
+	_, ok := resolveSitePath(dir, "/missing")
+	assert.False(t, ok)
+}
+
+func TestSitePath(t *testing.T) {
+	assert.Equal(t, "/", sitePath("public", "public/index.html"))
+	assert.Equal(t, "/articles", sitePath("public", "public/articles/index.html"))
+	assert.Equal(t, "/postgres", sitePath("public", "public/postgres"))
+	assert.Equal(t, "/about", sitePath("public", "public/about.html"))
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	file := path.Join(dir, name)
+	assert.NoError(t, os.MkdirAll(path.Dir(file), 0o755))
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scompress"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Extensions of built files that are worth compressing. Images and videos
+// are already compressed. Files without an extension are compressed if
+// they're HTML pages.
+var compressibleExts = map[string]struct{}{
+	".atom": {},
+	".css":  {},
+	".html": {},
+	".js":   {},
+	".json": {},
+	".svg":  {},
+	".txt":  {},
+	".xml":  {},
+}
+
+// compressTargetFiles enqueues a job to write compressed siblings for every
+// file in the target directory that's worth compressing. Files whose content
+// hasn't changed since they were last compressed are skipped by the job.
+//
+// Symlinks to directories of assets like images aren't followed because
+// those assets are already compressed or are fingerprinted elsewhere.
+func compressTargetFiles(c *modulir.Context, manifest *scompress.Manifest) error {
+	err := filepath.WalkDir(c.TargetDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || !isCompressible(file) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return xerrors.Errorf("error getting file info: %w", err)
+		}
+
+		if info.Size() < scompress.MinSize {
+			return nil
+		}
+
+		name, err := filepath.Rel(c.TargetDir, file)
+		if err != nil {
+			name = file
+		}
+
+		c.AddJob("compress: "+name, func() (bool, error) {
+			// Sniffed in the job rather than up front because it requires
+			// reading the file.
+			if path.Ext(file) == "" && !isHTMLFile(file) {
+				return false, nil
+			}
+
+			return manifest.Compress(file)
+		})
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("error walking target directory: %w", err)
+	}
+
+	return nil
+}
+
+// isCompressible returns true if a file in the target directory might be
+// worth compressing based on its name. Extensionless files may still need to
+// be sniffed to find out whether they're HTML.
+func isCompressible(file string) bool {
+	base := path.Base(file)
+
+	// Hidden files like the dependency cache and temporary files aren't
+	// served.
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+
+	ext := path.Ext(base)
+	if ext == "" {
+		return true
+	}
+
+	_, ok := compressibleExts[ext]
+	return ok
+}
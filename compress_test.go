@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scompress"
+)
+
+func TestCompressTargetFiles(t *testing.T) {
+	log := &modulir.Logger{Level: modulir.LevelInfo}
+	c := modulir.NewContext(&modulir.Args{
+		Concurrency: 2,
+		Log:         log,
+		Pool:        modulir.NewPool(log, 2),
+		TargetDir:   t.TempDir(),
+	})
+
+	large := "<!DOCTYPE html>" + strings.Repeat("<p>Hello, world.</p>\n", 100)
+
+	writeTestFile(t, c.TargetDir, "articles/index.html", large)
+	writeTestFile(t, c.TargetDir, "postgres", large)
+	writeTestFile(t, c.TargetDir, "about.html", "<!DOCTYPE html><p>Too small.</p>")
+	writeTestFile(t, c.TargetDir, "board", strings.Repeat("not html ", 200))
+	writeTestFile(t, c.TargetDir, "photo.jpg", strings.Repeat("x", 2000))
+
+	manifest, err := scompress.LoadManifest(path.Join(c.TargetDir, compressManifestFilename))
+	assert.NoError(t, err)
+
+	c.StartRound()
+	assert.NoError(t, compressTargetFiles(c, manifest))
+	assert.Empty(t, c.Wait())
+
+	assert.FileExists(t, path.Join(c.TargetDir, "articles/index.html.br"))
+	assert.FileExists(t, path.Join(c.TargetDir, "articles/index.html.gz"))
+	assert.FileExists(t, path.Join(c.TargetDir, "postgres.br"))
+	assert.FileExists(t, path.Join(c.TargetDir, "postgres.gz"))
+
+	assert.NoFileExists(t, path.Join(c.TargetDir, "about.html.gz"))
+	assert.NoFileExists(t, path.Join(c.TargetDir, "board.gz"))
+	assert.NoFileExists(t, path.Join(c.TargetDir, "photo.jpg.gz"))
+}
+
+func TestIsCompressible(t *testing.T) {
+	assert.True(t, isCompressible("public/articles/index.html"))
+	assert.True(t, isCompressible("public/articles.atom"))
+	assert.True(t, isCompressible("public/assets/stylesheets/main.0123abcdef.css"))
+	assert.True(t, isCompressible("public/postgres"))
+
+	assert.False(t, isCompressible("public/.dependencies.json"))
+	assert.False(t, isCompressible("public/postgres.gz"))
+	assert.False(t, isCompressible("public/postgres.br"))
+	assert.False(t, isCompressible("public/photographs/lake.jpg"))
+}
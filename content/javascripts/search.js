@@ -0,0 +1,433 @@
+/**
+ * Client for the static search index built by `modules/ssearch`.
+ *
+ * The index is a metadata file (`index.json`) listing every document, and a
+ * set of shards mapping terms to the documents that contain them, split by
+ * each term's first character. Only the shards needed for a query are
+ * fetched, and they're cached for subsequent queries.
+ *
+ * Tokenizing, stemming, and ranking all mirror the Go implementation in
+ * `modules/ssearch` (which `sorg search` uses from the terminal), so any
+ * changes must be made to both.
+ */
+(function () {
+    'use strict';
+
+    // Standard BM25 parameters. See `modules/ssearch`.
+    const BM25_B  = 0.75;
+    const BM25_K1 = 1.2;
+
+    // How much of a term's IDF is added to a document's score when the term
+    // appears in its title.
+    const TITLE_WEIGHT = 2.0;
+
+    const MAX_RESULTS = 50;
+
+    // Wait this long after typing stops before searching.
+    const SEARCH_DELAY_MS = 150;
+
+    const STOP_WORDS = new Set([
+        'an', 'and', 'are', 'as', 'at', 'be', 'but', 'by', 'for', 'if', 'in',
+        'into', 'is', 'it', 'no', 'not', 'of', 'on', 'or', 'such', 'that',
+        'the', 'their', 'then', 'there', 'these', 'they', 'this', 'to', 'was',
+        'will', 'with',
+    ]);
+
+    //
+    // Porter stemmer
+    //
+    // A port of `Stem` in `modules/ssearch/stem.go`, itself a port of Martin
+    // Porter's reference implementation.
+    //
+
+    const STEP2_SUFFIXES = {
+        a: [['ational', 'ate'], ['tional', 'tion']],
+        c: [['enci', 'ence'], ['anci', 'ance']],
+        e: [['izer', 'ize']],
+        g: [['logi', 'log']],
+        l: [['bli', 'ble'], ['alli', 'al'], ['entli', 'ent'], ['eli', 'e'], ['ousli', 'ous']],
+        o: [['ization', 'ize'], ['ation', 'ate'], ['ator', 'ate']],
+        s: [['alism', 'al'], ['iveness', 'ive'], ['fulness', 'ful'], ['ousness', 'ous']],
+        t: [['aliti', 'al'], ['iviti', 'ive'], ['biliti', 'ble']],
+    };
+
+    const STEP3_SUFFIXES = {
+        e: [['icate', 'ic'], ['ative', ''], ['alize', 'al']],
+        i: [['iciti', 'ic']],
+        l: [['ical', 'ic'], ['ful', '']],
+        s: [['ness', '']],
+    };
+
+    const STEP4_SUFFIXES = {
+        a: ['al'],
+        c: ['ance', 'ence'],
+        e: ['er'],
+        i: ['ic'],
+        l: ['able', 'ible'],
+        n: ['ant', 'ement', 'ment', 'ent'],
+        o: ['ion', 'ou'],
+        s: ['ism'],
+        t: ['ate', 'iti'],
+        u: ['ous'],
+        v: ['ive'],
+        z: ['ize'],
+    };
+
+    function stem(word) {
+        if (word.length <= 2 || !/^[a-z]+$/.test(word)) {
+            return word;
+        }
+
+        // b.slice(0, k + 1) is the current stem, and j is an offset into it
+        // that's set by ends.
+        let b = word;
+        let j = 0;
+        let k = word.length - 1;
+
+        function cons(i) {
+            switch (b[i]) {
+                case 'a': case 'e': case 'i': case 'o': case 'u':
+                    return false;
+                case 'y':
+                    return i === 0 ? true : !cons(i - 1);
+            }
+            return true;
+        }
+
+        function m() {
+            let n = 0;
+            let i = 0;
+            for (;;) {
+                if (i > j) return n;
+                if (!cons(i)) break;
+                i++;
+            }
+            i++;
+            for (;;) {
+                for (;;) {
+                    if (i > j) return n;
+                    if (cons(i)) break;
+                    i++;
+                }
+                i++;
+                n++;
+                for (;;) {
+                    if (i > j) return n;
+                    if (!cons(i)) break;
+                    i++;
+                }
+                i++;
+            }
+        }
+
+        function vowelInStem() {
+            for (let i = 0; i <= j; i++) {
+                if (!cons(i)) return true;
+            }
+            return false;
+        }
+
+        function doubleC(i) {
+            if (i < 1 || b[i] !== b[i - 1]) return false;
+            return cons(i);
+        }
+
+        function cvc(i) {
+            if (i < 2 || !cons(i) || cons(i - 1) || !cons(i - 2)) return false;
+            return !(b[i] === 'w' || b[i] === 'x' || b[i] === 'y');
+        }
+
+        function ends(s) {
+            if (s.length > k + 1) return false;
+            if (b.slice(k - s.length + 1, k + 1) !== s) return false;
+            j = k - s.length;
+            return true;
+        }
+
+        function setTo(s) {
+            b = b.slice(0, j + 1) + s;
+            k = j + s.length;
+        }
+
+        function r(s) {
+            if (m() > 0) setTo(s);
+        }
+
+        function replaceFirst(suffixes) {
+            for (const [suffix, replacement] of suffixes || []) {
+                if (ends(suffix)) {
+                    r(replacement);
+                    return;
+                }
+            }
+        }
+
+        // Step 1ab
+        if (b[k] === 's') {
+            if (ends('sses')) {
+                k -= 2;
+            } else if (ends('ies')) {
+                setTo('i');
+            } else if (b[k - 1] !== 's') {
+                k--;
+            }
+        }
+        if (ends('eed')) {
+            if (m() > 0) k--;
+        } else if ((ends('ed') || ends('ing')) && vowelInStem()) {
+            k = j;
+            if (ends('at')) {
+                setTo('ate');
+            } else if (ends('bl')) {
+                setTo('ble');
+            } else if (ends('iz')) {
+                setTo('ize');
+            } else if (doubleC(k)) {
+                k--;
+                if (b[k] === 'l' || b[k] === 's' || b[k] === 'z') k++;
+            } else if (m() === 1 && cvc(k)) {
+                setTo('e');
+            }
+        }
+
+        if (k > 0) {
+            // Step 1c
+            if (ends('y') && vowelInStem()) {
+                b = b.slice(0, k) + 'i' + b.slice(k + 1);
+            }
+
+            // Steps 2 and 3
+            replaceFirst(STEP2_SUFFIXES[b[k - 1]]);
+            replaceFirst(STEP3_SUFFIXES[b[k]]);
+
+            // Step 4
+            for (const suffix of STEP4_SUFFIXES[b[k - 1]] || []) {
+                if (!ends(suffix)) continue;
+                if (suffix === 'ion' && (j < 0 || (b[j] !== 's' && b[j] !== 't'))) continue;
+                if (m() > 1) k = j;
+                break;
+            }
+
+            // Step 5
+            j = k;
+            if (b[k] === 'e') {
+                const a = m();
+                if (a > 1 || (a === 1 && !cvc(k - 1))) k--;
+            }
+            if (b[k] === 'l' && doubleC(k) && m() > 1) k--;
+        }
+
+        return b.slice(0, k + 1);
+    }
+
+    //
+    // Index
+    //
+
+    function shardName(term) {
+        return /^[a-z0-9]/.test(term) ? term[0] : '_';
+    }
+
+    function tokenize(s) {
+        const words = s.toLowerCase().replace(/['’]/g, '').split(/[^\p{L}\p{N}]+/u);
+
+        const terms = [];
+        for (const word of words) {
+            if ([...word].length < 2) continue;
+            if (STOP_WORDS.has(word)) continue;
+            terms.push(stem(word));
+        }
+        return terms;
+    }
+
+    class SearchIndex {
+        constructor(url) {
+            this.url = url;
+            this.meta = null;
+            this.shards = new Map();
+        }
+
+        async loadMeta() {
+            if (!this.meta) {
+                this.meta = fetchJSON(this.url + '/index.json');
+            }
+            return this.meta;
+        }
+
+        async loadShard(name) {
+            if (!this.shards.has(name)) {
+                this.shards.set(name, fetchJSON(this.url + '/shards/' + name + '.json'));
+            }
+            return this.shards.get(name);
+        }
+
+        // Mirrors `Index.Search` in `modules/ssearch`.
+        async search(query, limit) {
+            const terms = [...new Set(tokenize(query))].sort();
+            if (terms.length === 0) {
+                return [];
+            }
+
+            const meta = await this.loadMeta();
+            const numDocs = meta.documents.length;
+
+            let scores = null;
+            for (const term of terms) {
+                const name = shardName(term);
+                if (!meta.shards.includes(name)) {
+                    return [];
+                }
+
+                const postings = (await this.loadShard(name))[term];
+                if (!postings || postings.length === 0) {
+                    return [];
+                }
+
+                const df = postings.length;
+                const idf = Math.log(1 + (numDocs - df + 0.5) / (df + 0.5));
+
+                const termScores = new Map();
+                for (const [doc, tf, titleTF] of postings) {
+                    const length = meta.documents[doc].length;
+
+                    let score = idf * tf * (BM25_K1 + 1) /
+                        (tf + BM25_K1 * (1 - BM25_B + BM25_B * length / Math.max(meta.avg_length, 1)));
+                    if (titleTF > 0) {
+                        score += TITLE_WEIGHT * idf;
+                    }
+
+                    termScores.set(doc, score);
+                }
+
+                // Documents must contain every term, so intersect with the
+                // documents found so far.
+                if (scores === null) {
+                    scores = termScores;
+                    continue;
+                }
+                for (const [doc, score] of scores) {
+                    if (!termScores.has(doc)) {
+                        scores.delete(doc);
+                        continue;
+                    }
+                    scores.set(doc, score + termScores.get(doc));
+                }
+            }
+
+            return [...scores]
+                .sort(([docA, scoreA], [docB, scoreB]) => scoreB - scoreA || docA - docB)
+                .slice(0, limit)
+                .map(([doc, score]) => ({ document: meta.documents[doc], score }));
+        }
+    }
+
+    async function fetchJSON(url) {
+        const resp = await fetch(url);
+        if (!resp.ok) {
+            throw new Error(`Error fetching ${url}: ${resp.status}`);
+        }
+        return resp.json();
+    }
+
+    //
+    // Page
+    //
+
+    const DATE_FORMAT = new Intl.DateTimeFormat('en-US',
+        { day: 'numeric', month: 'short', timeZone: 'UTC', year: 'numeric' });
+
+    // Builds a result using the same markup as tag pages.
+    function renderResult(doc) {
+        const li = document.createElement('li');
+        li.className = 'mb-9 mt-1.5 text-md text-proseBody dark:text-proseInvertBody';
+
+        const header = document.createElement('div');
+        header.className = 'mb-2';
+
+        const link = document.createElement('a');
+        link.className = 'border-b-[1px] border-b-slate-200 font-semibold text-proseLinks dark:text-proseInvertLinks dark:border-b-slate-700 hover:border-b-black dark:hover:border-b-proseInvertLinks';
+        link.href = doc.url;
+        link.textContent = doc.title;
+        header.appendChild(link);
+
+        const info = document.createElement('span');
+        info.className = 'italic ml-0.5 text-slate-500 text-xs';
+        info.textContent = ` ${doc.kind}, ${DATE_FORMAT.format(new Date(doc.published_at))}`;
+        header.appendChild(info);
+
+        li.appendChild(header);
+
+        if (doc.snippet) {
+            const snippet = document.createElement('p');
+            snippet.className = 'font-serif leading-7';
+            snippet.textContent = doc.snippet;
+            li.appendChild(snippet);
+        }
+
+        return li;
+    }
+
+    document.addEventListener('DOMContentLoaded', () => {
+        const input = document.getElementById('search-query');
+        const results = document.getElementById('search-results');
+        const status = document.getElementById('search-status');
+
+        const index = new SearchIndex(results.dataset.indexUrl);
+
+        // Guards against a slow search finishing after a later one.
+        let searchNum = 0;
+
+        async function search(query) {
+            const num = ++searchNum;
+
+            const url = new URL(window.location);
+            if (query) {
+                url.searchParams.set('q', query);
+            } else {
+                url.searchParams.delete('q');
+            }
+            window.history.replaceState(null, '', url);
+
+            let matches;
+            try {
+                matches = await index.search(query, MAX_RESULTS);
+            } catch (err) {
+                status.textContent = 'Error loading search index.';
+                console.error(err);
+                return;
+            }
+
+            if (num !== searchNum) {
+                return;
+            }
+
+            results.replaceChildren(...matches.map(match => renderResult(match.document)));
+
+            if (!query.trim()) {
+                status.textContent = '';
+            } else if (matches.length === 0) {
+                status.textContent = `No results for “${query}”.`;
+            } else {
+                status.textContent = `${matches.length === MAX_RESULTS ? 'Top ' : ''}${matches.length} result${matches.length === 1 ? '' : 's'} for “${query}”.`;
+            }
+        }
+
+        let timeout = null;
+        input.addEventListener('input', () => {
+            clearTimeout(timeout);
+            timeout = setTimeout(() => search(input.value), SEARCH_DELAY_MS);
+        });
+
+        document.getElementById('search-form').addEventListener('submit', event => {
+            event.preventDefault();
+            clearTimeout(timeout);
+            search(input.value);
+        });
+
+        const query = new URLSearchParams(window.location.search).get('q');
+        if (query) {
+            input.value = query;
+            search(query);
+        }
+    });
+})();
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// contentLink is a link to a piece of content in a list shown on the page of
+// another, like its backlinks or related content.
+type contentLink struct {
+	// Kind is a human-readable name for the type of the linked content, like
+	// "Article".
+	Kind string `json:"kind"`
+
+	// PublishedAt is when the linked content was published.
+	PublishedAt time.Time `json:"published_at"`
+
+	// Title is the linked content's title.
+	Title string `json:"title"`
+
+	// URL is the linked content's path on the site.
+	URL string `json:"url"`
+}
+
+// contentLinkGraph is a list of links for every piece of content on the site
+// that has one, keyed by its path, like `/fragments/queues`.
+//
+// Graphs are persisted between builds so that a page is only rendered again
+// when the links shown on it change.
+type contentLinkGraph map[string][]*contentLink
+
+// changed returns true if the links of the content at the given path are
+// different in the other graph.
+func (g contentLinkGraph) changed(other contentLinkGraph, contentPath string) bool {
+	return !slices.EqualFunc(g[contentPath], other[contentPath], func(a, b *contentLink) bool {
+		return a.Kind == b.Kind && a.PublishedAt.Equal(b.PublishedAt) && a.Title == b.Title && a.URL == b.URL
+	})
+}
+
+// save writes the graph to the given path.
+func (g contentLinkGraph) save(target string) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return xerrors.Errorf("error marshaling content links: %w", err)
+	}
+
+	tmpTarget := target + ".tmp"
+	if err := os.WriteFile(tmpTarget, data, 0o600); err != nil {
+		return xerrors.Errorf("error writing content links: %w", err)
+	}
+
+	if err := os.Rename(tmpTarget, target); err != nil {
+		return xerrors.Errorf("error renaming content links: %w", err)
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// loadContentLinkGraph loads a graph that pages were last rendered with from
+// the given path. A graph that doesn't exist yet is treated as empty.
+func loadContentLinkGraph(source string) (contentLinkGraph, error) {
+	data, err := os.ReadFile(source)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(contentLinkGraph), nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading content links: %w", err)
+	}
+
+	var graph contentLinkGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling content links %q: %w", source, err)
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestContentLinkGraphChanged(t *testing.T) {
+	publishedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	graph := contentLinkGraph{
+		"/postgres": {{Kind: "Article", PublishedAt: publishedAt, Title: "Postgres", URL: "/queues"}},
+	}
+
+	assert.False(t, graph.changed(graph, "/postgres"))
+	assert.False(t, graph.changed(graph, "/not-linked"))
+
+	// Times in other locations compare by instant.
+	assert.False(t, graph.changed(contentLinkGraph{
+		"/postgres": {{Kind: "Article", PublishedAt: publishedAt.In(localLocation), Title: "Postgres", URL: "/queues"}},
+	}, "/postgres"))
+
+	assert.True(t, graph.changed(contentLinkGraph{
+		"/postgres": {{Kind: "Article", PublishedAt: publishedAt, Title: "Postgres (retitled)", URL: "/queues"}},
+	}, "/postgres"))
+	assert.True(t, graph.changed(contentLinkGraph{}, "/postgres"))
+}
+
+func TestContentLinkGraphSave(t *testing.T) {
+	target := path.Join(t.TempDir(), backlinksFilename)
+
+	// A graph that doesn't exist yet is empty.
+	graph, err := loadContentLinkGraph(target)
+	assert.NoError(t, err)
+	assert.Empty(t, graph)
+
+	graph = contentLinkGraph{
+		"/postgres": {{Kind: "Article", PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Title: "Queues", URL: "/queues"}},
+	}
+	assert.NoError(t, graph.save(target))
+
+	loaded, err := loadContentLinkGraph(target)
+	assert.NoError(t, err)
+	assert.False(t, graph.changed(loaded, "/postgres"))
+}
@@ -3,17 +3,25 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"html/template"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"regexp"
 	"sync"
+	"time"
 
 	"golang.org/x/xerrors"
 
 	"github.com/brandur/modulir"
 	"github.com/brandur/modulir/modules/mtemplatemd"
 	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/sminify"
 )
 
 //
@@ -24,7 +32,33 @@ import (
 // DependencyRegistry maps Go template sources to other Go template sources that
 // have been included in them as dependencies. It's used to know when to trigger
 // a rebuild on a file change.
+//
+// The registry can be persisted to disk along with content hashes of every
+// file it's seen so that a new process can tell which files changed since the
+// last build. Modulir considers every file changed on a build's first run, so
+// without this every cold start would rebuild the entire site.
 type DependencyRegistry struct {
+	// Sources of the assets that views link to through the `Asset` template
+	// function. Every view is a dependent of them because a changed source
+	// gets a new fingerprinted name.
+	assetSources   []string
+	assetSourcesMu sync.RWMutex
+
+	// Maps paths to content hashes as of the last time they were found to
+	// have changed.
+	hashes   map[string]*fileHash
+	hashesMu sync.Mutex
+
+	// Maps paths to content hashes loaded from a previous process's cache.
+	// Only used on a build's first run, and nil if no cache was loaded.
+	persistedHashes map[string]string
+
+	// The time that content was published up to as of the last build, which
+	// is persisted so that content scheduled for after it gets rendered once
+	// its time comes, even if nothing else about it changed. Zero if there
+	// hasn't been a build yet.
+	publishedThrough time.Time
+
 	// Maps sources to their dependencies.
 	sources   map[string][]string
 	sourcesMu sync.RWMutex
@@ -32,10 +66,47 @@ type DependencyRegistry struct {
 
 func NewDependencyRegistry() *DependencyRegistry {
 	return &DependencyRegistry{
+		hashes:  make(map[string]*fileHash),
 		sources: make(map[string][]string),
 	}
 }
 
+// changed is like modulir.Context.Changed, except that on a build's first run
+// with a loaded cache it compares the file's content against the hash from the
+// last build instead of always returning true.
+func (r *DependencyRegistry) changed(c *modulir.Context, path string) bool {
+	if !c.Changed(path) {
+		return false
+	}
+
+	hash, err := r.hashFile(path)
+	if err != nil {
+		// Modulir considers paths that don't exist as changed, so do the
+		// same.
+		return true
+	}
+
+	if !c.FirstRun || c.Forced || r.persistedHashes == nil {
+		return true
+	}
+
+	return r.persistedHashes[path] != hash
+}
+
+// changedAny is the same as changed except it returns true if any of the
+// given paths have changed.
+func (r *DependencyRegistry) changedAny(c *modulir.Context, paths ...string) bool {
+	// Like modulir.Context.ChangedAny, check every path even after finding a
+	// change so that each is watched and hashed.
+	changed := false
+
+	for _, path := range paths {
+		changed = r.changed(c, path) || changed
+	}
+
+	return changed
+}
+
 func (r *DependencyRegistry) getDependencies(source string) []string {
 	r.sourcesMu.RLock()
 	defer r.sourcesMu.RUnlock()
@@ -43,6 +114,117 @@ func (r *DependencyRegistry) getDependencies(source string) []string {
 	return r.sources[source]
 }
 
+// load reads a cache file previously written by save, restoring the map of
+// sources to dependencies and the content hashes that are used to detect
+// changes on the next build's first run. A cache with a version different
+// from the given one is ignored, as is a cache that doesn't exist.
+func (r *DependencyRegistry) load(c *modulir.Context, path, version string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return xerrors.Errorf("error reading dependency cache %q: %w", path, err)
+	}
+
+	var cache dependencyRegistryCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return xerrors.Errorf("error unmarshaling dependency cache %q: %w", path, err)
+	}
+
+	if cache.Version != version {
+		c.Log.Infof("Dependency cache version changed; ignoring cache: %s", path)
+		return nil
+	}
+
+	r.hashesMu.Lock()
+	r.persistedHashes = cache.Hashes
+	r.hashesMu.Unlock()
+
+	r.publishedThrough = cache.PublishedThrough
+
+	r.sourcesMu.Lock()
+	for source, dependencies := range cache.Sources {
+		r.sources[source] = dependencies
+	}
+	r.sourcesMu.Unlock()
+
+	c.Log.Debugf("Loaded dependency cache with %v source(s): %s", len(cache.Sources), path)
+	return nil
+}
+
+// save writes the map of sources to dependencies and the content hashes of
+// every file that's been checked for changes to a cache file that can be
+// restored with load.
+func (r *DependencyRegistry) save(path, version string) error {
+	cache := dependencyRegistryCache{
+		Hashes:           make(map[string]string),
+		PublishedThrough: r.publishedThrough,
+		Version:          version,
+	}
+
+	r.hashesMu.Lock()
+	for hashedPath, hash := range r.hashes {
+		cache.Hashes[hashedPath] = hash.Hash
+	}
+	r.hashesMu.Unlock()
+
+	r.sourcesMu.RLock()
+	cache.Sources = maps.Clone(r.sources)
+	r.sourcesMu.RUnlock()
+
+	data, err := json.Marshal(&cache)
+	if err != nil {
+		return xerrors.Errorf("error marshaling dependency cache: %w", err)
+	}
+
+	// Write to a temporary file and rename it into place so that a crash
+	// midway through never leaves a truncated cache behind.
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return xerrors.Errorf("error writing dependency cache %q: %w", path, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return xerrors.Errorf("error renaming dependency cache %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// hashFile gets a content hash for the given path, reusing a previously
+// computed hash if the file's size and modification time haven't changed.
+func (r *DependencyRegistry) hashFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", xerrors.Errorf("error stating file %q: %w", path, err)
+	}
+
+	r.hashesMu.Lock()
+	hash, ok := r.hashes[path]
+	r.hashesMu.Unlock()
+
+	if ok && hash.ModTime.Equal(info.ModTime()) && hash.Size == info.Size() {
+		return hash.Hash, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", xerrors.Errorf("error reading file %q: %w", path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash = &fileHash{
+		Hash:    hex.EncodeToString(sum[:]),
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+	}
+
+	r.hashesMu.Lock()
+	r.hashes[path] = hash
+	r.hashesMu.Unlock()
+
+	return hash.Hash, nil
+}
+
 func (r *DependencyRegistry) parseGoTemplate(baseTmpl *template.Template,
 	path string,
 ) (*template.Template, []string, error) {
@@ -83,7 +265,21 @@ func (r *DependencyRegistry) renderGoTemplate(ctx context.Context, c *modulir.Co
 	writer := bufio.NewWriter(file)
 	defer writer.Flush()
 
-	return r.renderGoTemplateWriter(ctx, c, source, writer, locals)
+	if minifier == nil {
+		return r.renderGoTemplateWriter(ctx, c, source, writer, locals)
+	}
+
+	minifyWriter := minifier.Writer(writer, sminify.MediaTypeHTML, minifySection(c.TargetDir, target))
+
+	if err := r.renderGoTemplateWriter(ctx, c, source, minifyWriter, locals); err != nil {
+		return err
+	}
+
+	if err := minifyWriter.Close(); err != nil {
+		return xerrors.Errorf("error minifying '%s': %w", target, err)
+	}
+
+	return nil
 }
 
 func (r *DependencyRegistry) renderGoTemplateWriter(ctx context.Context, c *modulir.Context,
@@ -107,6 +303,14 @@ func (r *DependencyRegistry) renderGoTemplateWriter(ctx context.Context, c *modu
 	return nil
 }
 
+// setAssetSources sets the sources of the assets that views link to through
+// the `Asset` template function, which every view depends on.
+func (r *DependencyRegistry) setAssetSources(sources []string) {
+	r.assetSourcesMu.Lock()
+	r.assetSources = sources
+	r.assetSourcesMu.Unlock()
+}
+
 func (r *DependencyRegistry) setDependencies(_ context.Context, c *modulir.Context,
 	source string, dependencies []string,
 ) {
@@ -114,8 +318,48 @@ func (r *DependencyRegistry) setDependencies(_ context.Context, c *modulir.Conte
 	r.sources[source] = dependencies
 	r.sourcesMu.Unlock()
 
-	// Make sure all dependencies are watched.
-	c.ChangedAny(dependencies...)
+	// Make sure all dependencies are watched and hashed.
+	r.changedAny(c, dependencies...)
+}
+
+// viewChanged returns true if the given view or any of its dependencies have
+// changed, including the sources of assets set with setAssetSources. A view
+// that's never been rendered is always considered changed.
+func (r *DependencyRegistry) viewChanged(c *modulir.Context, source string) bool {
+	r.assetSourcesMu.RLock()
+	assetSources := r.assetSources
+	r.assetSourcesMu.RUnlock()
+
+	// Asset sources are checked first and in full so that they're always
+	// watched and hashed, even for a view that's never been rendered.
+	assetsChanged := r.changedAny(c, assetSources...)
+
+	r.sourcesMu.RLock()
+	dependencies, ok := r.sources[source]
+	r.sourcesMu.RUnlock()
+
+	if !ok {
+		return true
+	}
+
+	return r.changedAny(c, dependencies...) || assetsChanged
+}
+
+// dependencyRegistryCache is the format in which a DependencyRegistry is
+// persisted to disk.
+type dependencyRegistryCache struct {
+	Hashes           map[string]string   `json:"hashes"`
+	PublishedThrough time.Time           `json:"published_through"`
+	Sources          map[string][]string `json:"sources"`
+	Version          string              `json:"version"`
+}
+
+// fileHash is a content hash for a file along with the size and modification
+// time that it was computed at.
+type fileHash struct {
+	Hash    string
+	ModTime time.Time
+	Size    int64
 }
 
 var goFileTemplateRE = regexp.MustCompile(`\{\{\-? ?template "([^"]+\.tmpl.html)"`)
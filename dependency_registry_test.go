@@ -2,9 +2,14 @@ package main
 
 import (
 	"html/template"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
 )
 
 func TestDependencyRegistryParseGoTemplate(t *testing.T) {
@@ -65,3 +70,79 @@ func TestFindGoSubTemplates(t *testing.T) {
 	require.Equal(t, []string{}, findGoSubTemplates(`no templates here`))
 	require.Equal(t, []string{}, findGoSubTemplates(``))
 }
+
+func TestDependencyRegistrySaveLoad(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	newContext := func() *modulir.Context {
+		return modulir.NewContext(&modulir.Args{Log: &modulir.Logger{Level: modulir.LevelInfo}})
+	}
+
+	var (
+		cachePath = filepath.Join(dir, dependencyCacheFilename)
+		source    = filepath.Join(dir, "source.tmpl.html")
+		partial   = filepath.Join(dir, "_partial.tmpl.html")
+	)
+	require.NoError(t, os.WriteFile(source, []byte("source"), 0o600))
+	require.NoError(t, os.WriteFile(partial, []byte("partial"), 0o600))
+
+	// Without a cache, everything is changed on the first run.
+	{
+		r := NewDependencyRegistry()
+		c := newContext()
+
+		require.True(t, r.changed(c, source))
+		require.True(t, r.viewChanged(c, source))
+
+		r.setDependencies(ctx, c, source, []string{source, partial})
+		r.publishedThrough = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, r.save(cachePath, "v1"))
+	}
+
+	// A loaded cache means unchanged files aren't considered changed on the
+	// first run.
+	{
+		r := NewDependencyRegistry()
+		c := newContext()
+		require.NoError(t, r.load(c, cachePath, "v1"))
+
+		require.Equal(t, []string{source, partial}, r.getDependencies(source))
+		require.True(t, r.publishedThrough.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.False(t, r.changed(c, source))
+		require.False(t, r.viewChanged(c, source))
+
+		// A view that's never been rendered is always changed.
+		require.True(t, r.viewChanged(c, filepath.Join(dir, "other.tmpl.html")))
+	}
+
+	// A change in a dependency's content is detected.
+	{
+		require.NoError(t, os.WriteFile(partial, []byte("partial changed"), 0o600))
+
+		r := NewDependencyRegistry()
+		c := newContext()
+		require.NoError(t, r.load(c, cachePath, "v1"))
+
+		require.False(t, r.changed(c, source))
+		require.True(t, r.changed(c, partial))
+		require.True(t, r.viewChanged(c, source))
+	}
+
+	// A cache with a different version is ignored.
+	{
+		r := NewDependencyRegistry()
+		c := newContext()
+		require.NoError(t, r.load(c, cachePath, "v2"))
+
+		require.Nil(t, r.getDependencies(source))
+		require.True(t, r.changed(c, source))
+	}
+
+	// A cache that doesn't exist isn't an error.
+	{
+		r := NewDependencyRegistry()
+		c := newContext()
+		require.NoError(t, r.load(c, filepath.Join(dir, "does-not-exist.json"), "v1"))
+	}
+}
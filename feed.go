@@ -0,0 +1,401 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir/modules/matom"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/sjsonfeed"
+	"github.com/brandur/sorg/modules/sminify"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// feed is a feed of one kind of content, written as both Atom and JSON Feed.
+//
+// The newest entries go into subscription documents at `/<name>.atom` and
+// `/<name>.json`. Every entry also goes into an RFC 5005 archive page under
+// `/feeds/<name>/` so that a new subscriber can backfill the feed's full
+// history by following `prev-archive` links (or `next_url` in JSON Feed).
+type feed struct {
+	// Entries are the feed's entries, sorted newest first.
+	Entries []*feedEntry
+
+	// ID is the feed's Atom ID. It's shared by the feed's archive pages
+	// because they're all part of the same logical feed.
+	ID string
+
+	// Name determines where the feed is written, like `articles` for
+	// `/articles.atom`.
+	Name string
+
+	// Title is the feed's title.
+	Title string
+}
+
+// feedEntry is a single entry in a feed.
+type feedEntry struct {
+	// Content is the entry's content as HTML.
+	Content string
+
+	// ID is the entry's Atom ID, usually produced by feedEntryID.
+	ID string
+
+	// Image is an optional image for the entry. It may be an absolute URL
+	// or a path on the site like `/assets/images/...`.
+	Image string
+
+	// PublishedAt is when the entry was published.
+	PublishedAt time.Time
+
+	// Summary is an optional summary of the entry.
+	Summary string
+
+	// Tags are any tags for the entry.
+	Tags []string
+
+	// Title is the entry's title.
+	Title string
+
+	// UpdatedAt is when the entry was last updated, or a zero time if it
+	// hasn't been since it was published.
+	UpdatedAt time.Time
+
+	// URL is the absolute URL of the entry on the site.
+	URL string
+}
+
+func (e *feedEntry) atomEntry() *matom.Entry {
+	entry := &matom.Entry{
+		Title:     e.Title,
+		Summary:   e.Summary,
+		Content:   &matom.EntryContent{Content: e.Content, Type: "html"},
+		Published: e.PublishedAt,
+		Updated:   e.updatedAt(),
+		Link:      &matom.Link{Href: e.URL},
+		ID:        e.ID,
+
+		AuthorName: scommon.AtomAuthorName,
+		AuthorURI:  conf.AbsoluteURL,
+	}
+
+	for _, tag := range e.Tags {
+		entry.Categories = append(entry.Categories, &matom.Category{Term: tag})
+	}
+
+	return entry
+}
+
+func (e *feedEntry) jsonFeedItem() *sjsonfeed.Item {
+	updatedAt := e.updatedAt()
+
+	item := &sjsonfeed.Item{
+		ID:            e.ID,
+		URL:           e.URL,
+		Title:         e.Title,
+		ContentHTML:   e.Content,
+		Summary:       e.Summary,
+		DatePublished: &e.PublishedAt,
+		DateModified:  &updatedAt,
+		Authors:       feedAuthors(),
+		Tags:          e.Tags,
+	}
+
+	if e.Image != "" {
+		item.Image = e.Image
+		if strings.HasPrefix(e.Image, "/") {
+			item.Image = conf.AbsoluteURL + e.Image
+		}
+	}
+
+	return item
+}
+
+// updatedAt is when the entry was last updated, which is when it was published
+// if it hasn't been since.
+func (e *feedEntry) updatedAt() time.Time {
+	if e.UpdatedAt.IsZero() {
+		return e.PublishedAt
+	}
+	return e.UpdatedAt
+}
+
+// atomArchiveFeed is an Atom feed marked as an RFC 5005 archive document with
+// an `fh:archive` element, which matom has no way of expressing.
+type atomArchiveFeed struct {
+	*matom.Feed
+
+	XMLNSFH string    `xml:"xmlns:fh,attr"`
+	Archive *struct{} `xml:"fh:archive"`
+}
+
+// Encode the feed to an io.Writer. Like matom.Feed.Encode, but includes the
+// archive element.
+func (f *atomArchiveFeed) Encode(w io.Writer, indent string) error {
+	if f.XMLLang == "" {
+		f.XMLLang = "en-US"
+	}
+
+	if f.XMLNS == "" {
+		f.XMLNS = "http://www.w3.org/2005/Atom"
+	}
+
+	f.XMLNSFH = "http://purl.org/syndication/history/1.0"
+	f.Archive = &struct{}{}
+
+	_, err := w.Write([]byte(xml.Header))
+	if err != nil {
+		return xerrors.Errorf("error writing Atom feed header: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", indent)
+	if err := enc.Encode(f); err != nil {
+		return xerrors.Errorf("error encoding Atom feed: %w", err)
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Number of entries in each archive page of a feed.
+//
+// Archive pages are filled starting from a feed's oldest entry so that once a
+// page is full it never changes, which RFC 5005 requires of archive documents.
+// Changing this reshuffles every page, so don't.
+const feedArchivePageSize = 50
+
+// articleFeedEntry produces a feed entry for an article.
+func articleFeedEntry(article *Article) *feedEntry {
+	image := article.Image
+	if image == "" {
+		image = article.HookImageURL
+	}
+
+	return &feedEntry{
+		Content:     string(article.Content),
+		ID:          feedEntryID(article.PublishedAt, article.Slug),
+		Image:       image,
+		PublishedAt: article.PublishedAt,
+		Summary:     string(article.Hook),
+		Tags:        tagStrings(article.Tags),
+		Title:       article.Title,
+		UpdatedAt:   article.lastUpdatedAt(),
+		URL:         conf.AbsoluteURL + "/" + article.Slug,
+	}
+}
+
+// feedArchiveURL is the URL of one of a feed's archive pages with the given
+// extension (`.atom` or `.json`). Pages are numbered from 1, the oldest.
+func feedArchiveURL(name string, page int, ext string) string {
+	return conf.AbsoluteURL + "/feeds/" + name + "/" + strconv.Itoa(page) + ext
+}
+
+func feedAuthors() []*sjsonfeed.Author {
+	return []*sjsonfeed.Author{{Name: scommon.AtomAuthorName, URL: conf.AbsoluteURL}}
+}
+
+// feedEntryID produces an Atom ID for an entry that's unique across the site.
+// The name is a slug, optionally prefixed with the kind of content it is, like
+// `atoms:<slug>`.
+func feedEntryID(publishedAt time.Time, name string) string {
+	return "tag:" + scommon.AtomTag + "," + publishedAt.Format("2006-01-02") + ":" + name
+}
+
+// feedURL is the URL of a feed's subscription document with the given
+// extension (`.atom` or `.json`).
+func feedURL(name, ext string) string {
+	return conf.AbsoluteURL + "/" + name + ext
+}
+
+// fragmentFeedEntry produces a feed entry for a fragment.
+func fragmentFeedEntry(fragment *Fragment) *feedEntry {
+	return &feedEntry{
+		Content:     string(fragment.Content),
+		ID:          feedEntryID(fragment.PublishedAt, "fragments/"+fragment.Slug),
+		Image:       fragment.Image,
+		PublishedAt: fragment.PublishedAt,
+		Summary:     string(fragment.Hook),
+		Tags:        tagStrings(fragment.Tags),
+		Title:       fragment.Title,
+		UpdatedAt:   fragment.lastUpdatedAt(),
+		URL:         conf.AbsoluteURL + "/fragments/" + fragment.Slug,
+	}
+}
+
+// writeFeed writes a feed's subscription documents along with all of its
+// archive pages to the target directory.
+func writeFeed(f *feed) error {
+	var (
+		numEntries = len(f.Entries)
+		numPages   = numEntries / feedArchivePageSize
+	)
+
+	// The subscription documents carry the newest entries, and always at
+	// least all the ones that haven't filled an archive page yet.
+	numCurrent := min(numEntries, max(conf.NumAtomEntries, numEntries-numPages*feedArchivePageSize))
+
+	{
+		links := []*matom.Link{
+			{Rel: "self", Type: "application/atom+xml", Href: feedURL(f.Name, ".atom")},
+			{Rel: "alternate", Type: "text/html", Href: conf.AbsoluteURL},
+		}
+
+		var nextURL string
+		if numPages > 0 {
+			links = append(links, &matom.Link{
+				Rel: "prev-archive", Type: "application/atom+xml",
+				Href: feedArchiveURL(f.Name, numPages, ".atom"),
+			})
+			nextURL = feedArchiveURL(f.Name, numPages, ".json")
+		}
+
+		err := writeFeedDocuments(f, f.Entries[0:numCurrent], path.Join(conf.TargetDir, f.Name),
+			links, nextURL, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if numPages < 1 {
+		return nil
+	}
+
+	archiveDir := path.Join(conf.TargetDir, "feeds", f.Name)
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		return xerrors.Errorf("error creating directory '%s': %w", archiveDir, err)
+	}
+
+	for page := 1; page <= numPages; page++ {
+		links := []*matom.Link{
+			{Rel: "self", Type: "application/atom+xml", Href: feedArchiveURL(f.Name, page, ".atom")},
+			{Rel: "current", Type: "application/atom+xml", Href: feedURL(f.Name, ".atom")},
+			{Rel: "alternate", Type: "text/html", Href: conf.AbsoluteURL},
+		}
+
+		var nextURL string
+		if page > 1 {
+			links = append(links, &matom.Link{
+				Rel: "prev-archive", Type: "application/atom+xml",
+				Href: feedArchiveURL(f.Name, page-1, ".atom"),
+			})
+			nextURL = feedArchiveURL(f.Name, page-1, ".json")
+		}
+		if page < numPages {
+			links = append(links, &matom.Link{
+				Rel: "next-archive", Type: "application/atom+xml",
+				Href: feedArchiveURL(f.Name, page+1, ".atom"),
+			})
+		}
+
+		// Entries are sorted newest first, so the oldest page is at the end.
+		entries := f.Entries[numEntries-page*feedArchivePageSize : numEntries-(page-1)*feedArchivePageSize]
+
+		err := writeFeedDocuments(f, entries, path.Join(archiveDir, strconv.Itoa(page)),
+			links, nextURL, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFeedDocuments writes the given entries of a feed to an Atom document
+// and a JSON Feed document at the target path plus `.atom` and `.json`.
+func writeFeedDocuments(f *feed, entries []*feedEntry, target string,
+	links []*matom.Link, nextURL string, archive bool,
+) error {
+	atomFeed := &matom.Feed{
+		Title: f.Title,
+		ID:    f.ID,
+		Links: links,
+	}
+
+	jsonFeed := &sjsonfeed.Feed{
+		Title:       f.Title,
+		HomePageURL: conf.AbsoluteURL,
+		FeedURL:     feedURL(f.Name, ".json"),
+		Authors:     feedAuthors(),
+		NextURL:     nextURL,
+	}
+
+	// Entries are usually sorted by when they were published, but the feed
+	// was last updated whenever any of them was.
+	for _, entry := range entries {
+		if entry.updatedAt().After(atomFeed.Updated) {
+			atomFeed.Updated = entry.updatedAt()
+		}
+	}
+
+	for _, entry := range entries {
+		atomFeed.Entries = append(atomFeed.Entries, entry.atomEntry())
+		jsonFeed.Items = append(jsonFeed.Items, entry.jsonFeedItem())
+	}
+
+	var atomEncode func(w io.Writer, indent string) error = atomFeed.Encode
+	if archive {
+		atomEncode = (&atomArchiveFeed{Feed: atomFeed}).Encode
+	}
+
+	for _, doc := range []struct {
+		encode    func(w io.Writer, indent string) error
+		filename  string
+		mediaType string
+	}{
+		{atomEncode, target + ".atom", sminify.MediaTypeAtom},
+		{jsonFeed.Encode, target + ".json", ""},
+	} {
+		file, err := os.Create(doc.filename)
+		if err != nil {
+			return xerrors.Errorf("error creating file '%s': %w", doc.filename, err)
+		}
+
+		// Feeds are reported as their own section when minified rather than
+		// by where they live because they're spread throughout the site.
+		var (
+			minifyWriter io.WriteCloser
+			w            io.Writer = file
+		)
+		if minifier != nil && doc.mediaType != "" {
+			minifyWriter = minifier.Writer(file, doc.mediaType, "feeds")
+			w = minifyWriter
+		}
+
+		err = doc.encode(w, "  ")
+		if err == nil && minifyWriter != nil {
+			err = minifyWriter.Close()
+		}
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir/modules/matom"
+)
+
+func TestAtomArchiveFeedEncode(t *testing.T) {
+	feed := &atomArchiveFeed{Feed: &matom.Feed{Title: "Atoms", ID: "tag:brandur.org,2019:atoms"}}
+
+	var b bytes.Buffer
+	assert.NoError(t, feed.Encode(&b, "  "))
+
+	assert.Contains(t, b.String(),
+		`<feed xml:lang="en-US" xmlns="http://www.w3.org/2005/Atom" xmlns:fh="http://purl.org/syndication/history/1.0">`)
+	assert.Contains(t, b.String(), `<fh:archive></fh:archive>`)
+	assert.Contains(t, b.String(), `<title>Atoms</title>`)
+}
+
+func TestFeedEntryAtomEntry(t *testing.T) {
+	entry := testFeedEntry(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	atomEntry := entry.atomEntry()
+	assert.Equal(t, entry.ID, atomEntry.ID)
+	assert.Equal(t, entry.Title, atomEntry.Title)
+	assert.Equal(t, entry.Summary, atomEntry.Summary)
+	assert.Equal(t, &matom.EntryContent{Content: entry.Content, Type: "html"}, atomEntry.Content)
+	assert.Equal(t, entry.PublishedAt, atomEntry.Published)
+	assert.Equal(t, entry.PublishedAt, atomEntry.Updated)
+	assert.Equal(t, entry.URL, atomEntry.Link.Href)
+	assert.Equal(t, []*matom.Category{{Term: "postgres"}}, atomEntry.Categories)
+}
+
+func TestFeedEntryUpdatedAt(t *testing.T) {
+	entry := testFeedEntry(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	entry.UpdatedAt = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, entry.PublishedAt, entry.atomEntry().Published)
+	assert.Equal(t, entry.UpdatedAt, entry.atomEntry().Updated)
+	assert.Equal(t, entry.PublishedAt, *entry.jsonFeedItem().DatePublished)
+	assert.Equal(t, entry.UpdatedAt, *entry.jsonFeedItem().DateModified)
+}
+
+func TestFeedEntryJSONFeedItem(t *testing.T) {
+	entry := testFeedEntry(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	{
+		item := entry.jsonFeedItem()
+		assert.Equal(t, entry.ID, item.ID)
+		assert.Equal(t, entry.URL, item.URL)
+		assert.Equal(t, entry.Title, item.Title)
+		assert.Equal(t, entry.Content, item.ContentHTML)
+		assert.Equal(t, entry.Summary, item.Summary)
+		assert.Equal(t, conf.AbsoluteURL+"/assets/images/an-article/hook.jpg", item.Image)
+		assert.Equal(t, entry.PublishedAt, *item.DatePublished)
+		assert.Equal(t, []string{"postgres"}, item.Tags)
+		assert.Len(t, item.Authors, 1)
+	}
+
+	// Absolute image URLs are left alone.
+	{
+		entry.Image = "https://example.com/hook.jpg"
+		assert.Equal(t, "https://example.com/hook.jpg", entry.jsonFeedItem().Image)
+	}
+
+	{
+		entry.Image = ""
+		assert.Empty(t, entry.jsonFeedItem().Image)
+	}
+}
+
+func TestFeedEntryID(t *testing.T) {
+	assert.Equal(t, "tag:brandur.org,2026-01-02:atoms:abc",
+		feedEntryID(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), "atoms:abc"))
+}
+
+func TestWriteFeed(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.AbsoluteURL = "https://example.com"
+	conf.NumAtomEntries = 20
+	conf.TargetDir = t.TempDir()
+
+	// Two full archive pages plus 10 entries that haven't filled a page yet.
+	numEntries := 2*feedArchivePageSize + 10
+
+	f := &feed{ID: "tag:example.com,2026:test", Name: "test", Title: "Test"}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := numEntries - 1; i >= 0; i-- {
+		entry := testFeedEntry(start.Add(time.Duration(i) * time.Hour))
+		entry.ID = feedEntryID(entry.PublishedAt, "entry-"+strconv.Itoa(i))
+		f.Entries = append(f.Entries, entry)
+	}
+
+	assert.NoError(t, writeFeed(f))
+
+	{
+		atomFeed := readAtomFeed(t, path.Join(conf.TargetDir, "test.atom"))
+		assert.Len(t, atomFeed.Entries, conf.NumAtomEntries)
+		assert.Equal(t, f.Entries[0].ID, atomFeed.Entries[0].ID)
+		assert.Equal(t, map[string]string{
+			"self":         "https://example.com/test.atom",
+			"alternate":    "https://example.com",
+			"prev-archive": "https://example.com/feeds/test/2.atom",
+		}, atomFeed.links())
+		assert.Nil(t, atomFeed.Archive)
+
+		jsonFeed := readJSONFeed(t, path.Join(conf.TargetDir, "test.json"))
+		assert.Len(t, jsonFeed.Items, conf.NumAtomEntries)
+		assert.Equal(t, "https://example.com/test.json", jsonFeed.FeedURL)
+		assert.Equal(t, "https://example.com/feeds/test/2.json", jsonFeed.NextURL)
+	}
+
+	// The oldest page holds the oldest entries.
+	{
+		atomFeed := readAtomFeed(t, path.Join(conf.TargetDir, "feeds/test/1.atom"))
+		assert.Len(t, atomFeed.Entries, feedArchivePageSize)
+		assert.Equal(t, f.Entries[numEntries-feedArchivePageSize].ID, atomFeed.Entries[0].ID)
+		assert.Equal(t, f.Entries[numEntries-1].ID, atomFeed.Entries[feedArchivePageSize-1].ID)
+		assert.Equal(t, map[string]string{
+			"self":         "https://example.com/feeds/test/1.atom",
+			"current":      "https://example.com/test.atom",
+			"alternate":    "https://example.com",
+			"next-archive": "https://example.com/feeds/test/2.atom",
+		}, atomFeed.links())
+		assert.NotNil(t, atomFeed.Archive)
+
+		jsonFeed := readJSONFeed(t, path.Join(conf.TargetDir, "feeds/test/1.json"))
+		assert.Len(t, jsonFeed.Items, feedArchivePageSize)
+		assert.Equal(t, "https://example.com/test.json", jsonFeed.FeedURL)
+		assert.Empty(t, jsonFeed.NextURL)
+	}
+
+	{
+		atomFeed := readAtomFeed(t, path.Join(conf.TargetDir, "feeds/test/2.atom"))
+		assert.Len(t, atomFeed.Entries, feedArchivePageSize)
+		assert.Equal(t, f.Entries[10].ID, atomFeed.Entries[0].ID)
+		assert.Equal(t, map[string]string{
+			"self":         "https://example.com/feeds/test/2.atom",
+			"current":      "https://example.com/test.atom",
+			"alternate":    "https://example.com",
+			"prev-archive": "https://example.com/feeds/test/1.atom",
+		}, atomFeed.links())
+
+		jsonFeed := readJSONFeed(t, path.Join(conf.TargetDir, "feeds/test/2.json"))
+		assert.Equal(t, "https://example.com/feeds/test/1.json", jsonFeed.NextURL)
+	}
+
+	assert.NoFileExists(t, path.Join(conf.TargetDir, "feeds/test/3.atom"))
+}
+
+func TestWriteFeedNoArchive(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.NumAtomEntries = 20
+	conf.TargetDir = t.TempDir()
+
+	f := &feed{ID: "tag:example.com,2026:test", Name: "test", Title: "Test"}
+	f.Entries = append(f.Entries, testFeedEntry(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	assert.NoError(t, writeFeed(f))
+
+	atomFeed := readAtomFeed(t, path.Join(conf.TargetDir, "test.atom"))
+	assert.Len(t, atomFeed.Entries, 1)
+	assert.NotContains(t, atomFeed.links(), "prev-archive")
+
+	assert.NoDirExists(t, path.Join(conf.TargetDir, "feeds"))
+}
+
+// When the subscription documents are configured smaller than an archive page,
+// they still include every entry that's not in an archive page yet.
+func TestWriteFeedSmallSubscription(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	conf.NumAtomEntries = 5
+	conf.TargetDir = t.TempDir()
+
+	f := &feed{ID: "tag:example.com,2026:test", Name: "test", Title: "Test"}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := feedArchivePageSize + 10; i > 0; i-- {
+		f.Entries = append(f.Entries, testFeedEntry(start.Add(time.Duration(i)*time.Hour)))
+	}
+
+	assert.NoError(t, writeFeed(f))
+
+	atomFeed := readAtomFeed(t, path.Join(conf.TargetDir, "test.atom"))
+	assert.Len(t, atomFeed.Entries, 10)
+}
+
+//
+// Private
+//
+
+// testAtomFeed is enough of an Atom feed to check what writeFeed produced.
+type testAtomFeed struct {
+	Archive *struct{} `xml:"http://purl.org/syndication/history/1.0 archive"`
+	Entries []struct {
+		ID string `xml:"id"`
+	} `xml:"entry"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+}
+
+func (f *testAtomFeed) links() map[string]string {
+	links := make(map[string]string)
+	for _, link := range f.Links {
+		links[link.Rel] = link.Href
+	}
+	return links
+}
+
+type testJSONFeed struct {
+	FeedURL string            `json:"feed_url"`
+	Items   []json.RawMessage `json:"items"`
+	NextURL string            `json:"next_url"`
+}
+
+func readAtomFeed(t *testing.T, filename string) *testAtomFeed {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+
+	var feed testAtomFeed
+	assert.NoError(t, xml.Unmarshal(data, &feed))
+	return &feed
+}
+
+func readJSONFeed(t *testing.T, filename string) *testJSONFeed {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+
+	var feed testJSONFeed
+	assert.NoError(t, json.Unmarshal(data, &feed))
+	return &feed
+}
+
+func testFeedEntry(publishedAt time.Time) *feedEntry {
+	return &feedEntry{
+		Content:     "<p>Content.</p>",
+		ID:          feedEntryID(publishedAt, "an-article"),
+		Image:       "/assets/images/an-article/hook.jpg",
+		PublishedAt: publishedAt,
+		Summary:     "Summary.",
+		Tags:        []string{"postgres"},
+		Title:       "An Article",
+		URL:         "https://brandur.org/an-article",
+	}
+}
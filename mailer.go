@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v4"
+	"golang.org/x/xerrors"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// mailer is a transport that can send an email message.
+type mailer interface {
+	// Send sends the given message, returning an identifier for it that was
+	// assigned by the transport (for example, a provider's message ID).
+	Send(ctx context.Context, message *mailMessage) (string, error)
+}
+
+// mailMessage is a single email message to be sent through a mailer.
+type mailMessage struct {
+	// From is the address that the message is from, which may include a
+	// display name like `Brandur <brandur@example.com>`.
+	From string
+
+	// HTML is the HTML body of the message.
+	HTML string
+
+	// ReplyTo is the address that replies to the message should go to.
+	ReplyTo string
+
+	// Subject is the message's subject line.
+	Subject string
+
+	// Text is the plain text alternative body of the message.
+	Text string
+
+	// To is the recipient address of the message.
+	To string
+}
+
+// fileMailer is a mailer that doesn't send anything, but rather writes each
+// message as an `.eml` file to a directory. Useful for testing, or keeping an
+// archive of exactly what went out.
+type fileMailer struct {
+	// Dir is the directory that messages are written to.
+	Dir string
+}
+
+func (m *fileMailer) Send(_ context.Context, message *mailMessage) (string, error) {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return "", xerrors.Errorf("error creating mail directory %q: %w", m.Dir, err)
+	}
+
+	messageID := newMessageID()
+
+	data, err := encodeMIMEMessage(message, messageID, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	filename := filepath.Join(m.Dir,
+		time.Now().UTC().Format("20060102T150405Z")+"-"+mailFilenameSafe(message.To)+".eml")
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		return "", xerrors.Errorf("error writing message %q: %w", filename, err)
+	}
+
+	return messageID, nil
+}
+
+// mailgunMailer is a mailer that sends messages through the Mailgun API.
+type mailgunMailer struct {
+	mg mailgun.Mailgun
+}
+
+func newMailgunMailer(domain, apiKey string) *mailgunMailer {
+	return &mailgunMailer{mg: mailgun.NewMailgun(domain, apiKey)}
+}
+
+func (m *mailgunMailer) Send(ctx context.Context, message *mailMessage) (string, error) {
+	mgMessage := m.mg.NewMessage(
+		message.From,
+		message.Subject,
+		message.Text,
+		message.To)
+	mgMessage.SetReplyTo(message.ReplyTo)
+	mgMessage.SetHtml(message.HTML)
+
+	_, id, err := m.mg.Send(ctx, mgMessage)
+	if err != nil {
+		return "", xerrors.Errorf("error sending email through Mailgun: %w", err)
+	}
+
+	return id, nil
+}
+
+// smtpMailer is a mailer that sends messages through a generic SMTP server.
+type smtpMailer struct {
+	// Addr is the address of the SMTP server including a port, like
+	// `smtp.example.com:587`.
+	Addr string
+
+	// Password is the password used to authenticate with the SMTP server.
+	// Only used if Username is also set.
+	Password string
+
+	// Username is the username used to authenticate with the SMTP server. If
+	// empty, no authentication is attempted.
+	Username string
+}
+
+func (m *smtpMailer) Send(_ context.Context, message *mailMessage) (string, error) {
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return "", xerrors.Errorf("error parsing SMTP address %q: %w", m.Addr, err)
+	}
+
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return "", xerrors.Errorf("error parsing from address %q: %w", message.From, err)
+	}
+
+	messageID := newMessageID()
+
+	data, err := encodeMIMEMessage(message, messageID, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	if err := smtp.SendMail(m.Addr, auth, from.Address, []string{message.To}, data); err != nil {
+		return "", xerrors.Errorf("error sending email through SMTP server %q: %w", m.Addr, err)
+	}
+
+	return messageID, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Possible values for Conf.MailTransport.
+const (
+	mailTransportFile    = "file"
+	mailTransportMailgun = "mailgun"
+	mailTransportSMTP    = "smtp"
+)
+
+// newMailer produces a mailer for the transport selected in configuration,
+// checking that the transport has everything it needs to send.
+func newMailer() (mailer, error) {
+	switch conf.MailTransport {
+	case mailTransportFile:
+		if conf.MailDir == "" {
+			return nil, xerrors.Errorf(
+				"MAIL_DIR must be configured in the environment")
+		}
+		return &fileMailer{Dir: conf.MailDir}, nil
+
+	case mailTransportMailgun:
+		if conf.MailgunAPIKey == "" {
+			return nil, xerrors.Errorf(
+				"MAILGUN_API_KEY must be configured in the environment")
+		}
+		return newMailgunMailer(mailDomain, conf.MailgunAPIKey), nil
+
+	case mailTransportSMTP:
+		if conf.SMTPAddr == "" {
+			return nil, xerrors.Errorf(
+				"SMTP_ADDR must be configured in the environment")
+		}
+		return &smtpMailer{
+			Addr:     conf.SMTPAddr,
+			Password: conf.SMTPPassword,
+			Username: conf.SMTPUsername,
+		}, nil
+	}
+
+	return nil, xerrors.Errorf("unknown MAIL_TRANSPORT %q (must be one of: %s, %s, %s)",
+		conf.MailTransport, mailTransportFile, mailTransportMailgun, mailTransportSMTP)
+}
+
+// encodeMIMEMessage encodes a message as a multipart/alternative MIME message
+// with plain text and HTML parts, suitable for sending over SMTP or saving as
+// an `.eml` file.
+func encodeMIMEMessage(message *mailMessage, messageID string, date time.Time) ([]byte, error) {
+	var (
+		body      bytes.Buffer
+		multipart = multipart.NewWriter(&body)
+	)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		w, err := multipart.CreatePart(textproto.MIMEHeader{
+			"Content-Transfer-Encoding": {"quoted-printable"},
+			"Content-Type":              {part.contentType},
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("error creating MIME part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, xerrors.Errorf("error writing MIME part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, xerrors.Errorf("error closing MIME part: %w", err)
+		}
+	}
+
+	if err := multipart.Close(); err != nil {
+		return nil, xerrors.Errorf("error closing MIME message: %w", err)
+	}
+
+	var b bytes.Buffer
+	headers := [][2]string{
+		{"Date", date.Format(time.RFC1123Z)},
+		{"From", message.From},
+		{"To", message.To},
+		{"Reply-To", message.ReplyTo},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Subject)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `multipart/alternative; boundary="` + multipart.Boundary() + `"`},
+	}
+	for _, header := range headers {
+		if header[1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", header[0], header[1])
+	}
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+
+	return b.Bytes(), nil
+}
+
+var mailFilenameUnsafeRE = regexp.MustCompile(`[^a-zA-Z0-9\-_.@]+`)
+
+// mailFilenameSafe makes a string like an email address safe for use in a
+// filename.
+func mailFilenameSafe(s string) string {
+	return strings.Trim(mailFilenameUnsafeRE.ReplaceAllString(s, "_"), "_")
+}
+
+// newMessageID generates a new unique value for a Message-ID header.
+func newMessageID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b[:]), mailDomain)
+}
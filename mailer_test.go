@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestEncodeMIMEMessage(t *testing.T) {
+	data, err := encodeMIMEMessage(testMailMessage(), "<123@example.com>",
+		time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.NoError(t, err)
+
+	// Line breaks are canonicalized to CRLF as MIME requires.
+	text, html := parseMIMEMessage(t, data, "Passages & Glass 001 — First")
+	assert.Equal(t, "Hello, world.\r\n", text)
+	assert.Equal(t, "<p>Hello, world.</p>\r\n", html)
+}
+
+func TestFileMailer(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	id, err := (&fileMailer{Dir: dir}).Send(ctx, testMailMessage())
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(id, "@"+mailDomain+">"))
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Name(), "-passages@list.brandur.org.eml"))
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+
+	text, _ := parseMIMEMessage(t, data, "Passages & Glass 001 — First")
+	assert.Equal(t, "Hello, world.\r\n", text)
+}
+
+func TestMailFilenameSafe(t *testing.T) {
+	assert.Equal(t, "passages@list.brandur.org", mailFilenameSafe("passages@list.brandur.org"))
+	assert.Equal(t, "Brandur_brandur@brandur.org", mailFilenameSafe("Brandur <brandur@brandur.org>"))
+}
+
+func TestNewMailer(t *testing.T) {
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	{
+		conf.MailTransport = mailTransportFile
+		conf.MailDir = ""
+		_, err := newMailer()
+		assert.EqualError(t, err, "MAIL_DIR must be configured in the environment")
+
+		conf.MailDir = "./tmp/mail"
+		m, err := newMailer()
+		assert.NoError(t, err)
+		assert.Equal(t, &fileMailer{Dir: "./tmp/mail"}, m)
+	}
+
+	{
+		conf.MailTransport = mailTransportMailgun
+		conf.MailgunAPIKey = ""
+		_, err := newMailer()
+		assert.EqualError(t, err, "MAILGUN_API_KEY must be configured in the environment")
+
+		conf.MailgunAPIKey = "key"
+		m, err := newMailer()
+		assert.NoError(t, err)
+		assert.IsType(t, &mailgunMailer{}, m)
+	}
+
+	{
+		conf.MailTransport = mailTransportSMTP
+		conf.SMTPAddr = ""
+		_, err := newMailer()
+		assert.EqualError(t, err, "SMTP_ADDR must be configured in the environment")
+
+		conf.SMTPAddr = "localhost:25"
+		m, err := newMailer()
+		assert.NoError(t, err)
+		assert.Equal(t, &smtpMailer{Addr: "localhost:25"}, m)
+	}
+
+	{
+		conf.MailTransport = "carrier-pigeon"
+		_, err := newMailer()
+		assert.EqualError(t, err,
+			`unknown MAIL_TRANSPORT "carrier-pigeon" (must be one of: file, mailgun, smtp)`)
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	ctx := t.Context()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan smtpSinkMessage, 1)
+	go runSMTPSink(t, listener, received)
+
+	id, err := (&smtpMailer{Addr: listener.Addr().String()}).Send(ctx, testMailMessage())
+	assert.NoError(t, err)
+
+	message := <-received
+	assert.Equal(t, "passages@list.brandur.org", message.From)
+	assert.Equal(t, []string{"passages@list.brandur.org"}, message.To)
+
+	msg, err := mail.ReadMessage(strings.NewReader(message.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, id, msg.Header.Get("Message-ID"))
+}
+
+//
+// Private
+//
+
+type smtpSinkMessage struct {
+	Data string
+	From string
+	To   []string
+}
+
+// parseMIMEMessage parses a message produced by encodeMIMEMessage, checks its
+// subject, and returns its plain text and HTML parts.
+func parseMIMEMessage(t *testing.T, data []byte, subject string) (string, string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+
+	decodedSubject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, subject, decodedSubject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		// The multipart reader transparently decodes quoted-printable.
+		content, err := io.ReadAll(part)
+		assert.NoError(t, err)
+
+		mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		parts[mediaType] = string(content)
+	}
+
+	return parts["text/plain"], parts["text/html"]
+}
+
+// runSMTPSink accepts a single connection on the listener and speaks just
+// enough SMTP to receive one message, which is sent to the received channel.
+func runSMTPSink(t *testing.T, listener net.Listener, received chan<- smtpSinkMessage) {
+	t.Helper()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var (
+		message smtpSinkMessage
+		reader  = bufio.NewReader(conn)
+	)
+
+	reply := func(s string) {
+		_, _ = conn.Write([]byte(s + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch command := strings.ToUpper(line); {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.From = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			message.Data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			received <- message
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func testMailMessage() *mailMessage {
+	return &mailMessage{
+		From:    "Brandur <passages@list.brandur.org>",
+		HTML:    "<p>Hello, world.</p>\n",
+		ReplyTo: "brandur@brandur.org",
+		Subject: "Passages & Glass 001 — First",
+		Text:    "Hello, world.\n",
+		To:      "passages@list.brandur.org",
+	}
+}
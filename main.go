@@ -60,31 +60,210 @@ Runs the build loop one time and places the result in TARGET_DIR
 	}
 	rootCmd.AddCommand(loopCommand)
 
+	var concurrency int
+	var external bool
+	checkLinksCommand := &cobra.Command{
+		Use:   "check-links",
+		Short: "Check a built site for broken links",
+		Long: strings.TrimSpace(`
+Walks the site built to TARGET_DIR (default ./public/) and checks
+that every internal link resolves to a built page, and that any
+#fragment resolves to an ID in it. Broken links are reported
+grouped by the content source they came from, and the command
+exits with a non-zero status if there were any.
+
+With --external, external links are checked too using HEAD
+requests. Links that check out are cached for a week in
+./tmp/link-cache.json so that they're not rechecked every run.`),
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			c := &modulir.Context{
+				Log:       getLog(),
+				SourceDir: ".",
+				TargetDir: conf.TargetDir,
+			}
+			checkLinks(c, external, concurrency)
+		},
+	}
+	checkLinksCommand.Flags().IntVar(&concurrency, "concurrency", 10,
+		"Maximum number of external links to check at once")
+	checkLinksCommand.Flags().BoolVar(&external, "external", false,
+		"Check external links as well as internal ones")
+	rootCmd.AddCommand(checkLinksCommand)
+
+	var force bool
+	var history bool
 	var live bool
+	var scheduled bool
 	var staging bool
 	sendCommand := &cobra.Command{
 		Use:   "send [source newsletter .md file]",
-		Short: "Email a Nanoglyph or Passages newsletter",
+		Short: "Email a newsletter issue",
 		Long: strings.TrimSpace(`
-Emails the Nanoglyph or Passages newsletter at the location given
-as argument. Note that MAILGUN_API_KEY must be set in the
-environment for this to work as it executes against the Mailgun
-API.`),
-		Args: cobra.ExactArgs(1),
+Emails the newsletter issue (e.g. Nanoglyph or Passages) at the
+location given as argument. Newsletters are defined in
+content/newsletters.toml.
+
+Mail goes out through the transport selected by MAIL_TRANSPORT:
+"mailgun" (the default, requires MAILGUN_API_KEY), "smtp"
+(requires SMTP_ADDR), or "file" (writes .eml files to MAIL_DIR
+instead of sending anything).
+
+Every send is recorded in a ledger at SEND_LOG_PATH, and an issue
+that's already gone out to a live list won't be sent to it again
+unless --force is given. Use --history to list past sends.
+
+With --scheduled, every newsletter issue whose published_at has
+passed (within the last week) and that hasn't yet gone out to its
+live list is sent to it. This is meant to be run periodically by
+a cron job.`),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if history || scheduled {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		Run: func(_ *cobra.Command, args []string) {
+			if history {
+				showSendHistory()
+				return
+			}
+
 			c := &modulir.Context{
-				Forced: true, // bypasses some cache checks that won't work with this minimal context
-				Log:    getLog(),
+				Forced:    true, // bypasses some cache checks that won't work with this minimal context
+				Log:       getLog(),
+				SourceDir: ".",
 			}
-			sendNewsletter(c, args[0], live, staging)
+
+			if scheduled {
+				sendScheduled(c)
+				return
+			}
+
+			sendNewsletter(c, args[0], live, staging, force)
 		},
 	}
+	sendCommand.Flags().BoolVar(&force, "force", false,
+		"Send to live list even if the issue was already sent to it")
+	sendCommand.Flags().BoolVar(&history, "history", false,
+		"List past sends from the send log instead of sending")
 	sendCommand.Flags().BoolVar(&live, "live", false,
 		"Send to list (as opposed to dry run)")
+	sendCommand.Flags().BoolVar(&scheduled, "scheduled", false,
+		"Send every issue that's due to live lists and hasn't been sent yet")
+	sendCommand.MarkFlagsMutuallyExclusive("history", "scheduled")
 	sendCommand.Flags().BoolVar(&staging, "staging", false,
 		"Send to staging list (as opposed to dry run)")
 	rootCmd.AddCommand(sendCommand)
 
+	publishedCommand := &cobra.Command{
+		Use:   "published",
+		Short: "Add new content to the ledger of published paths",
+		Long: strings.TrimSpace(`
+Adds the path of every article, fragment, and newsletter issue
+that's not a draft, including scheduled ones, to the ledger of
+published paths in data/published.toml. Builds fail if content
+is missing from the ledger, so run this when adding content and
+check in the result.`),
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			c := &modulir.Context{
+				Log:       getLog(),
+				SourceDir: ".",
+			}
+			updatePublishedLedger(c)
+		},
+	}
+	rootCmd.AddCommand(publishedCommand)
+
+	var limit int
+	searchCommand := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the site's content",
+		Long: strings.TrimSpace(`
+Searches the site's content for the given query using the search
+index of a site already built to TARGET_DIR (default ./public/).
+This is the same index and ranking used by the search page at
+/search, so it's useful for checking results.`),
+		Args: cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			searchIndex(strings.Join(args, " "), limit)
+		},
+	}
+	searchCommand.Flags().IntVar(&limit, "limit", 10,
+		"Maximum number of results to show")
+	rootCmd.AddCommand(searchCommand)
+
+	var (
+		statsLimit int
+		statsYear  int
+	)
+	statsCommand := &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics about the site's content",
+		Long: strings.TrimSpace(`
+Prints word counts, reading times, and counts of code blocks,
+images, and footnotes for the articles, fragments, and newsletter
+issues of a site already built to TARGET_DIR (default ./public/).
+They're totaled by year and by kind of content, followed by the
+longest pieces.`),
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			printStats(statsYear, statsLimit)
+		},
+	}
+	statsCommand.Flags().IntVar(&statsLimit, "limit", 10,
+		"Number of the longest pieces of content to show (zero or less for all)")
+	statsCommand.Flags().IntVar(&statsYear, "year", 0,
+		"Only include content published in the given year")
+	rootCmd.AddCommand(statsCommand)
+
+	spring83Command := &cobra.Command{
+		Use:   "spring83",
+		Short: "Work with the Spring '83 board",
+	}
+	rootCmd.AddCommand(spring83Command)
+
+	spring83PublishCommand := &cobra.Command{
+		Use:   "publish",
+		Short: "Publish the latest atom as a Spring '83 board",
+		Long: strings.TrimSpace(`
+Publishes a board made from the latest atom to the Spring '83
+server at SPRING83_SERVER_URL (default
+https://neospring.brandur.org), signing it with the key in
+SPRING83_PRIVATE_KEY. Publishing a board that the server already
+has is a no-op.`),
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			c := &modulir.Context{
+				Log:       getLog(),
+				SourceDir: ".",
+			}
+			publishSpring83(c)
+		},
+	}
+	spring83Command.AddCommand(spring83PublishCommand)
+
+	validateCommand := &cobra.Command{
+		Use:   "validate",
+		Short: "Check all content for problems without rendering",
+		Long: strings.TrimSpace(`
+Parses and validates every article, fragment, newsletter issue,
+atom, sequence entry, and photo without rendering the site, then
+reports every problem found along with its file and line. Exits
+with a non-zero status if there were any problems. Drafts are
+included if DRAFTS is set.`),
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			c := &modulir.Context{
+				Log:       getLog(),
+				SourceDir: ".",
+			}
+			validateAll(c)
+		},
+	}
+	rootCmd.AddCommand(validateCommand)
+
 	if err := envdecode.Decode(&conf); err != nil {
 		fmt.Fprintf(os.Stderr, "Error decoding conf from env: %v", err)
 		os.Exit(1)
@@ -136,6 +315,12 @@ type Conf struct {
 	// in order to extract books, tweets, runs, etc.
 	BlackSwanDatabaseURL string `env:"BLACK_SWAN_DATABASE_URL"`
 
+	// Compress is whether the build writes gzip and Brotli compressed
+	// siblings (like `index.html.gz`) of built files that are worth
+	// compressing, so that they can be served without compressing on the
+	// fly. Only files whose content changed are compressed again.
+	Compress bool `env:"COMPRESS,default=false"`
+
 	// Concurrency is the number of build Goroutines that will be used to
 	// perform build work items.
 	Concurrency int `env:"CONCURRENCY,default=30"`
@@ -163,20 +348,34 @@ type Conf struct {
 	// where you otherwise wouldn't have the fonts.
 	LocalFonts bool `env:"LOCAL_FONTS,default=false"`
 
+	// MailDir is the directory that `.eml` files are written to when
+	// MailTransport is `file`.
+	MailDir string `env:"MAIL_DIR,default=./tmp/mail"`
+
+	// MailTransport is the transport used to send email with the `send`
+	// command. One of `mailgun`, `smtp`, or `file`.
+	MailTransport string `env:"MAIL_TRANSPORT,default=mailgun"`
+
 	// MailgunAPIKey is a key for Mailgun used to send email. It's required
-	// when using the `passages` command.
+	// when MailTransport is `mailgun`.
 	MailgunAPIKey string `env:"MAILGUN_API_KEY"`
 
 	// MagickBin is the location of the `magick` binary that ships with the
 	// ImageMagick project (an image manipulation utility).
 	MagickBin string `env:"MAGICK_BIN"`
 
+	// Minify is whether rendered HTML and Atom documents are minified. It's
+	// always off in development so that output stays easy to read.
+	Minify bool `env:"MINIFY,default=false"`
+
 	// MozJPEGBin is the location of the `cjpeg` binary that ships with the
 	// mozjpeg project (a JPG optimizer). If configured, Sorg will put photos
 	// through an optimization pass after resizing them.
 	MozJPEGBin string `env:"MOZJPEG_BIN"`
 
-	// NumAtomEntries is the number of entries to put in Atom feeds.
+	// NumAtomEntries is the minimum number of entries to put in the
+	// subscription documents of Atom and JSON feeds. Older entries are found
+	// in archive pages.
 	NumAtomEntries int `env:"NUM_ATOM_ENTRIES,default=20"`
 
 	// PNGQuantBin is the location of the `pnqquant` binary (a PNG optimizer). If
@@ -187,10 +386,34 @@ type Conf struct {
 	// Port is the port on which to serve HTTP when looping in development.
 	Port int `env:"PORT,default=5002"`
 
+	// SMTPAddr is the address of an SMTP server including a port (e.g.
+	// `smtp.example.com:587`). It's required when MailTransport is `smtp`.
+	SMTPAddr string `env:"SMTP_ADDR"`
+
+	// SMTPPassword is the password used to authenticate with the SMTP server.
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+
+	// SMTPUsername is the username used to authenticate with the SMTP server.
+	// If left empty, no authentication is attempted.
+	SMTPUsername string `env:"SMTP_USERNAME"`
+
+	// SendLogPath is the location of the ledger that records every newsletter
+	// send. It's checked in so that it's shared between machines.
+	SendLogPath string `env:"SEND_LOG_PATH,default=./data/sends.toml"`
+
 	// SorgEnv is the environment to run the app with. Use "development" to
 	// activate development features.
 	SorgEnv string `env:"SORG_ENV,default=production"`
 
+	// Spring83PrivateKey is a hex-encoded Ed25519 private key used to sign
+	// Spring '83 boards. If set, builds produce a signed board from the
+	// latest atom, and it's required by `spring83 publish`.
+	Spring83PrivateKey string `env:"SPRING83_PRIVATE_KEY"`
+
+	// Spring83ServerURL is the Spring '83 server that boards are published
+	// to by `spring83 publish`.
+	Spring83ServerURL string `env:"SPRING83_SERVER_URL,default=https://neospring.brandur.org"`
+
 	// TargetDir is the target location where the site will be built to.
 	TargetDir string `env:"TARGET_DIR,default=./public"`
 
@@ -0,0 +1,67 @@
+// Package sassets fingerprints static assets like JavaScripts and stylesheets
+// by putting a hash of their contents in their names, so that they can be
+// cached forever and a changed asset gets a new URL.
+package sassets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Fingerprint copies an asset into targetDir under its fingerprinted name
+// (see FingerprintedName), returning the name.
+//
+// Because a fingerprinted name changes along with the asset's contents, a
+// file that's already in targetDir is left alone. Old versions of an asset
+// are left in place too, so that pages referencing them that are still
+// cached somewhere keep working.
+func Fingerprint(source, targetDir string) (string, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return "", xerrors.Errorf("error reading asset: %w", err)
+	}
+
+	name := FingerprintedName(filepath.Base(source), data)
+	target := path.Join(targetDir, name)
+
+	if _, err := os.Stat(target); err == nil {
+		return name, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", xerrors.Errorf("error checking asset '%s': %w", target, err)
+	}
+
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return "", xerrors.Errorf("error creating asset directory: %w", err)
+	}
+
+	// Write to a temporary file first so that a partially written asset is
+	// never mistaken for a complete one. Assets are served, so they're
+	// readable by everyone like other files in the target directory.
+	if err := os.WriteFile(target+".tmp", data, 0o644); err != nil { //nolint:gosec
+		return "", xerrors.Errorf("error writing asset: %w", err)
+	}
+
+	if err := os.Rename(target+".tmp", target); err != nil {
+		return "", xerrors.Errorf("error renaming asset: %w", err)
+	}
+
+	return name, nil
+}
+
+// FingerprintedName inserts a hash of an asset's contents into its name
+// before its extension, like `main.0123abcd.css` for `main.css`.
+func FingerprintedName(name string, data []byte) string {
+	hash := sha256.Sum256(data)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(hash[:])[:hashLength] + ext
+}
+
+// Number of hex characters of an asset's hash that are included in its name.
+const hashLength = 10
@@ -0,0 +1,48 @@
+package sassets
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	sourceDir := t.TempDir()
+	targetDir := path.Join(t.TempDir(), "stylesheets")
+
+	source := path.Join(sourceDir, "main.css")
+	assert.NoError(t, os.WriteFile(source, []byte("body { color: black; }"), 0o600))
+
+	name, err := Fingerprint(source, targetDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "main.06bead3bcb.css", name)
+
+	data, err := os.ReadFile(path.Join(targetDir, name))
+	assert.NoError(t, err)
+	assert.Equal(t, "body { color: black; }", string(data))
+
+	// An unchanged asset gets the same name.
+	sameName, err := Fingerprint(source, targetDir)
+	assert.NoError(t, err)
+	assert.Equal(t, name, sameName)
+
+	// A changed one gets a new name, and the old version is left in place.
+	assert.NoError(t, os.WriteFile(source, []byte("body { color: white; }"), 0o600))
+
+	newName, err := Fingerprint(source, targetDir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, name, newName)
+	assert.FileExists(t, path.Join(targetDir, name))
+	assert.FileExists(t, path.Join(targetDir, newName))
+
+	_, err = Fingerprint(path.Join(sourceDir, "missing.css"), targetDir)
+	assert.ErrorContains(t, err, "error reading asset")
+}
+
+func TestFingerprintedName(t *testing.T) {
+	assert.Equal(t, "main.06bead3bcb.css", FingerprintedName("main.css", []byte("body { color: black; }")))
+	assert.Equal(t, "tailwind.min.06bead3bcb.css", FingerprintedName("tailwind.min.css", []byte("body { color: black; }")))
+	assert.Equal(t, "LICENSE.e3b0c44298", FingerprintedName("LICENSE", nil))
+}
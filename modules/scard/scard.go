@@ -0,0 +1,334 @@
+// Package scard draws Open Graph "cards": the preview images shown by social
+// networks and chat clients when a link is shared. Cards are drawn entirely in
+// Go using the Go fonts so that they can be produced as part of a build
+// without any external tools.
+package scard
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/xerrors"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Constants
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Height is the height of a card in pixels.
+	Height = 630
+
+	// Width is the width of a card in pixels. 1200x630 is the size
+	// recommended for Open Graph images, and what most clients will crop to
+	// anyway.
+	Width = 1200
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Card holds the information drawn into a card.
+type Card struct {
+	// Date is the publication date of the content. Not drawn if zero.
+	Date time.Time
+
+	// Hook is a plain text introduction to the content, drawn below the
+	// title and truncated to however many lines fit. Optional.
+	Hook string
+
+	// Image is an optional image drawn along the right side of the card,
+	// scaled and cropped to fill it.
+	Image image.Image
+
+	// Mark is the name of the site, drawn above the title.
+	Mark string
+
+	// Title is the content's title.
+	Title string
+}
+
+// Encode draws a card and writes it to w as a PNG.
+func Encode(w io.Writer, card *Card) error {
+	img, err := Render(card)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(w, img); err != nil {
+		return xerrors.Errorf("error encoding card: %w", err)
+	}
+
+	return nil
+}
+
+// Render draws a card.
+func Render(card *Card) (*image.RGBA, error) {
+	faces, err := loadFaces()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+
+	// A strip of accent color down the left side.
+	draw.Draw(img, image.Rect(0, 0, accentWidth, Height), image.NewUniform(colorAccent), image.Point{}, draw.Src)
+
+	textWidth := Width - 2*padding
+	if card.Image != nil {
+		imageRect := image.Rect(Width-imageWidth, 0, Width, Height)
+		drawCover(img, imageRect, card.Image)
+		textWidth -= imageWidth
+	}
+
+	d := &font.Drawer{Dst: img}
+
+	// Site mark at the top.
+	d.Face = faces.mark
+	d.Src = image.NewUniform(colorMuted)
+	drawLine(d, padding, padding+faces.mark.Metrics().Ascent.Ceil(), card.Mark)
+
+	// Date pinned to the bottom, which also bounds how far the hook can go.
+	bottom := Height - padding
+	if !card.Date.IsZero() {
+		drawLine(d, padding, bottom, card.Date.Format("January 2, 2006"))
+		bottom -= faces.mark.Metrics().Height.Ceil() + lineGap
+	}
+
+	// Title, taking up as many as three lines.
+	y := padding + faces.mark.Metrics().Height.Ceil() + sectionGap
+	d.Face = faces.title
+	d.Src = image.NewUniform(colorText)
+	titleLineHeight := faces.title.Metrics().Height.Ceil()
+	for _, line := range wrapText(faces.title, card.Title, textWidth, maxTitleLines) {
+		y += titleLineHeight
+		drawLine(d, padding, y, line)
+	}
+
+	// Hook, taking up whatever space is left.
+	if card.Hook != "" {
+		y += sectionGap
+		d.Face = faces.hook
+		d.Src = image.NewUniform(colorHook)
+		hookLineHeight := faces.hook.Metrics().Height.Ceil() + lineGap
+		maxLines := (bottom - y) / hookLineHeight
+		for _, line := range wrapText(faces.hook, card.Hook, textWidth, maxLines) {
+			y += hookLineHeight
+			drawLine(d, padding, y, line)
+		}
+	}
+
+	return img, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+const (
+	accentWidth   = 16
+	ellipsis      = "…"
+	imageWidth    = 450
+	lineGap       = 8
+	maxTitleLines = 3
+	padding       = 80
+	sectionGap    = 32
+)
+
+var (
+	colorAccent     = color.RGBA{0xd7, 0x26, 0x3d, 0xff}
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorHook       = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	colorMuted      = color.RGBA{0x88, 0x88, 0x88, 0xff}
+	colorText       = color.RGBA{0x11, 0x11, 0x11, 0xff}
+)
+
+type faces struct {
+	hook  font.Face
+	mark  font.Face
+	title font.Face
+}
+
+// loadFaces parses the Go fonts once and produces the faces used to draw a
+// card. Faces keep a glyph cache that's not safe for concurrent use, so a new
+// set is produced every time.
+func loadFaces() (*faces, error) {
+	fonts, err := parseFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	newFace := func(f *opentype.Font, size float64) (font.Face, error) {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{
+			DPI:     72,
+			Hinting: font.HintingFull,
+			Size:    size,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("error creating font face: %w", err)
+		}
+		return face, nil
+	}
+
+	var fs faces
+	if fs.hook, err = newFace(fonts.regular, 34); err != nil {
+		return nil, err
+	}
+	if fs.mark, err = newFace(fonts.bold, 28); err != nil {
+		return nil, err
+	}
+	if fs.title, err = newFace(fonts.bold, 64); err != nil {
+		return nil, err
+	}
+	return &fs, nil
+}
+
+type parsedFonts struct {
+	bold    *opentype.Font
+	regular *opentype.Font
+}
+
+var parseFonts = sync.OnceValues(func() (*parsedFonts, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing bold font: %w", err)
+	}
+
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing regular font: %w", err)
+	}
+
+	return &parsedFonts{bold: bold, regular: regular}, nil
+})
+
+// drawCover scales src so that it covers all of rect, cropping whatever
+// overflows evenly from both sides.
+func drawCover(dst draw.Image, rect image.Rectangle, src image.Image) {
+	srcBounds := src.Bounds()
+	if srcBounds.Empty() {
+		return
+	}
+
+	// Compare aspect ratios to find which dimension of the source has to be
+	// cropped.
+	crop := srcBounds
+	if srcBounds.Dx()*rect.Dy() > srcBounds.Dy()*rect.Dx() {
+		width := srcBounds.Dy() * rect.Dx() / rect.Dy()
+		crop.Min.X += (srcBounds.Dx() - width) / 2
+		crop.Max.X = crop.Min.X + width
+	} else {
+		height := srcBounds.Dx() * rect.Dy() / rect.Dx()
+		crop.Min.Y += (srcBounds.Dy() - height) / 2
+		crop.Max.Y = crop.Min.Y + height
+	}
+
+	draw.CatmullRom.Scale(dst, rect, src, crop, draw.Src, nil)
+}
+
+func drawLine(d *font.Drawer, x, y int, s string) {
+	d.Dot = fixed.P(x, y)
+	d.DrawString(s)
+}
+
+// wrapText breaks s into lines no wider than width, stopping at maxLines. If
+// the text didn't fit, the last line is truncated with an ellipsis.
+func wrapText(face font.Face, s string, width, maxLines int) []string {
+	if maxLines < 1 {
+		return nil
+	}
+
+	maxWidth := fixed.I(width)
+
+	var (
+		lines []string
+		line  string
+		words = strings.Fields(s)
+	)
+	for _, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+
+		if line == "" || font.MeasureString(face, candidate) <= maxWidth {
+			line = candidate
+			continue
+		}
+
+		lines = append(lines, line)
+		line = word
+
+		if len(lines) == maxLines {
+			// Text remains that didn't fit, so mark the last line as
+			// truncated.
+			lines[maxLines-1] = truncate(face, lines[maxLines-1], maxWidth)
+			return lines
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	// A single word can be wider than the card, so truncate lines that are
+	// still too long.
+	for i, line := range lines {
+		if font.MeasureString(face, line) > maxWidth {
+			lines[i] = truncate(face, line, maxWidth)
+		}
+	}
+
+	return lines
+}
+
+// truncate shortens s until it fits in maxWidth along with a trailing
+// ellipsis, preferring to break between words.
+func truncate(face font.Face, s string, maxWidth fixed.Int26_6) string {
+	for {
+		if font.MeasureString(face, s+ellipsis) <= maxWidth {
+			return s + ellipsis
+		}
+
+		if i := strings.LastIndex(s, " "); i > 0 {
+			s = s[0:i]
+			continue
+		}
+
+		runes := []rune(s)
+		if len(runes) == 0 {
+			return ellipsis
+		}
+		s = string(runes[0 : len(runes)-1])
+	}
+}
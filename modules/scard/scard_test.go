@@ -0,0 +1,87 @@
+package scard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestEncode(t *testing.T) {
+	var buf bytes.Buffer
+	err := Encode(&buf, &Card{
+		Date:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Hook:  "A hook.",
+		Mark:  "brandur.org",
+		Title: "A title",
+	})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+}
+
+func TestRender(t *testing.T) {
+	{
+		img, err := Render(&Card{Mark: "brandur.org", Title: "A title"})
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+		assert.Equal(t, colorAccent, img.RGBAAt(0, 0))
+		assert.Equal(t, colorBackground, img.RGBAAt(Width-1, Height-1))
+	}
+
+	// With an image, which fills the right side of the card.
+	{
+		red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+		src := image.NewRGBA(image.Rect(0, 0, 100, 50))
+		for y := range 50 {
+			for x := range 100 {
+				src.SetRGBA(x, y, red)
+			}
+		}
+
+		img, err := Render(&Card{Image: src, Mark: "brandur.org", Title: "A title"})
+		assert.NoError(t, err)
+		assert.Equal(t, red, img.RGBAAt(Width-imageWidth, 0))
+		assert.Equal(t, red, img.RGBAAt(Width-1, Height-1))
+		assert.Equal(t, colorBackground, img.RGBAAt(Width-imageWidth-1, Height-1))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	faces, err := loadFaces()
+	assert.NoError(t, err)
+
+	face := faces.hook
+	width := font.MeasureString(face, "aaaa bbbb").Ceil()
+
+	assert.Equal(t, []string{"aaaa bbbb", "cccc"}, wrapText(face, "aaaa bbbb cccc", width, 3))
+	assert.Equal(t, []string{"aaaa bbbb", "cccc dddd"}, wrapText(face, "aaaa  bbbb\ncccc dddd", width, 3))
+	assert.Nil(t, wrapText(face, "aaaa", width, 0))
+	assert.Empty(t, wrapText(face, "", width, 3))
+
+	// Text that doesn't fit is truncated with an ellipsis.
+	{
+		lines := wrapText(face, "aaaa bbbb cccc dddd eeee", width, 2)
+		assert.Len(t, lines, 2)
+		assert.Equal(t, "aaaa bbbb", lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], ellipsis))
+		assert.LessOrEqual(t, font.MeasureString(face, lines[1]), fixed.I(width))
+	}
+
+	// As is a single word that's wider than the card.
+	{
+		lines := wrapText(face, strings.Repeat("a", 100), width, 2)
+		assert.Len(t, lines, 1)
+		assert.True(t, strings.HasSuffix(lines[0], ellipsis))
+		assert.LessOrEqual(t, font.MeasureString(face, lines[0]), fixed.I(width))
+	}
+}
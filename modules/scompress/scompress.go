@@ -0,0 +1,215 @@
+// Package scompress writes pre-compressed gzip and Brotli siblings of built
+// files (like `index.html.gz` and `index.html.br` next to `index.html`) so
+// that they can be served without having to be compressed on the fly.
+package scompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/xerrors"
+)
+
+// Extensions of compressed siblings. A file with one of these is never
+// compressed itself.
+const (
+	ExtBrotli = ".br"
+	ExtGzip   = ".gz"
+)
+
+// MinSize is the size in bytes below which files aren't worth compressing.
+// The savings on small files are negligible, and a compressed response can
+// even end up larger than the original.
+const MinSize = 1024
+
+// Manifest tracks the content hash of every file as of the last time that
+// its siblings were written so that only files whose content changed are
+// compressed again. It can be persisted to disk between runs.
+type Manifest struct {
+	// Files maps the paths of compressed files to their state when they
+	// were compressed.
+	Files map[string]*fileState `json:"files"`
+
+	mu sync.Mutex
+}
+
+// LoadManifest loads a manifest from the given path. If nothing's there yet,
+// an empty manifest is returned.
+func LoadManifest(source string) (*Manifest, error) {
+	manifest := &Manifest{Files: make(map[string]*fileState)}
+
+	data, err := os.ReadFile(source)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading compression manifest '%s': %w", source, err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling compression manifest '%s': %w", source, err)
+	}
+
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]*fileState)
+	}
+
+	return manifest, nil
+}
+
+// Compress writes gzip and Brotli siblings for the file at source, unless
+// its content hasn't changed since the last time it was compressed and its
+// siblings are still there. Returns true if the siblings were written.
+func (m *Manifest) Compress(source string) (bool, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return false, xerrors.Errorf("error stating file '%s': %w", source, err)
+	}
+
+	m.mu.Lock()
+	state := m.Files[source]
+	m.mu.Unlock()
+
+	// Most files are left untouched between builds, so avoid reading them
+	// if their size and modification time are the same as last time.
+	if state != nil && state.ModTime.Equal(info.ModTime()) && state.Size == info.Size() && siblingsExist(source) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return false, xerrors.Errorf("error reading file '%s': %w", source, err)
+	}
+
+	sum := sha256.Sum256(data)
+	newState := &fileState{
+		Hash:    hex.EncodeToString(sum[:]),
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+	}
+
+	// Many files are rewritten on every build even though their content
+	// stays the same.
+	if state != nil && state.Hash == newState.Hash && siblingsExist(source) {
+		m.put(source, newState)
+		return false, nil
+	}
+
+	if err := writeSibling(source+ExtGzip, data, compressGzip); err != nil {
+		return false, err
+	}
+
+	if err := writeSibling(source+ExtBrotli, data, compressBrotli); err != nil {
+		return false, err
+	}
+
+	m.put(source, newState)
+	return true, nil
+}
+
+// Save writes the manifest to the given path.
+func (m *Manifest) Save(target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return xerrors.Errorf("error marshaling compression manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(path.Dir(target), 0o755); err != nil {
+		return xerrors.Errorf("error creating directory for compression manifest: %w", err)
+	}
+
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return xerrors.Errorf("error writing compression manifest: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manifest) put(source string, state *fileState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Files[source] = state
+}
+
+// fileState is a file's content hash along with the size and modification
+// time that it was computed at.
+type fileState struct {
+	Hash    string    `json:"hash"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+func compressBrotli(w io.Writer, data []byte) error {
+	bw := brotli.NewWriterLevel(w, brotli.BestCompression)
+
+	if _, err := bw.Write(data); err != nil {
+		return xerrors.Errorf("error writing Brotli data: %w", err)
+	}
+
+	if err := bw.Close(); err != nil {
+		return xerrors.Errorf("error closing Brotli writer: %w", err)
+	}
+
+	return nil
+}
+
+func compressGzip(w io.Writer, data []byte) error {
+	gw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return xerrors.Errorf("error creating gzip writer: %w", err)
+	}
+
+	if _, err := gw.Write(data); err != nil {
+		return xerrors.Errorf("error writing gzip data: %w", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return xerrors.Errorf("error closing gzip writer: %w", err)
+	}
+
+	return nil
+}
+
+func siblingsExist(source string) bool {
+	for _, ext := range []string{ExtBrotli, ExtGzip} {
+		if _, err := os.Stat(source + ext); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeSibling compresses data to target by way of a temporary file so that
+// a server never sees a partially written sibling. Siblings are served, so
+// they're readable by everyone like the files that they're compressed from.
+func writeSibling(target string, data []byte, compress func(io.Writer, []byte) error) error {
+	var buf bytes.Buffer
+	if err := compress(&buf, data); err != nil {
+		return xerrors.Errorf("error compressing '%s': %w", target, err)
+	}
+
+	if err := os.WriteFile(target+".tmp", buf.Bytes(), 0o644); err != nil { //nolint:gosec
+		return xerrors.Errorf("error writing '%s': %w", target, err)
+	}
+
+	if err := os.Rename(target+".tmp", target); err != nil {
+		return xerrors.Errorf("error renaming '%s': %w", target, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package scompress
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestManifestCompress(t *testing.T) {
+	dir := t.TempDir()
+	source := path.Join(dir, "index.html")
+	content := strings.Repeat("<p>Hello, world.</p>\n", 100)
+
+	assert.NoError(t, os.WriteFile(source, []byte(content), 0o600))
+
+	manifest, err := LoadManifest(path.Join(dir, "manifest.json"))
+	assert.NoError(t, err)
+
+	compressed, err := manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.True(t, compressed)
+
+	assert.Equal(t, content, readGzip(t, source+ExtGzip))
+	assert.Equal(t, content, readBrotli(t, source+ExtBrotli))
+
+	// Unchanged.
+	compressed, err = manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.False(t, compressed)
+
+	// Rewritten with the same content.
+	assert.NoError(t, os.WriteFile(source, []byte(content), 0o600))
+	assert.NoError(t, os.Chtimes(source, time.Now(), time.Now().Add(time.Minute)))
+	compressed, err = manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.False(t, compressed)
+
+	// A sibling went missing.
+	assert.NoError(t, os.Remove(source+ExtBrotli))
+	compressed, err = manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.True(t, compressed)
+	assert.Equal(t, content, readBrotli(t, source+ExtBrotli))
+
+	// Changed content.
+	newContent := strings.Repeat("<p>Goodbye, world.</p>\n", 100)
+	assert.NoError(t, os.WriteFile(source, []byte(newContent), 0o600))
+	assert.NoError(t, os.Chtimes(source, time.Now(), time.Now().Add(2*time.Minute)))
+	compressed, err = manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.True(t, compressed)
+	assert.Equal(t, newContent, readGzip(t, source+ExtGzip))
+	assert.Equal(t, newContent, readBrotli(t, source+ExtBrotli))
+
+	_, err = manifest.Compress(path.Join(dir, "missing.html"))
+	assert.ErrorContains(t, err, "error stating file")
+}
+
+func TestManifestSave(t *testing.T) {
+	dir := t.TempDir()
+	target := path.Join(dir, "nested", "manifest.json")
+
+	manifest, err := LoadManifest(target)
+	assert.NoError(t, err)
+	assert.Empty(t, manifest.Files)
+
+	source := path.Join(dir, "index.html")
+	assert.NoError(t, os.WriteFile(source, []byte(strings.Repeat("a", MinSize)), 0o600))
+
+	_, err = manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.NoError(t, manifest.Save(target))
+
+	manifest, err = LoadManifest(target)
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Files, 1)
+
+	// The loaded manifest knows that the file was already compressed.
+	compressed, err := manifest.Compress(source)
+	assert.NoError(t, err)
+	assert.False(t, compressed)
+}
+
+func readBrotli(t *testing.T, source string) string {
+	t.Helper()
+
+	f, err := os.Open(source)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	data, err := io.ReadAll(brotli.NewReader(f))
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func readGzip(t *testing.T, source string) string {
+	t.Helper()
+
+	f, err := os.Open(source)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(data)
+}
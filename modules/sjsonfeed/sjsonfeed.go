@@ -0,0 +1,77 @@
+package sjsonfeed
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Version is the JSON Feed version produced by this package.
+const Version = "https://jsonfeed.org/version/1.1"
+
+// Author is the author of a feed or item.
+type Author struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Feed represents a JSON Feed that will be marshaled to JSON.
+type Feed struct {
+	Version string `json:"version"`
+
+	Title       string    `json:"title"`
+	HomePageURL string    `json:"home_page_url,omitempty"`
+	FeedURL     string    `json:"feed_url,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Authors     []*Author `json:"authors,omitempty"`
+
+	// NextURL is the URL of a page of the feed containing older items, if
+	// there is one.
+	NextURL string `json:"next_url,omitempty"`
+
+	Items []*Item `json:"items"`
+}
+
+// Item is a single item in a JSON Feed.
+type Item struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	ContentHTML   string     `json:"content_html,omitempty"`
+	Summary       string     `json:"summary,omitempty"`
+	Image         string     `json:"image,omitempty"`
+	DatePublished *time.Time `json:"date_published,omitempty"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+	Authors       []*Author  `json:"authors,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+}
+
+// Encode the feed to an io.Writer.
+//
+// Fills in defaults for a few fields like version and language.
+func (f *Feed) Encode(w io.Writer, indent string) error {
+	if f.Version == "" {
+		f.Version = Version
+	}
+
+	if f.Language == "" {
+		f.Language = "en-US"
+	}
+
+	// The spec requires items, so make sure an empty feed doesn't encode it
+	// as null.
+	if f.Items == nil {
+		f.Items = []*Item{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(f); err != nil {
+		return xerrors.Errorf("error encoding JSON feed: %w", err)
+	}
+
+	return nil
+}
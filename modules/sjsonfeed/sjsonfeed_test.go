@@ -0,0 +1,53 @@
+package sjsonfeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFeedEncode(t *testing.T) {
+	publishedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	feed := &Feed{
+		Title:   "Articles",
+		FeedURL: "https://brandur.org/articles.json",
+		Items: []*Item{
+			{
+				ID:            "tag:brandur.org,2026-01-02:article",
+				ContentHTML:   "<p>Hello & welcome.</p>",
+				DatePublished: &publishedAt,
+				Tags:          []string{"postgres"},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, feed.Encode(&b, "  "))
+
+	// HTML isn't escaped into `<` and friends.
+	assert.Contains(t, b.String(), `"content_html": "<p>Hello & welcome.</p>"`)
+
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &decoded))
+	assert.Equal(t, Version, decoded["version"])
+	assert.Equal(t, "en-US", decoded["language"])
+	assert.Equal(t, "Articles", decoded["title"])
+	assert.NotContains(t, decoded, "home_page_url")
+
+	items := decoded["items"].([]any)
+	assert.Len(t, items, 1)
+	item := items[0].(map[string]any)
+	assert.Equal(t, "2026-01-02T03:04:05Z", item["date_published"])
+	assert.Equal(t, []any{"postgres"}, item["tags"])
+	assert.NotContains(t, item, "date_modified")
+}
+
+func TestFeedEncodeEmpty(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, (&Feed{Title: "Empty"}).Encode(&b, ""))
+	assert.Contains(t, b.String(), `"items":[]`)
+}
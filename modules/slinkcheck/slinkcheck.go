@@ -0,0 +1,296 @@
+// Package slinkcheck finds the links in HTML documents and checks external
+// ones over HTTP, with a cache of good results so that a site's many links
+// don't all have to be rechecked every time.
+package slinkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/xerrors"
+)
+
+// Document is the links in an HTML document, along with the anchors that
+// links to it may target with a fragment.
+type Document struct {
+	// IDs are the fragments that can be targeted in the document: every
+	// `id` attribute, and the `name` attribute of `<a>` elements.
+	IDs map[string]struct{}
+
+	// Links are the `href` attribute of every element that has one, in
+	// document order.
+	Links []string
+}
+
+// HasID returns true if the given fragment can be targeted in the document.
+// An empty fragment and `top` always can be because browsers treat them as
+// the top of the document.
+func (d *Document) HasID(id string) bool {
+	if id == "" || id == "top" {
+		return true
+	}
+
+	_, ok := d.IDs[id]
+	return ok
+}
+
+// Parse reads the links and anchors of an HTML document.
+func Parse(r io.Reader) (*Document, error) {
+	doc := &Document{IDs: make(map[string]struct{})}
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, xerrors.Errorf("error parsing HTML: %w", err)
+			}
+			return doc, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			isAnchor := atom.Lookup(name) == atom.A
+
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+
+				switch string(key) {
+				case "href":
+					doc.Links = append(doc.Links, string(val))
+				case "id":
+					doc.IDs[string(val)] = struct{}{}
+				case "name":
+					if isAnchor {
+						doc.IDs[string(val)] = struct{}{}
+					}
+				}
+			}
+
+		case html.CommentToken, html.DoctypeToken, html.EndTagToken, html.TextToken:
+		}
+	}
+}
+
+// Checker checks that external URLs resolve.
+type Checker struct {
+	// Cache is an optional cache of good results. URLs that were checked
+	// successfully within CacheTTL aren't checked again.
+	Cache *Cache
+
+	// CacheTTL is how long a good result is cached for.
+	CacheTTL time.Duration
+
+	// Concurrency is the maximum number of URLs checked at once. Defaults to
+	// 1 if not set.
+	Concurrency int
+
+	// HTTPClient is the client used to make requests. Defaults to
+	// http.DefaultClient if not set.
+	HTTPClient *http.Client
+
+	// UserAgent is sent with every request. Some servers refuse requests
+	// without one.
+	UserAgent string
+}
+
+// Check checks each of the given URLs, returning a result for every one of
+// them keyed by URL.
+func (c *Checker) Check(ctx context.Context, urls []string) map[string]*Result {
+	results := make(map[string]*Result, len(urls))
+
+	var toCheck []string
+	for _, u := range urls {
+		if _, ok := results[u]; ok {
+			continue
+		}
+
+		if c.Cache != nil && c.Cache.fresh(u, c.CacheTTL) {
+			results[u] = &Result{Cached: true}
+			continue
+		}
+
+		// Reserve the URL so that duplicates are skipped.
+		results[u] = nil
+		toCheck = append(toCheck, u)
+	}
+
+	var (
+		mu  sync.Mutex
+		sem = make(chan struct{}, max(c.Concurrency, 1))
+		wg  sync.WaitGroup
+	)
+
+	for _, u := range toCheck {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := c.check(ctx, u)
+			if !result.Broken() && c.Cache != nil {
+				c.Cache.put(u)
+			}
+
+			mu.Lock()
+			results[u] = result
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// check checks a single URL with a HEAD request, falling back to GET for
+// servers that don't allow HEAD.
+func (c *Checker) check(ctx context.Context, u string) *Result {
+	result := c.request(ctx, http.MethodHead, u)
+
+	if result.Err == nil && slices.Contains(headUnsupportedStatuses, result.StatusCode) {
+		result = c.request(ctx, http.MethodGet, u)
+	}
+
+	return result
+}
+
+func (c *Checker) request(ctx context.Context, method, u string) *Result {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return &Result{Err: xerrors.Errorf("error creating request: %w", err)}
+	}
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &Result{Err: err}
+	}
+	defer resp.Body.Close()
+
+	return &Result{StatusCode: resp.StatusCode}
+}
+
+// Result is the result of checking an external URL.
+type Result struct {
+	// Cached is true if the URL wasn't checked because it was checked
+	// successfully recently.
+	Cached bool
+
+	// Err is set if the URL couldn't be requested at all.
+	Err error
+
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+}
+
+// Broken returns true if the URL couldn't be requested or responded with an
+// error. Being rate limited isn't considered broken because it says nothing
+// about whether the URL exists.
+func (r *Result) Broken() bool {
+	if r.Cached {
+		return false
+	}
+
+	return r.Err != nil ||
+		(r.StatusCode >= 400 && r.StatusCode != http.StatusTooManyRequests)
+}
+
+// Cache is a cache of URLs that were checked successfully, which can be
+// persisted to disk between runs.
+type Cache struct {
+	// CheckedAt is when each URL was last checked successfully.
+	CheckedAt map[string]time.Time `json:"checked_at"`
+
+	mu sync.Mutex
+}
+
+// LoadCache loads a cache from the given path. If nothing's there yet, an
+// empty cache is returned.
+func LoadCache(source string) (*Cache, error) {
+	cache := &Cache{CheckedAt: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(source)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading link cache '%s': %w", source, err)
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling link cache '%s': %w", source, err)
+	}
+
+	if cache.CheckedAt == nil {
+		cache.CheckedAt = make(map[string]time.Time)
+	}
+
+	return cache, nil
+}
+
+// Save writes the cache to the given path.
+func (c *Cache) Save(target string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("error marshaling link cache: %w", err)
+	}
+
+	if err := os.MkdirAll(path.Dir(target), 0o755); err != nil {
+		return xerrors.Errorf("error creating directory for link cache: %w", err)
+	}
+
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return xerrors.Errorf("error writing link cache: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache) fresh(u string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	checkedAt, ok := c.CheckedAt[u]
+	return ok && time.Since(checkedAt) < ttl
+}
+
+func (c *Cache) put(u string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.CheckedAt[u] = time.Now()
+}
+
+// Statuses returned by servers that don't support HEAD, in which case a URL
+// is checked again with GET. Some return 403 or 404 instead of the more
+// accurate 405 or 501.
+var headUnsupportedStatuses = []int{
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusMethodNotAllowed,
+	http.StatusNotImplemented,
+}
@@ -0,0 +1,135 @@
+package slinkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestDocumentHasID(t *testing.T) {
+	doc := &Document{IDs: map[string]struct{}{"intro": {}}}
+
+	assert.True(t, doc.HasID(""))
+	assert.True(t, doc.HasID("intro"))
+	assert.True(t, doc.HasID("top"))
+	assert.False(t, doc.HasID("conclusion"))
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`<!DOCTYPE html>
+<html>
+<head>
+    <link href="/assets/main.css" rel="stylesheet">
+</head>
+<body>
+    <h2 id="intro">Intro</h2>
+    <a name="old-anchor"></a>
+    <div name="not-an-anchor"></div>
+    <p>See <a href="/articles">articles</a> and <a href="#intro">the intro</a>.</p>
+    <img src="/image.jpg" />
+</body>
+</html>`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"/assets/main.css", "/articles", "#intro"}, doc.Links)
+	assert.Equal(t, map[string]struct{}{"intro": {}, "old-anchor": {}}, doc.IDs)
+}
+
+func TestCheckerCheck(t *testing.T) {
+	var numRequests atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests.Add(1)
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/rate-limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cache, err := LoadCache(path.Join(t.TempDir(), "cache.json"))
+	assert.NoError(t, err)
+
+	checker := &Checker{
+		Cache:       cache,
+		CacheTTL:    time.Hour,
+		Concurrency: 2,
+	}
+
+	results := checker.Check(context.Background(), []string{
+		server.URL + "/ok",
+		server.URL + "/ok",
+		server.URL + "/no-head",
+		server.URL + "/missing",
+		server.URL + "/rate-limited",
+	})
+	assert.Len(t, results, 4)
+
+	assert.False(t, results[server.URL+"/ok"].Broken())
+	assert.False(t, results[server.URL+"/no-head"].Broken())
+	assert.False(t, results[server.URL+"/rate-limited"].Broken())
+
+	assert.True(t, results[server.URL+"/missing"].Broken())
+	assert.Equal(t, http.StatusNotFound, results[server.URL+"/missing"].StatusCode)
+
+	// The duplicate wasn't checked twice, and URLs that don't support HEAD
+	// (or might not) were retried with GET.
+	assert.Equal(t, int64(6), numRequests.Load())
+
+	// Only good results are cached, so on a second check they're not
+	// requested again.
+	numRequests.Store(0)
+	results = checker.Check(context.Background(), []string{
+		server.URL + "/ok",
+		server.URL + "/missing",
+	})
+	assert.True(t, results[server.URL+"/ok"].Cached)
+	assert.True(t, results[server.URL+"/missing"].Broken())
+	assert.Equal(t, int64(2), numRequests.Load())
+}
+
+func TestCheckerCheckError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	results := (&Checker{}).Check(context.Background(), []string{server.URL})
+	assert.Error(t, results[server.URL].Err)
+	assert.True(t, results[server.URL].Broken())
+}
+
+func TestCache(t *testing.T) {
+	source := path.Join(t.TempDir(), "nested", "cache.json")
+
+	cache, err := LoadCache(source)
+	assert.NoError(t, err)
+	assert.Empty(t, cache.CheckedAt)
+
+	cache.put("https://example.com")
+	assert.True(t, cache.fresh("https://example.com", time.Hour))
+	assert.False(t, cache.fresh("https://example.com", 0))
+	assert.False(t, cache.fresh("https://example.org", time.Hour))
+
+	assert.NoError(t, cache.Save(source))
+
+	cache, err = LoadCache(source)
+	assert.NoError(t, err)
+	assert.True(t, cache.fresh("https://example.com", time.Hour))
+}
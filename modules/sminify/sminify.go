@@ -0,0 +1,223 @@
+// Package sminify minifies rendered HTML and Atom documents, keeping track of
+// how many bytes were saved across each section of the site.
+package sminify
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+	"github.com/tdewolff/minify/v2/json"
+	"github.com/tdewolff/minify/v2/svg"
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/xml"
+	"golang.org/x/xerrors"
+)
+
+// Media types of documents that can be minified.
+const (
+	MediaTypeAtom = "application/atom+xml"
+	MediaTypeHTML = "text/html"
+)
+
+// Minifier minifies documents and records the bytes saved by doing so. It's
+// safe for concurrent use.
+type Minifier struct {
+	m *minify.M
+
+	stats   map[string]*SectionStats
+	statsMu sync.Mutex
+}
+
+// New initializes a new Minifier.
+//
+// HTML has its whitespace collapsed and unnecessary attribute quotes removed,
+// along with any inline CSS, JavaScript, JSON, and SVG minified. Document and
+// end tags are kept even where they're optional to keep output easy to read
+// when debugging.
+//
+// Atom feeds only lose the whitespace between elements (see minifyAtom).
+func New() *Minifier {
+	m := minify.New()
+	m.Add(MediaTypeHTML, &html.Minifier{
+		KeepDocumentTags: true,
+		KeepEndTags:      true,
+	})
+	m.AddFunc(MediaTypeAtom, minifyAtom)
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("image/svg+xml", svg.Minify)
+	m.AddFuncRegexp(jsMediaTypeRE, js.Minify)
+	m.AddFuncRegexp(jsonMediaTypeRE, json.Minify)
+
+	return &Minifier{
+		m:     m,
+		stats: make(map[string]*SectionStats),
+	}
+}
+
+// Bytes minifies a document of the given media type, recording the bytes
+// saved under section.
+func (m *Minifier) Bytes(mediaType, section string, data []byte) ([]byte, error) {
+	minified, err := m.m.Bytes(mediaType, data)
+	if err != nil {
+		return nil, xerrors.Errorf("error minifying %s: %w", mediaType, err)
+	}
+
+	m.record(section, len(data), len(minified))
+	return minified, nil
+}
+
+// Reset clears recorded stats, like between build loops.
+func (m *Minifier) Reset() {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	m.stats = make(map[string]*SectionStats)
+}
+
+// Stats returns the stats recorded for each section, sorted by bytes saved
+// with the most first.
+func (m *Minifier) Stats() []*SectionStats {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	stats := make([]*SectionStats, 0, len(m.stats))
+	for _, sectionStats := range m.stats {
+		statsCopy := *sectionStats
+		stats = append(stats, &statsCopy)
+	}
+
+	slices.SortFunc(stats, func(a, b *SectionStats) int {
+		if a.Saved() != b.Saved() {
+			return int(b.Saved() - a.Saved())
+		}
+		return strings.Compare(a.Section, b.Section)
+	})
+
+	return stats
+}
+
+// Writer returns a writer that minifies a document of the given media type
+// that's written to it, writing the result to w and recording the bytes saved
+// under section. The document is buffered until the writer is closed, which
+// must be checked for an error.
+func (m *Minifier) Writer(w io.Writer, mediaType, section string) io.WriteCloser {
+	return &writer{m: m, mediaType: mediaType, section: section, w: w}
+}
+
+func (m *Minifier) record(section string, bytesIn, bytesOut int) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	sectionStats, ok := m.stats[section]
+	if !ok {
+		sectionStats = &SectionStats{Section: section}
+		m.stats[section] = sectionStats
+	}
+
+	sectionStats.BytesIn += int64(bytesIn)
+	sectionStats.BytesOut += int64(bytesOut)
+	sectionStats.NumDocuments++
+}
+
+// SectionStats are the effects of minification on a section of the site.
+type SectionStats struct {
+	// BytesIn is the total size of documents before minification.
+	BytesIn int64
+
+	// BytesOut is the total size of documents after minification.
+	BytesOut int64
+
+	// NumDocuments is the number of documents minified.
+	NumDocuments int
+
+	// Section is the section of the site, like `twitter`.
+	Section string
+}
+
+// Saved is the number of bytes saved by minification.
+func (s *SectionStats) Saved() int64 {
+	return s.BytesIn - s.BytesOut
+}
+
+// SavedPercent is the percentage of bytes saved by minification.
+func (s *SectionStats) SavedPercent() float64 {
+	if s.BytesIn == 0 {
+		return 0
+	}
+
+	return float64(s.Saved()) / float64(s.BytesIn) * 100
+}
+
+// Media types of inline scripts, like `<script type="application/ld+json">`.
+var (
+	jsMediaTypeRE   = regexp.MustCompile(`^(application|text)/(x-)?(java|ecma)script$`)
+	jsonMediaTypeRE = regexp.MustCompile(`[/+]json$`)
+)
+
+// minifyAtom removes whitespace between the elements of an Atom document,
+// leaving everything else as it was.
+//
+// The general purpose XML minifier isn't used because it collapses
+// whitespace in text, and entry content is escaped HTML that may contain
+// preformatted code whose indentation has to be kept.
+func minifyAtom(_ *minify.M, w io.Writer, r io.Reader, _ map[string]string) error {
+	l := xml.NewLexer(parse.NewInput(r))
+
+	for {
+		tt, data := l.Next()
+
+		switch tt {
+		case xml.ErrorToken:
+			if err := l.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+			return nil
+
+		case xml.TextToken:
+			if len(bytes.TrimSpace(data)) == 0 {
+				continue
+			}
+
+		case xml.AttributeToken, xml.CDATAToken, xml.CommentToken, xml.DOCTYPEToken, xml.EndTagToken, xml.StartTagCloseToken,
+			xml.StartTagClosePIToken, xml.StartTagCloseVoidToken, xml.StartTagPIToken, xml.StartTagToken:
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+}
+
+type writer struct {
+	buf       bytes.Buffer
+	m         *Minifier
+	mediaType string
+	section   string
+	w         io.Writer
+}
+
+func (w *writer) Close() error {
+	minified, err := w.m.Bytes(w.mediaType, w.section, w.buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.w.Write(minified); err != nil {
+		return xerrors.Errorf("error writing minified %s: %w", w.mediaType, err)
+	}
+
+	return nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
@@ -0,0 +1,117 @@
+package sminify
+
+import (
+	"bytes"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestMinifierBytesAtom(t *testing.T) {
+	m := New()
+
+	minified, err := m.Bytes(MediaTypeAtom, "feeds", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>brandur.org</title>
+  <link href="https://brandur.org/articles" rel="alternate"></link>
+  <entry>
+    <content type="html">&lt;pre&gt;&lt;code&gt;func main() {
+    fmt.Println(&#34;hello&#34;)
+}&lt;/code&gt;&lt;/pre&gt;</content>
+  </entry>
+</feed>`))
+	assert.NoError(t, err)
+
+	// Whitespace between elements is removed, but the indentation of the
+	// preformatted code in entry content is kept.
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<feed xmlns="http://www.w3.org/2005/Atom">`+
+		`<title>brandur.org</title>`+
+		`<link href="https://brandur.org/articles" rel="alternate"></link>`+
+		`<entry><content type="html">&lt;pre&gt;&lt;code&gt;func main() {
+    fmt.Println(&#34;hello&#34;)
+}&lt;/code&gt;&lt;/pre&gt;</content></entry>`+
+		`</feed>`, string(minified))
+}
+
+func TestMinifierBytesHTML(t *testing.T) {
+	m := New()
+
+	minified, err := m.Bytes(MediaTypeHTML, "articles", []byte(`<!DOCTYPE html>
+<html lang="en">
+    <head>
+        <style>
+            body { color: black; }
+        </style>
+        <script type="text/javascript">
+            var sum = 1 + 2;
+        </script>
+        <script type="application/ld+json">
+            { "name": "brandur" }
+        </script>
+    </head>
+    <body>
+        <p class="intro">Hello  <em>world</em>.</p>
+        <pre>func main() {
+    fmt.Println("hello")
+}</pre>
+    </body>
+</html>`))
+	assert.NoError(t, err)
+	assert.Equal(t, `<!doctype html><html lang=en><head>`+
+		`<style>body{color:#000}</style>`+
+		`<script>var sum=1+2</script>`+
+		`<script type=application/ld+json>{"name":"brandur"}</script>`+
+		`</head><body><p class=intro>Hello <em>world</em>.</p><pre>func main() {
+    fmt.Println("hello")
+}</pre></body></html>`, string(minified))
+}
+
+func TestMinifierBytesError(t *testing.T) {
+	m := New()
+
+	_, err := m.Bytes(MediaTypeHTML, "articles", []byte(`<script>var x = ;</script>`))
+	assert.ErrorContains(t, err, "error minifying text/html")
+	assert.Empty(t, m.Stats())
+}
+
+func TestMinifierStats(t *testing.T) {
+	m := New()
+
+	_, err := m.Bytes(MediaTypeHTML, "articles", []byte("<p>  a  </p>"))
+	assert.NoError(t, err)
+	_, err = m.Bytes(MediaTypeHTML, "articles", []byte("<p>  b  </p>"))
+	assert.NoError(t, err)
+	_, err = m.Bytes(MediaTypeHTML, "twitter", []byte("<p>          c          </p>"))
+	assert.NoError(t, err)
+
+	stats := m.Stats()
+	assert.Equal(t, []*SectionStats{
+		{BytesIn: 28, BytesOut: 8, NumDocuments: 1, Section: "twitter"},
+		{BytesIn: 24, BytesOut: 16, NumDocuments: 2, Section: "articles"},
+	}, stats)
+	assert.Equal(t, int64(20), stats[0].Saved())
+	assert.InDelta(t, 71.4, stats[0].SavedPercent(), 0.1)
+
+	m.Reset()
+	assert.Empty(t, m.Stats())
+}
+
+func TestMinifierWriter(t *testing.T) {
+	m := New()
+
+	var buf bytes.Buffer
+	w := m.Writer(&buf, MediaTypeHTML, "articles")
+
+	_, err := w.Write([]byte("<p>  Hello,  "))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("world.  </p>"))
+	assert.NoError(t, err)
+
+	// Nothing is written until the writer is closed.
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "<p>Hello, world.</p>", buf.String())
+	assert.Equal(t, 1, m.Stats()[0].NumDocuments)
+}
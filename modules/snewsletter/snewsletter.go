@@ -13,6 +13,8 @@ import (
 	"github.com/brandur/modulir/modules/mtemplate"
 	"github.com/brandur/modulir/modules/mtoml"
 	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/splaintext"
+	"github.com/brandur/sorg/modules/sstats"
 )
 
 // Possible orientations for a newsletter's main image.
@@ -24,6 +26,10 @@ const (
 // Issue represents a single burst of the Nanoglyph or Passages & Glass
 // newsletters to be rendered.
 type Issue struct {
+	// Aliases are old paths that the issue used to live at, like
+	// `/nanoglyphs/old-slug`, which redirect to it.
+	Aliases []string `toml:"aliases,omitempty"`
+
 	// Content is the HTML content of the issue. It isn't included as TOML
 	// frontmatter, and is rather split out of an issue's Markdown file,
 	// rendered, and then added separately.
@@ -32,6 +38,10 @@ type Issue struct {
 	// ContentRaw is the raw Markdown content of the issue.
 	ContentRaw string `toml:"-"`
 
+	// ContentText is a plain text rendering of Content suitable for the text
+	// part of an email. Only set when an issue is rendered for email.
+	ContentText string `toml:"-"`
+
 	// Draft indicates that the issue is not yet published.
 	Draft bool `toml:"-"`
 
@@ -71,6 +81,15 @@ type Issue struct {
 	// (like `001` and a short identifier).
 	Slug string `toml:"-"`
 
+	// Stats are statistics about the issue's rendered content like its word
+	// count. Only set once the issue is rendered.
+	Stats *sstats.Stats `toml:"-"`
+
+	// Tags are the set of tags that the issue is tagged with. They're plain
+	// strings here, but are checked against the same allowlist as the tags
+	// of other content.
+	Tags []string `toml:"tags,omitempty"`
+
 	// Title is the issue's title.
 	Title string `toml:"title"`
 }
@@ -98,12 +117,11 @@ func (p *Issue) validate(source string) error {
 	return nil
 }
 
-// Render reads a newsletter file and builds an Issue object from it.
-//
-// The email parameter specifies whether or not the issue is being rendered
-// to be sent it an email (as opposed for rendering on the web) and affects
-// things like whether images should use absolute URLs.
-func Render(c *modulir.Context, dir, name, absoluteURL string, email bool) (*Issue, error) {
+// Parse reads a newsletter file's frontmatter and validates it, producing an
+// Issue object with all its metadata, but without rendering its content. This
+// is useful for tasks like validation or send scheduling that don't need the
+// rendered HTML.
+func Parse(c *modulir.Context, dir, name string) (*Issue, error) {
 	source := path.Join(dir, name)
 
 	var issue Issue
@@ -137,6 +155,20 @@ func Render(c *modulir.Context, dir, name, absoluteURL string, email bool) (*Iss
 		return nil, err
 	}
 
+	return &issue, nil
+}
+
+// Render reads a newsletter file and builds an Issue object from it.
+//
+// The email parameter specifies whether or not the issue is being rendered
+// to be sent it an email (as opposed for rendering on the web) and affects
+// things like whether images should use absolute URLs.
+func Render(c *modulir.Context, dir, name, absoluteURL string, email bool) (*Issue, error) {
+	issue, err := Parse(c, dir, name)
+	if err != nil {
+		return nil, err
+	}
+
 	content, err := mmarkdownext.Render(issue.ContentRaw, &mmarkdownext.RenderOptions{
 		AbsoluteURL:     absoluteURL,
 		NoFootnoteLinks: email,
@@ -155,6 +187,14 @@ func Render(c *modulir.Context, dir, name, absoluteURL string, email bool) (*Iss
 	}
 
 	issue.Content = template.HTML(content)
+	issue.Stats = sstats.Compute(content)
 
-	return &issue, nil
+	if email {
+		issue.ContentText, err = splaintext.Render(content, absoluteURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return issue, nil
 }
@@ -0,0 +1,479 @@
+package splaintext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/xerrors"
+)
+
+// Width is the column at which plain text is wrapped.
+const Width = 72
+
+// Render renders HTML content as plain text suitable for the text/plain
+// alternative of an email. Text is wrapped to Width columns, links are turned
+// into numbered references listed at the end, images are replaced by their
+// alt text, and footnotes are moved to the end as well.
+//
+// absoluteURL is used to make any relative links absolute.
+func Render(content, absoluteURL string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("error parsing HTML: %w", err)
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+
+	r := &renderer{
+		absoluteURL: strings.TrimSuffix(absoluteURL, "/"),
+		linkNumbers: make(map[string]int),
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(r.renderBlocks(root, Width), "\n\n"))
+
+	if len(r.footnotes) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(strings.Repeat("-", Width))
+		b.WriteString("\n\n")
+		b.WriteString(strings.Join(r.footnotes, "\n\n"))
+	}
+
+	if len(r.links) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(strings.Repeat("-", Width))
+		b.WriteString("\n\n")
+		for i, link := range r.links {
+			b.WriteString("[" + strconv.Itoa(i+1) + "] " + link + "\n")
+		}
+	}
+
+	return strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// Text reduces HTML content to a single line of plain text without any of
+// Render's formatting, like for a short summary or a search index. Tags are
+// dropped (along with the contents of ones like `<script>`) and whitespace is
+// collapsed. Block elements and line breaks separate words, but inline
+// elements don't, so `foo<em>bar</em>` is "foobar".
+func Text(content string) string {
+	var (
+		b       strings.Builder
+		ignored int
+		z       = html.NewTokenizer(strings.NewReader(content))
+	)
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return collapseWhitespace(b.String())
+
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if ignoredElements[atom.Lookup(name)] {
+				ignored++
+			}
+			writeSeparator(&b, name)
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if ignoredElements[atom.Lookup(name)] && ignored > 0 {
+				ignored--
+			}
+			writeSeparator(&b, name)
+
+		case html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			writeSeparator(&b, name)
+
+		case html.TextToken:
+			if ignored == 0 {
+				b.Write(z.Text())
+			}
+
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Elements that start a new block of text rather than continuing the current
+// one.
+var blockElements = map[atom.Atom]bool{
+	atom.Blockquote: true,
+	atom.Div:        true,
+	atom.Figcaption: true,
+	atom.Figure:     true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Hr:         true,
+	atom.Li:         true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Ul:         true,
+}
+
+// Elements other than block elements that separate words in Text. Inline
+// elements like `<em>` can start or end in the middle of a word.
+var separatingElements = map[atom.Atom]bool{
+	atom.Br:    true,
+	atom.Dd:    true,
+	atom.Dl:    true,
+	atom.Dt:    true,
+	atom.Table: true,
+	atom.Td:    true,
+	atom.Th:    true,
+	atom.Tr:    true,
+}
+
+// Elements that are dropped along with all their content.
+var ignoredElements = map[atom.Atom]bool{
+	atom.Head:   true,
+	atom.Script: true,
+	atom.Style:  true,
+}
+
+var (
+	footnoteNumberRE = regexp.MustCompile(`^\d+$`)
+	whitespaceRE     = regexp.MustCompile(`\s+`)
+)
+
+type renderer struct {
+	absoluteURL string
+
+	// footnotes are rendered footnotes, to be listed at the end.
+	footnotes []string
+
+	// inFootnotes is set while rendering the footnotes section.
+	inFootnotes bool
+
+	// linkNumbers maps link URLs to their reference number so that a URL
+	// that's linked multiple times gets a single reference.
+	linkNumbers map[string]int
+
+	// links are link URLs in order of their reference number.
+	links []string
+}
+
+// renderBlocks renders the children of a node as a series of blocks of text,
+// each wrapped to the given width.
+func (r *renderer) renderBlocks(node *html.Node, width int) []string {
+	var (
+		blocks []string
+		inline strings.Builder
+	)
+
+	flushInline := func() {
+		if text := collapseWhitespace(inline.String()); text != "" {
+			blocks = append(blocks, wrap(text, width))
+		}
+		inline.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || !blockElements[child.DataAtom] {
+			r.renderInline(child, &inline)
+			continue
+		}
+
+		flushInline()
+		blocks = append(blocks, r.renderBlock(child, width)...)
+	}
+
+	flushInline()
+
+	return blocks
+}
+
+// renderBlock renders a single block element to zero or more blocks of text.
+func (r *renderer) renderBlock(node *html.Node, width int) []string {
+	switch node.DataAtom {
+	case atom.Blockquote:
+		return []string{prefixLines(strings.Join(r.renderBlocks(node, width-2), "\n\n"), "> ", "> ")}
+
+	case atom.Div:
+		if hasClass(node, "footnotes") {
+			r.inFootnotes = true
+			r.footnotes = append(r.footnotes, r.renderBlocks(node, width)...)
+			r.inFootnotes = false
+			return nil
+		}
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		title := wrap(collapseWhitespace(r.renderInlineString(node)), width)
+		if title == "" {
+			return nil
+		}
+
+		underline := "-"
+		if node.DataAtom == atom.H1 || node.DataAtom == atom.H2 {
+			underline = "="
+		}
+
+		return []string{title + "\n" + strings.Repeat(underline, longestLine(title))}
+
+	case atom.Hr:
+		return []string{strings.Repeat("-", width)}
+
+	case atom.Ol, atom.Ul:
+		var items []string
+		number := 1
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom != atom.Li {
+				continue
+			}
+
+			marker := "* "
+			if node.DataAtom == atom.Ol {
+				marker = strconv.Itoa(number) + ". "
+				number++
+			}
+
+			indent := strings.Repeat(" ", len(marker))
+			content := strings.Join(r.renderBlocks(child, width-len(marker)), "\n\n")
+			items = append(items, prefixLines(content, marker, indent))
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+		return []string{strings.Join(items, "\n")}
+
+	case atom.Pre:
+		// Preformatted text is indented rather than wrapped so that code
+		// stays intact.
+		text := strings.Trim(textContent(node), "\n")
+		if text == "" {
+			return nil
+		}
+		return []string{prefixLines(text, "    ", "    ")}
+	}
+
+	return r.renderBlocks(node, width)
+}
+
+// renderInline renders an inline node and its children to a builder. Text is
+// written as is, and whitespace is collapsed later.
+func (r *renderer) renderInline(node *html.Node, b *strings.Builder) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(node.Data)
+		return
+
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if ignoredElements[node.DataAtom] {
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.A:
+		text := r.renderInlineString(node)
+		b.WriteString(text)
+
+		href := r.resolveURL(attr(node, "href"))
+		if href == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+
+		// Don't bother numbering a link whose text is already its URL.
+		trimmedText := strings.TrimSpace(text)
+		if trimmedText == href || "mailto:"+trimmedText == href {
+			return
+		}
+
+		b.WriteString(" [" + strconv.Itoa(r.linkNumber(href)) + "]")
+		return
+
+	case atom.Br:
+		// Line breaks don't survive wrapping, so a break is treated as a
+		// space.
+		b.WriteString(" ")
+		return
+
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(node, "alt")); alt != "" {
+			b.WriteString(" [Image: " + alt + "] ")
+		}
+		return
+
+	case atom.Sup:
+		text := strings.TrimSpace(r.renderInlineString(node))
+		if footnoteNumberRE.MatchString(text) {
+			b.WriteString("[^" + text + "]")
+			if r.inFootnotes {
+				b.WriteString(" ")
+			}
+			return
+		}
+
+		b.WriteString("^" + text)
+		return
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockElements[child.DataAtom] {
+			// A block nested inside an inline element (which is invalid, but
+			// possible). Flatten it.
+			b.WriteString(" " + strings.Join(r.renderBlocks(child, Width), " ") + " ")
+			continue
+		}
+		r.renderInline(child, b)
+	}
+}
+
+func (r *renderer) renderInlineString(node *html.Node) string {
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		r.renderInline(child, &b)
+	}
+	return b.String()
+}
+
+// linkNumber returns the reference number for a link URL, assigning a new
+// one if it hasn't been seen before.
+func (r *renderer) linkNumber(href string) int {
+	if number, ok := r.linkNumbers[href]; ok {
+		return number
+	}
+
+	r.links = append(r.links, href)
+	r.linkNumbers[href] = len(r.links)
+	return len(r.links)
+}
+
+func (r *renderer) resolveURL(href string) string {
+	href = strings.TrimSpace(href)
+	if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
+		return r.absoluteURL + href
+	}
+	return href
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func collapseWhitespace(s string) string {
+	return strings.TrimSpace(whitespaceRE.ReplaceAllString(s, " "))
+}
+
+func hasClass(node *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(node, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func longestLine(s string) int {
+	var longest int
+	for _, line := range strings.Split(s, "\n") {
+		longest = max(longest, utf8.RuneCountInString(line))
+	}
+	return longest
+}
+
+// prefixLines prefixes the first line of s with first and every subsequent
+// line with rest. Prefixes are trimmed of trailing space on empty lines.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// writeSeparator leaves a space in place of a tag if the element it's for
+// separates words (e.g. between paragraphs). Extra whitespace is collapsed
+// later.
+func writeSeparator(b *strings.Builder, name []byte) {
+	a := atom.Lookup(name)
+	if blockElements[a] || separatingElements[a] || ignoredElements[a] {
+		b.WriteByte(' ')
+	}
+}
+
+// wrap wraps text that's already had its whitespace collapsed to the given
+// width. Words longer than the width (like long URLs) are put on their own
+// line rather than broken.
+func wrap(s string, width int) string {
+	var (
+		b         strings.Builder
+		lineWidth int
+	)
+
+	for _, word := range strings.Fields(s) {
+		wordWidth := utf8.RuneCountInString(word)
+
+		switch {
+		case lineWidth == 0:
+		case lineWidth+1+wordWidth > width:
+			b.WriteString("\n")
+			lineWidth = 0
+		default:
+			b.WriteString(" ")
+			lineWidth++
+		}
+
+		b.WriteString(word)
+		lineWidth += wordWidth
+	}
+
+	return b.String()
+}
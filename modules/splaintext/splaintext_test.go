@@ -0,0 +1,125 @@
+package splaintext
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	content := strings.TrimSpace(`
+<p>An issue about <a href="https://example.com/a">one thing</a> and
+<a href="/articles/b">another</a>, plus <a href="https://example.com/a">one
+thing</a> again. <a href="https://example.com/c">https://example.com/c</a>
+is its own text.<sup><strong>1</strong></sup></p>
+
+<h2>A header</h2>
+
+<p><img src="https://example.com/photo.jpg" alt="A photo"></p>
+<p><img src="https://example.com/decoration.jpg"></p>
+
+<blockquote>
+<p>A quotation.</p>
+</blockquote>
+
+<ul>
+<li>First</li>
+<li>Second</li>
+</ul>
+
+<ol>
+<li><p>One</p></li>
+<li><p>Two</p></li>
+</ol>
+
+<pre><code>func main() {
+	fmt.Println("hello")
+}
+</code></pre>
+
+<hr />
+
+<p>Until next week.</p>
+
+<div class="footnotes">
+  <p><sup><strong>1</strong></sup><em>A</em> footnote.</p>
+</div>
+`)
+
+	expected := strings.TrimLeft(`
+An issue about one thing [1] and another [2], plus one thing [1] again.
+https://example.com/c is its own text.[^1]
+
+A header
+========
+
+[Image: A photo]
+
+> A quotation.
+
+* First
+* Second
+
+1. One
+2. Two
+
+    func main() {
+    	fmt.Println("hello")
+    }
+
+------------------------------------------------------------------------
+
+Until next week.
+
+------------------------------------------------------------------------
+
+[^1] A footnote.
+
+------------------------------------------------------------------------
+
+[1] https://example.com/a
+[2] https://brandur.org/articles/b
+`, "\n")
+
+	text, err := Render(content, "https://brandur.org/")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, text)
+}
+
+func TestRenderEmpty(t *testing.T) {
+	text, err := Render("", "https://brandur.org")
+	assert.NoError(t, err)
+	assert.Equal(t, "\n", text)
+}
+
+func TestPrefixLines(t *testing.T) {
+	assert.Equal(t, "> a\n>\n> b", prefixLines("a\n\nb", "> ", "> "))
+	assert.Equal(t, "1. a\n   b", prefixLines("a\nb", "1. ", "   "))
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, "", wrap("", 10))
+	assert.Equal(t, "a b c", wrap("a b c", 10))
+	assert.Equal(t, "aaaa bbbb\ncccc", wrap("aaaa bbbb cccc", 10))
+
+	// Width is measured in characters rather than bytes.
+	assert.Equal(t, "ąąąą ąąąą\nąąąą", wrap("ąąąą ąąąą ąąąą", 10))
+
+	// Words longer than the width go on their own line.
+	assert.Equal(t, "a\nbbbbbbbbbbbb\nc", wrap("a bbbbbbbbbbbb c", 10))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "", Text(""))
+	assert.Equal(t, "Hello, world.", Text("Hello, world."))
+	assert.Equal(t, "A hook with a link & an entity.",
+		Text(`A hook with <a href="https://example.com">a link</a> &amp; an entity.`))
+	assert.Equal(t, "One. Two.", Text("<p>One.</p>\n\n<p>Two.</p>"))
+	assert.Equal(t, "Line one. Line two.", Text("Line one.<br/>Line two."))
+	assert.Equal(t, "Before. After.", Text("<p>Before.</p><script>var a = 1;</script><style>p {}</style><p>After.</p>"))
+
+	// Inline markup inside a word doesn't split it.
+	assert.Equal(t, "foobar and unbreakable", Text("foo<em>bar</em> and un<strong>break</strong>able"))
+	assert.Equal(t, "Cell one Cell two", Text("<table><tr><td>Cell one</td><td>Cell two</td></tr></table>"))
+}
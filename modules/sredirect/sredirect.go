@@ -0,0 +1,98 @@
+// Package sredirect renders redirects from old paths on a site to new ones,
+// both as stub pages that redirect with a meta refresh (which work on any
+// static host) and as configuration for servers that can send a real HTTP
+// redirect.
+package sredirect
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// Redirect is a redirect from an old path on the site to a new location.
+type Redirect struct {
+	// From is the old path, like `/glass`.
+	From string `toml:"from" validate:"required,startswith=/"`
+
+	// To is where From now lives, either a path on the site like
+	// `/newsletter` or an absolute URL.
+	To string `toml:"to" validate:"required"`
+}
+
+// HasStub returns true if a stub page should be written for the redirect.
+// That's only the case for redirects from pages because a stub is HTML, and
+// HTML served in place of something like an Atom feed wouldn't do any good.
+func (r *Redirect) HasStub() bool {
+	ext := path.Ext(r.From)
+	return ext == "" || ext == ".html"
+}
+
+// WriteNetlify writes redirects in the format of a Netlify `_redirects` file.
+func WriteNetlify(w io.Writer, redirects []*Redirect) error {
+	for _, redirect := range redirects {
+		if _, err := fmt.Fprintf(w, "%s %s 301\n", redirect.From, redirect.To); err != nil {
+			return xerrors.Errorf("error writing Netlify redirect: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteNginx writes redirects as Nginx `location` blocks suitable for
+// inclusion in a `server` block.
+func WriteNginx(w io.Writer, redirects []*Redirect) error {
+	for _, redirect := range redirects {
+		if _, err := fmt.Fprintf(w, "location = %s { return 301 %s; }\n", redirect.From, redirect.To); err != nil {
+			return xerrors.Errorf("error writing Nginx redirect: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteS3 writes redirects as lines of an S3 object key followed by its
+// redirect location, separated by a space. Each is meant to be set with
+// `aws s3api put-object --key <key> --website-redirect-location <location>`.
+func WriteS3(w io.Writer, redirects []*Redirect) error {
+	for _, redirect := range redirects {
+		if _, err := fmt.Fprintf(w, "%s %s\n", redirect.From[1:], redirect.To); err != nil {
+			return xerrors.Errorf("error writing S3 redirect: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteStub writes a stub page that redirects to the given location with a
+// meta refresh. canonicalURL is the absolute URL of the location, which
+// search engines use in place of the stub.
+func WriteStub(w io.Writer, to, canonicalURL string) error {
+	err := stubTemplate.Execute(w, map[string]string{
+		"CanonicalURL": canonicalURL,
+		"To":           to,
+	})
+	if err != nil {
+		return xerrors.Errorf("error writing redirect stub: %w", err)
+	}
+
+	return nil
+}
+
+var stubTemplate = template.Must(template.New("stub").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <title>Redirecting&hellip;</title>
+    <link rel="canonical" href="{{.CanonicalURL}}">
+    <meta name="robots" content="noindex">
+    <meta http-equiv="refresh" content="0; url={{.To}}">
+</head>
+<body>
+    <p>This page has moved to <a href="{{.To}}">{{.To}}</a>.</p>
+</body>
+</html>
+`))
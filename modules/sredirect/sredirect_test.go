@@ -0,0 +1,52 @@
+package sredirect
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+var testRedirects = []*Redirect{
+	{From: "/glass", To: "/newsletter"},
+	{From: "/sequences/berlin.atom", To: "https://brandur.org/sequences.atom"},
+}
+
+func TestRedirectHasStub(t *testing.T) {
+	assert.True(t, (&Redirect{From: "/glass"}).HasStub())
+	assert.True(t, (&Redirect{From: "/sequences/berlin/001"}).HasStub())
+	assert.True(t, (&Redirect{From: "/about.html"}).HasStub())
+	assert.False(t, (&Redirect{From: "/sequences/berlin.atom"}).HasStub())
+}
+
+func TestWriteNetlify(t *testing.T) {
+	var sb strings.Builder
+	assert.NoError(t, WriteNetlify(&sb, testRedirects))
+	assert.Equal(t, `/glass /newsletter 301
+/sequences/berlin.atom https://brandur.org/sequences.atom 301
+`, sb.String())
+}
+
+func TestWriteNginx(t *testing.T) {
+	var sb strings.Builder
+	assert.NoError(t, WriteNginx(&sb, testRedirects))
+	assert.Equal(t, `location = /glass { return 301 /newsletter; }
+location = /sequences/berlin.atom { return 301 https://brandur.org/sequences.atom; }
+`, sb.String())
+}
+
+func TestWriteS3(t *testing.T) {
+	var sb strings.Builder
+	assert.NoError(t, WriteS3(&sb, testRedirects))
+	assert.Equal(t, `glass /newsletter
+sequences/berlin.atom https://brandur.org/sequences.atom
+`, sb.String())
+}
+
+func TestWriteStub(t *testing.T) {
+	var sb strings.Builder
+	assert.NoError(t, WriteStub(&sb, "/newsletter", "https://brandur.org/newsletter"))
+	assert.Contains(t, sb.String(), `<link rel="canonical" href="https://brandur.org/newsletter">`)
+	assert.Contains(t, sb.String(), `<meta http-equiv="refresh" content="0; url=/newsletter">`)
+	assert.Contains(t, sb.String(), `<a href="/newsletter">/newsletter</a>`)
+}
@@ -0,0 +1,488 @@
+// Package ssearch builds and queries a static full-text search index.
+//
+// The index is written as a set of JSON files so that it can be served
+// statically and queried from the browser. `index.json` holds metadata for
+// every document, and the inverted index mapping terms to the documents that
+// contain them is split into shards by each term's first character so that a
+// client only has to fetch the shards for the terms in its query.
+//
+// Terms are produced by Tokenize, which is mirrored in
+// `content/javascripts/search.js` along with Stem and the ranking in Search.
+// Any changes must be made to both.
+package ssearch
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Document is a piece of content to be indexed.
+type Document struct {
+	// Content is the document's content as plain text.
+	Content string
+
+	// Kind is a human-readable name for the type of content, like "Article".
+	Kind string
+
+	// PublishedAt is when the document was published.
+	PublishedAt time.Time
+
+	// Slug is the document's slug.
+	Slug string
+
+	// Snippet is a short summary of the document shown in results. If empty,
+	// one is taken from the start of Content.
+	Snippet string
+
+	// Title is the document's title.
+	Title string
+
+	// URL is the document's path on the site.
+	URL string
+}
+
+// Index is a search index, either built from documents with Build or read
+// from disk with Open.
+type Index struct {
+	// AvgLength is the average number of terms in a document's content.
+	AvgLength float64 `json:"avg_length"`
+
+	// Documents are the indexed documents, whose offsets in this slice are
+	// referenced by postings.
+	Documents []*IndexDocument `json:"documents"`
+
+	// Shards are the names of every shard in the index.
+	Shards []string `json:"shards"`
+
+	// loadShard loads a shard that's not in shards yet. Nil for an index
+	// produced by Build, which has all of its shards in memory already.
+	loadShard func(name string) (Shard, error)
+
+	shards   map[string]Shard
+	shardsMu sync.Mutex
+}
+
+// IndexDocument is the information about a document that's kept in an index
+// for display in results.
+type IndexDocument struct {
+	// Kind is a human-readable name for the type of content, like "Article".
+	Kind string `json:"kind"`
+
+	// Length is the number of terms in the document's content.
+	Length int `json:"length"`
+
+	// PublishedAt is the date that the document was published, like
+	// `2006-01-02`.
+	PublishedAt string `json:"published_at"`
+
+	// Slug is the document's slug.
+	Slug string `json:"slug"`
+
+	// Snippet is a short summary of the document.
+	Snippet string `json:"snippet"`
+
+	// Title is the document's title.
+	Title string `json:"title"`
+
+	// URL is the document's path on the site.
+	URL string `json:"url"`
+}
+
+// Posting records the appearance of a term in a document. It's encoded as a
+// compact three element array of `[doc, tf, title_tf]`.
+type Posting struct {
+	// Doc is the document's offset in Index.Documents.
+	Doc int
+
+	// TF is the number of times the term appears in the document's content.
+	TF int
+
+	// TitleTF is the number of times the term appears in the document's
+	// title.
+	TitleTF int
+}
+
+func (p Posting) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]int{p.Doc, p.TF, p.TitleTF})
+}
+
+func (p *Posting) UnmarshalJSON(data []byte) error {
+	var a [3]int
+	if err := json.Unmarshal(data, &a); err != nil {
+		return xerrors.Errorf("error decoding posting: %w", err)
+	}
+	p.Doc, p.TF, p.TitleTF = a[0], a[1], a[2]
+	return nil
+}
+
+// Result is a document matching a search.
+type Result struct {
+	Document *IndexDocument
+	Score    float64
+}
+
+// Shard maps terms to the documents that contain them.
+type Shard map[string][]Posting
+
+// Build produces an index of the given documents. Documents that score the
+// same in a search are returned in the order that they were given.
+func Build(docs []*Document) *Index {
+	ix := &Index{
+		Documents: make([]*IndexDocument, len(docs)),
+		shards:    make(map[string]Shard),
+	}
+
+	var totalLength int
+	for i, doc := range docs {
+		contentTerms := Tokenize(doc.Content)
+		titleTerms := Tokenize(doc.Title)
+
+		snippet := doc.Snippet
+		if snippet == "" {
+			snippet = truncate(doc.Content, snippetLength)
+		}
+
+		ix.Documents[i] = &IndexDocument{
+			Kind:        doc.Kind,
+			Length:      len(contentTerms),
+			PublishedAt: doc.PublishedAt.Format(time.DateOnly),
+			Slug:        doc.Slug,
+			Snippet:     snippet,
+			Title:       doc.Title,
+			URL:         doc.URL,
+		}
+		totalLength += len(contentTerms)
+
+		postings := make(map[string]*Posting)
+		posting := func(term string) *Posting {
+			p, ok := postings[term]
+			if !ok {
+				p = &Posting{Doc: i}
+				postings[term] = p
+			}
+			return p
+		}
+		for _, term := range contentTerms {
+			posting(term).TF++
+		}
+		for _, term := range titleTerms {
+			posting(term).TitleTF++
+		}
+
+		for term, p := range postings {
+			name := ShardName(term)
+			shard, ok := ix.shards[name]
+			if !ok {
+				shard = make(Shard)
+				ix.shards[name] = shard
+			}
+			shard[term] = append(shard[term], *p)
+		}
+	}
+
+	if len(docs) > 0 {
+		ix.AvgLength = float64(totalLength) / float64(len(docs))
+	}
+
+	for name := range ix.shards {
+		ix.Shards = append(ix.Shards, name)
+	}
+	slices.Sort(ix.Shards)
+
+	return ix
+}
+
+// Open reads an index that was written to dir with Write. Shards are read
+// lazily as they're needed by searches.
+func Open(dir string) (*Index, error) {
+	var ix Index
+	if err := readJSON(path.Join(dir, indexFilename), &ix); err != nil {
+		return nil, err
+	}
+
+	ix.loadShard = func(name string) (Shard, error) {
+		var shard Shard
+		if err := readJSON(path.Join(dir, shardsDir, name+".json"), &shard); err != nil {
+			return nil, err
+		}
+		return shard, nil
+	}
+	ix.shards = make(map[string]Shard)
+
+	return &ix, nil
+}
+
+// Search finds documents containing every term in query, returning up to
+// limit of them with the best matches first. Documents are ranked with BM25,
+// with a bonus for query terms that appear in their titles.
+func (ix *Index) Search(query string, limit int) ([]*Result, error) {
+	terms := slices.Compact(slices.Sorted(slices.Values(Tokenize(query))))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var (
+		numDocs = float64(len(ix.Documents))
+		scores  map[int]float64
+	)
+	for _, term := range terms {
+		shard, err := ix.shard(ShardName(term))
+		if err != nil {
+			return nil, err
+		}
+
+		postings := shard[term]
+		if len(postings) == 0 {
+			return nil, nil
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (numDocs-df+0.5)/(df+0.5))
+
+		termScores := make(map[int]float64, len(postings))
+		for _, p := range postings {
+			tf := float64(p.TF)
+			length := float64(ix.Documents[p.Doc].Length)
+
+			score := idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/max(ix.AvgLength, 1)))
+			if p.TitleTF > 0 {
+				score += titleWeight * idf
+			}
+
+			termScores[p.Doc] = score
+		}
+
+		// Documents must contain every term, so intersect with the documents
+		// found so far.
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for doc, score := range scores {
+			termScore, ok := termScores[doc]
+			if !ok {
+				delete(scores, doc)
+				continue
+			}
+			scores[doc] = score + termScore
+		}
+	}
+
+	results := make([]*Result, 0, len(scores))
+	docs := make([]int, 0, len(scores))
+	for doc := range scores {
+		docs = append(docs, doc)
+	}
+	slices.Sort(docs)
+	for _, doc := range docs {
+		results = append(results, &Result{Document: ix.Documents[doc], Score: scores[doc]})
+	}
+
+	// Stable so that ties keep the order of documents in the index.
+	slices.SortStableFunc(results, func(a, b *Result) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[0:limit]
+	}
+
+	return results, nil
+}
+
+// ShardName gets the name of the shard that a term is stored in: its first
+// character if that's a lowercase ASCII letter or digit, and `_` otherwise.
+func ShardName(term string) string {
+	if term == "" {
+		return "_"
+	}
+
+	c := term[0]
+	if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+		return string(c)
+	}
+
+	return "_"
+}
+
+// Tokenize breaks text into the terms that are indexed: words are lowercased,
+// stripped of apostrophes, and stemmed, and stop words and single characters
+// are dropped.
+func Tokenize(s string) []string {
+	s = apostropheReplacer.Replace(strings.ToLower(s))
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < 2 {
+			continue
+		}
+
+		if _, ok := stopWords[word]; ok {
+			continue
+		}
+
+		terms = append(terms, Stem(word))
+	}
+
+	return terms
+}
+
+// Write writes the index to dir as `index.json` and a JSON file for each
+// shard in `shards/`.
+func (ix *Index) Write(dir string) error {
+	if err := os.MkdirAll(path.Join(dir, shardsDir), 0o755); err != nil {
+		return xerrors.Errorf("error creating directory: %w", err)
+	}
+
+	if err := writeJSON(path.Join(dir, indexFilename), ix); err != nil {
+		return err
+	}
+
+	for _, name := range ix.Shards {
+		shard, err := ix.shard(name)
+		if err != nil {
+			return err
+		}
+
+		if err := writeJSON(path.Join(dir, shardsDir, name+".json"), shard); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+const (
+	// Standard BM25 parameters: k1 controls how quickly additional
+	// appearances of a term stop mattering, and b how much a document's
+	// length is normalized against.
+	bm25B  = 0.75
+	bm25K1 = 1.2
+
+	indexFilename = "index.json"
+	shardsDir     = "shards"
+	snippetLength = 200
+
+	// titleWeight is how much of a term's IDF is added to a document's score
+	// when the term appears in its title.
+	titleWeight = 2.0
+)
+
+var apostropheReplacer = strings.NewReplacer("'", "", "’", "")
+
+// A short list of common English words that aren't worth indexing. Matches
+// Lucene's default.
+var stopWords = map[string]struct{}{
+	"an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "but": {},
+	"by": {}, "for": {}, "if": {}, "in": {}, "into": {}, "is": {}, "it": {},
+	"no": {}, "not": {}, "of": {}, "on": {}, "or": {}, "such": {}, "that": {},
+	"the": {}, "their": {}, "then": {}, "there": {}, "these": {}, "they": {},
+	"this": {}, "to": {}, "was": {}, "will": {}, "with": {},
+}
+
+func readJSON(filename string, v any) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return xerrors.Errorf("error reading '%s': %w", filename, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return xerrors.Errorf("error decoding '%s': %w", filename, err)
+	}
+
+	return nil
+}
+
+func (ix *Index) shard(name string) (Shard, error) {
+	ix.shardsMu.Lock()
+	defer ix.shardsMu.Unlock()
+
+	if shard, ok := ix.shards[name]; ok {
+		return shard, nil
+	}
+
+	if ix.loadShard == nil || !slices.Contains(ix.Shards, name) {
+		return Shard{}, nil
+	}
+
+	shard, err := ix.loadShard(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ix.shards[name] = shard
+	return shard, nil
+}
+
+// truncate shortens s to at most maxLength runes, breaking between words and
+// adding an ellipsis if anything was removed.
+func truncate(s string, maxLength int) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	s = string(runes[0:maxLength])
+	if i := strings.LastIndex(s, " "); i > 0 {
+		s = s[0:i]
+	}
+
+	return strings.TrimRight(s, ",.;:") + " …"
+}
+
+func writeJSON(filename string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return xerrors.Errorf("error encoding '%s': %w", filename, err)
+	}
+
+	// Index files are fetched by browsers, so any server must be able to read
+	// them.
+	if err := os.WriteFile(filename, data, 0o644); err != nil { //nolint:gosec
+		return xerrors.Errorf("error writing '%s': %w", filename, err)
+	}
+
+	return nil
+}
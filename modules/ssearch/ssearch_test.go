@@ -0,0 +1,165 @@
+package ssearch
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	ix := Build(testDocuments())
+
+	assert.Len(t, ix.Documents, 3)
+	assert.Equal(t, &IndexDocument{
+		Kind:        "Article",
+		Length:      5,
+		PublishedAt: "2026-01-03",
+		Slug:        "postgres-queues",
+		Snippet:     "Queues in Postgres.",
+		Title:       "Postgres Queues",
+		URL:         "/postgres-queues",
+	}, ix.Documents[0])
+	assert.InDelta(t, 6.0, ix.AvgLength, 0.0001)
+	assert.Equal(t, []string{"a", "c", "f", "j", "p", "q", "r", "s", "t", "w"}, ix.Shards)
+
+	// "postgres" appears in the content and title of the first document and
+	// the content of the second.
+	assert.Equal(t, []Posting{{Doc: 0, TF: 2, TitleTF: 1}, {Doc: 1, TF: 1}}, ix.shards["p"]["postgr"])
+
+	// A snippet is produced from content when one isn't given.
+	assert.Equal(t, "Running a job queue in Postgres works well when jobs are short.", ix.Documents[1].Snippet)
+}
+
+func TestIndexSearch(t *testing.T) {
+	ix := Build(testDocuments())
+
+	titles := func(results []*Result) []string {
+		var titles []string
+		for _, result := range results {
+			titles = append(titles, result.Document.Title)
+		}
+		return titles
+	}
+
+	search := func(query string, limit int) []string {
+		results, err := ix.Search(query, limit)
+		assert.NoError(t, err)
+		return titles(results)
+	}
+
+	// A title match ranks above a content match.
+	assert.Equal(t, []string{"Postgres Queues", "Job Queues"}, search("postgres", 0))
+
+	// Query terms are stemmed like content.
+	assert.Equal(t, []string{"Postgres Queues", "Job Queues"}, search("QUEUEING", 0))
+
+	// Every term has to match.
+	assert.Equal(t, []string{"Job Queues"}, search("postgres jobs", 0))
+	assert.Empty(t, search("postgres mysql", 0))
+
+	assert.Equal(t, []string{"Postgres Queues"}, search("postgres", 1))
+	assert.Empty(t, search("", 0))
+	assert.Empty(t, search("the", 0))
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, Build(testDocuments()).Write(dir))
+
+	// Shards are written as maps of terms to arrays of postings.
+	{
+		data, err := os.ReadFile(path.Join(dir, "shards", "j.json"))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"job":[[1,2,1]]}`, string(data))
+	}
+
+	ix, err := Open(dir)
+	assert.NoError(t, err)
+	assert.Len(t, ix.Documents, 3)
+	assert.Empty(t, ix.shards)
+
+	results, err := ix.Search("postgres jobs", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Job Queues", results[0].Document.Title)
+	assert.Len(t, ix.shards, 2)
+
+	// A term in a shard that doesn't exist.
+	results, err = ix.Search("xylophone", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = Open(path.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "error reading")
+}
+
+func TestPostingJSON(t *testing.T) {
+	data, err := json.Marshal(Posting{Doc: 1, TF: 2, TitleTF: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "[1,2,3]", string(data))
+
+	var p Posting
+	assert.NoError(t, json.Unmarshal(data, &p))
+	assert.Equal(t, Posting{Doc: 1, TF: 2, TitleTF: 3}, p)
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`"a"`), &p), "error decoding posting")
+}
+
+func TestShardName(t *testing.T) {
+	assert.Equal(t, "p", ShardName("postgr"))
+	assert.Equal(t, "2", ShardName("2026"))
+	assert.Equal(t, "_", ShardName("éclair"))
+	assert.Equal(t, "_", ShardName(""))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"run", "job", "postgr"}, Tokenize("Running the jobs in Postgres."))
+	assert.Equal(t, []string{"dont", "stop"}, Tokenize("Don't stop"))
+	assert.Equal(t, []string{"dont", "stop"}, Tokenize("Don’t stop"))
+	assert.Equal(t, []string{"2026", "café", "東京"}, Tokenize("2026: a café in 東京"))
+	assert.Equal(t, []string{"hyphen", "word"}, Tokenize("hyphenated-words"))
+	assert.Empty(t, Tokenize(""))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Short.", truncate("Short.", 10))
+	assert.Equal(t, "Collapsed whitespace.", truncate("Collapsed\n\n  whitespace.", 100))
+	assert.Equal(t, "One two …", truncate("One two, three four", 12))
+	assert.Equal(t, strings.Repeat("é", 5)+" …", truncate(strings.Repeat("é", 10), 5))
+}
+
+func testDocuments() []*Document {
+	return []*Document{
+		{
+			Content:     "Queues in Postgres. Queueing in Postgres is fast.",
+			Kind:        "Article",
+			PublishedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+			Slug:        "postgres-queues",
+			Snippet:     "Queues in Postgres.",
+			Title:       "Postgres Queues",
+			URL:         "/postgres-queues",
+		},
+		{
+			Content:     "Running a job queue in Postgres works well when jobs are short.",
+			Kind:        "Fragment",
+			PublishedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Slug:        "job-queues",
+			Title:       "Job Queues",
+			URL:         "/fragments/job-queues",
+		},
+		{
+			Content:     "A short walk through the city.",
+			Kind:        "Atom",
+			PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "abc",
+			Title:       "Atom #abc",
+			URL:         "/atoms/abc",
+		},
+	}
+}
@@ -0,0 +1,315 @@
+package ssearch
+
+// Stem reduces an English word to its stem using the Porter stemming
+// algorithm so that words like "connection", "connected", and "connecting"
+// are all indexed as "connect". Words must be lowercase, and any that contain
+// anything other than the letters a-z are returned unchanged.
+//
+// This is a straight port of Martin Porter's reference implementation:
+//
+//	https://tartarus.org/martin/PorterStemmer/
+//
+// It's mirrored in `content/javascripts/search.js`, which has to stem queries
+// exactly the same way, so any changes must be made to both.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := range len(word) {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+
+	return string(z.b[0 : z.k+1])
+}
+
+// stemmer holds the state of a word being stemmed. b[0:k+1] is the current
+// stem, and j is an offset into it that's set by ends.
+type stemmer struct {
+	b []byte
+	j int
+	k int
+}
+
+// cons returns true if b[i] is a consonant.
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !z.cons(i - 1)
+	}
+	return true
+}
+
+// m measures the number of consonant sequences in b[0:j+1]. With c a
+// consonant sequence and v a vowel sequence, and [...] indicating optional
+// presence:
+//
+//	[c][v]       gives 0
+//	[c]vc[v]     gives 1
+//	[c]vcvc[v]   gives 2
+//	...
+func (z *stemmer) m() int {
+	n := 0
+	i := 0
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem returns true if b[0:j+1] contains a vowel.
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleC returns true if b[i-1:i+1] is a double consonant.
+func (z *stemmer) doubleC(i int) bool {
+	if i < 1 || z.b[i] != z.b[i-1] {
+		return false
+	}
+	return z.cons(i)
+}
+
+// cvc returns true if b[i-2:i+1] is consonant-vowel-consonant and the second
+// consonant isn't w, x, or y. This is used when trying to restore an "e" at
+// the end of a short word, like cav(e), lov(e), hop(e), crim(e), but not
+// snow, box, or tray.
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends returns true if b[0:k+1] ends with s, setting j to the offset just
+// before the suffix if it does.
+func (z *stemmer) ends(s string) bool {
+	if len(s) > z.k+1 {
+		return false
+	}
+	if string(z.b[z.k-len(s)+1:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - len(s)
+	return true
+}
+
+// setTo replaces b[j+1:k+1] with s, adjusting k.
+func (z *stemmer) setTo(s string) {
+	z.b = append(z.b[0:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+// r replaces the suffix found by ends with s, but only if what comes before
+// it has a measure greater than zero.
+func (z *stemmer) r(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// step1ab gets rid of plurals, and -ed or -ing. e.g.
+//
+//	caresses  ->  caress
+//	ponies    ->  poni
+//	feed      ->  feed
+//	agreed    ->  agree
+//	plastered ->  plaster
+//	motoring  ->  motor
+//	hopping   ->  hop
+//	filing    ->  file
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setTo("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+		return
+	}
+
+	if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		switch {
+		case z.ends("at"):
+			z.setTo("ate")
+		case z.ends("bl"):
+			z.setTo("ble")
+		case z.ends("iz"):
+			z.setTo("ize")
+		case z.doubleC(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		case z.m() == 1 && z.cvc(z.k):
+			z.setTo("e")
+		}
+	}
+}
+
+// step1c turns a terminal "y" into "i" when there's another vowel in the
+// stem.
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones, so -ization (-ize plus -ation)
+// maps to -ize and so on.
+func (z *stemmer) step2() {
+	z.replaceFirst(step2Suffixes[z.b[z.k-1]])
+}
+
+// step3 deals with -ic-, -full, -ness, etc. using a similar strategy to step2.
+func (z *stemmer) step3() {
+	z.replaceFirst(step3Suffixes[z.b[z.k]])
+}
+
+// step4 takes off -ant, -ence, etc. in context <c>vcvc<v>.
+func (z *stemmer) step4() {
+	for _, suffix := range step4Suffixes[z.b[z.k-1]] {
+		if !z.ends(suffix) {
+			continue
+		}
+
+		if suffix == "ion" && (z.j < 0 || (z.b[z.j] != 's' && z.b[z.j] != 't')) {
+			continue
+		}
+
+		if z.m() > 1 {
+			z.k = z.j
+		}
+		return
+	}
+}
+
+// step5 removes a final -e if m() > 1, and changes -ll to -l if m() > 1.
+func (z *stemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		a := z.m()
+		if a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doubleC(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
+
+// replaceFirst replaces the first of the given suffixes that the stem ends
+// with, stopping there even if the replacement isn't made.
+func (z *stemmer) replaceFirst(suffixes [][2]string) {
+	for _, suffix := range suffixes {
+		if z.ends(suffix[0]) {
+			z.r(suffix[1])
+			return
+		}
+	}
+}
+
+// Suffixes for step2 and step3 along with their replacements, keyed by the
+// penultimate and last letter of the stem respectively.
+var (
+	step2Suffixes = map[byte][][2]string{
+		'a': {{"ational", "ate"}, {"tional", "tion"}},
+		'c': {{"enci", "ence"}, {"anci", "ance"}},
+		'e': {{"izer", "ize"}},
+		'g': {{"logi", "log"}},
+		'l': {{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}},
+		'o': {{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}},
+		's': {{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}},
+		't': {{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}},
+	}
+
+	step3Suffixes = map[byte][][2]string{
+		'e': {{"icate", "ic"}, {"ative", ""}, {"alize", "al"}},
+		'i': {{"iciti", "ic"}},
+		'l': {{"ical", "ic"}, {"ful", ""}},
+		's': {{"ness", ""}},
+	}
+)
+
+// Suffixes removed by step4, keyed by the penultimate letter of the stem.
+var step4Suffixes = map[byte][]string{
+	'a': {"al"},
+	'c': {"ance", "ence"},
+	'e': {"er"},
+	'i': {"ic"},
+	'l': {"able", "ible"},
+	'n': {"ant", "ement", "ment", "ent"},
+	'o': {"ion", "ou"},
+	's': {"ism"},
+	't': {"ate", "iti"},
+	'u': {"ous"},
+	'v': {"ive"},
+	'z': {"ize"},
+}
@@ -0,0 +1,101 @@
+package ssitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxURLs is the most URLs that the protocol allows in a single sitemap.
+const MaxURLs = 50000
+
+// XML namespaces for sitemaps and their image extension.
+const (
+	XMLNS      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	XMLNSImage = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+// Image is an image that appears on a page, from Google's image sitemap
+// extension.
+type Image struct {
+	Loc string `xml:"image:loc"`
+}
+
+// Index is a sitemap index listing other sitemaps.
+type Index struct {
+	XMLName xml.Name `xml:"sitemapindex"`
+	XMLNS   string   `xml:"xmlns,attr"`
+
+	Sitemaps []*Sitemap `xml:"sitemap"`
+}
+
+// Encode the index to an io.Writer.
+func (ix *Index) Encode(w io.Writer, indent string) error {
+	if ix.XMLNS == "" {
+		ix.XMLNS = XMLNS
+	}
+
+	return encode(w, ix, indent)
+}
+
+// Sitemap is a reference to a sitemap from an index.
+type Sitemap struct {
+	Loc     string     `xml:"loc"`
+	LastMod *time.Time `xml:"lastmod,omitempty"`
+}
+
+// URL is a single page in a sitemap.
+type URL struct {
+	Loc     string     `xml:"loc"`
+	LastMod *time.Time `xml:"lastmod,omitempty"`
+	Images  []*Image   `xml:"image:image,omitempty"`
+}
+
+// URLSet is a sitemap listing pages.
+type URLSet struct {
+	XMLName    xml.Name `xml:"urlset"`
+	XMLNS      string   `xml:"xmlns,attr"`
+	XMLNSImage string   `xml:"xmlns:image,attr,omitempty"`
+
+	URLs []*URL `xml:"url"`
+}
+
+// Encode the sitemap to an io.Writer.
+//
+// The image namespace is only declared if an image is included.
+func (s *URLSet) Encode(w io.Writer, indent string) error {
+	if len(s.URLs) > MaxURLs {
+		return xerrors.Errorf("sitemap has %d URLs, but the maximum is %d", len(s.URLs), MaxURLs)
+	}
+
+	if s.XMLNS == "" {
+		s.XMLNS = XMLNS
+	}
+
+	if s.XMLNSImage == "" {
+		for _, u := range s.URLs {
+			if len(u.Images) > 0 {
+				s.XMLNSImage = XMLNSImage
+				break
+			}
+		}
+	}
+
+	return encode(w, s, indent)
+}
+
+func encode(w io.Writer, v any, indent string) error {
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return xerrors.Errorf("error writing XML header: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return xerrors.Errorf("error encoding sitemap: %w", err)
+	}
+
+	return nil
+}
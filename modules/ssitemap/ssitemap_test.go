@@ -0,0 +1,89 @@
+package ssitemap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestIndexEncode(t *testing.T) {
+	lastMod := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	index := &Index{
+		Sitemaps: []*Sitemap{
+			{Loc: "https://brandur.org/sitemaps/articles.xml", LastMod: &lastMod},
+			{Loc: "https://brandur.org/sitemaps/pages.xml"},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, index.Encode(&b, "  "))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://brandur.org/sitemaps/articles.xml</loc>
+    <lastmod>2026-01-02T03:04:05Z</lastmod>
+  </sitemap>
+  <sitemap>
+    <loc>https://brandur.org/sitemaps/pages.xml</loc>
+  </sitemap>
+</sitemapindex>`, b.String())
+}
+
+func TestURLSetEncode(t *testing.T) {
+	lastMod := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	{
+		urlSet := &URLSet{
+			URLs: []*URL{
+				{Loc: "https://brandur.org/a?b=1&c=2", LastMod: &lastMod},
+			},
+		}
+
+		var b bytes.Buffer
+		assert.NoError(t, urlSet.Encode(&b, "  "))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://brandur.org/a?b=1&amp;c=2</loc>
+    <lastmod>2026-01-02T03:04:05Z</lastmod>
+  </url>
+</urlset>`, b.String())
+	}
+
+	// The image namespace is declared when there are images.
+	{
+		urlSet := &URLSet{
+			URLs: []*URL{
+				{
+					Loc:    "https://brandur.org/photos",
+					Images: []*Image{{Loc: "https://brandur.org/photographs/a_large.jpg"}},
+				},
+			},
+		}
+
+		var b bytes.Buffer
+		assert.NoError(t, urlSet.Encode(&b, "  "))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">
+  <url>
+    <loc>https://brandur.org/photos</loc>
+    <image:image>
+      <image:loc>https://brandur.org/photographs/a_large.jpg</image:loc>
+    </image:image>
+  </url>
+</urlset>`, b.String())
+	}
+}
+
+func TestURLSetEncodeTooManyURLs(t *testing.T) {
+	urlSet := &URLSet{URLs: make([]*URL, MaxURLs+1)}
+
+	var b bytes.Buffer
+	err := urlSet.Encode(&b, "")
+	assert.ErrorContains(t, err, "sitemap has 50001 URLs, but the maximum is 50000")
+	assert.False(t, strings.Contains(b.String(), "urlset"))
+}
@@ -0,0 +1,172 @@
+// Package sspring83 implements the parts of Spring '83 needed to publish a
+// board: key handling, board construction and signing, and a client that
+// PUTs boards to a server.
+//
+// See https://github.com/robinsloan/spring-83 for the specification.
+package sspring83
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxBoardLength is the maximum size of a board in bytes.
+const MaxBoardLength = 2217
+
+// Version is the version of the protocol sent in the `Spring-Version` header.
+const Version = "83"
+
+// ErrConflict is returned by Client.Publish when the server already has a
+// board for the key with a timestamp as new or newer than the published one,
+// which usually means that the same board has been published before.
+var ErrConflict = errors.New("server already has a board at least as new as this one")
+
+// Client publishes boards to a Spring '83 server.
+type Client struct {
+	// HTTPClient is the client used to make requests. Defaults to
+	// http.DefaultClient if not set.
+	HTTPClient *http.Client
+
+	// ServerURL is the base URL of the server, like
+	// `https://neospring.brandur.org`.
+	ServerURL string
+}
+
+// Publish PUTs a board to the server, signed with the given key.
+func (c *Client) Publish(ctx context.Context, key ed25519.PrivateKey, board []byte) error {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	url := strings.TrimSuffix(c.ServerURL, "/") + "/" + PublicKeyHex(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(board))
+	if err != nil {
+		return xerrors.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/html;charset=utf-8")
+	req.Header.Set("Spring-Signature", Sign(key, board))
+	req.Header.Set("Spring-Version", Version)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("error publishing board to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return ErrConflict
+
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return xerrors.Errorf("error publishing board to %s (status %d): %s",
+			url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// KeyExpiry gets the time at which a public key expires. Spring '83 keys end
+// in `83e` followed by the month and two digit year of their last valid
+// month, so a key ending in `83e1226` is valid through December 2026.
+func KeyExpiry(publicKeyHex string) (time.Time, error) {
+	matches := keySuffixRE.FindStringSubmatch(publicKeyHex)
+	if matches == nil {
+		return time.Time{}, xerrors.Errorf("key doesn't end with 83eMMYY: %s", publicKeyHex)
+	}
+
+	month, _ := strconv.Atoi(matches[1])
+	year, _ := strconv.Atoi(matches[2])
+
+	// The first instant of the month after the last valid one.
+	return time.Date(2000+year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// NewBoard produces a board from HTML content, prefixing it with the `<time>`
+// element that Spring '83 servers use to order boards. A board is considered
+// to have been modified at modifiedAt, and publishing one that's not newer
+// than what a server already has is rejected with ErrConflict.
+func NewBoard(content string, modifiedAt time.Time) ([]byte, error) {
+	board := []byte(`<time datetime="` + modifiedAt.UTC().Format("2006-01-02T15:04:05Z") + `"></time>` +
+		"\n" + content)
+
+	if len(board) > MaxBoardLength {
+		return nil, xerrors.Errorf("board is greater than %d bytes (was %d)", MaxBoardLength, len(board))
+	}
+
+	return board, nil
+}
+
+// ParseKey parses a hex-encoded Ed25519 private key. Either a 32-byte seed or
+// the 64-byte form including the public key (as produced by most Spring '83
+// key generators) is accepted.
+func ParseKey(s string) (ed25519.PrivateKey, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, xerrors.Errorf("error decoding key as hex: %w", err)
+	}
+
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(b[0:ed25519.SeedSize])
+		if !bytes.Equal(key, b) {
+			return nil, xerrors.New("key's public half doesn't match its seed")
+		}
+		return key, nil
+	}
+
+	return nil, xerrors.Errorf("key should be %d or %d bytes (was %d)",
+		ed25519.SeedSize, ed25519.PrivateKeySize, len(b))
+}
+
+// PublicKeyHex gets the hex-encoded public key for a private key, which is
+// how a board is addressed on a server.
+func PublicKeyHex(key ed25519.PrivateKey) string {
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// Sign produces the hex-encoded signature of a board that's sent in the
+// `Spring-Signature` header.
+func Sign(key ed25519.PrivateKey, board []byte) string {
+	return hex.EncodeToString(ed25519.Sign(key, board))
+}
+
+// ValidateKey checks that a public key is one that a server will accept at
+// the given time: it must not have expired, nor expire more than two years
+// in the future.
+func ValidateKey(publicKeyHex string, now time.Time) error {
+	expiry, err := KeyExpiry(publicKeyHex)
+	if err != nil {
+		return err
+	}
+
+	if !now.Before(expiry) {
+		return xerrors.Errorf("key expired at %s: %s", expiry.Format(time.RFC3339), publicKeyHex)
+	}
+
+	if expiry.After(now.AddDate(2, 0, 0)) {
+		return xerrors.Errorf("key expires more than two years in the future at %s: %s",
+			expiry.Format(time.RFC3339), publicKeyHex)
+	}
+
+	return nil
+}
+
+var keySuffixRE = regexp.MustCompile(`83e(0[1-9]|1[0-2])(\d\d)$`)
@@ -0,0 +1,139 @@
+package sspring83
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestClientPublish(t *testing.T) {
+	key := testKey()
+
+	var (
+		received  []byte
+		signature string
+		status    = http.StatusOK
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/"+PublicKeyHex(key), r.URL.Path)
+		assert.Equal(t, "text/html;charset=utf-8", r.Header.Get("Content-Type"))
+		assert.Equal(t, Version, r.Header.Get("Spring-Version"))
+
+		var err error
+		received, err = io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		signature = r.Header.Get("Spring-Signature")
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("message from server\n"))
+	}))
+	defer server.Close()
+
+	client := &Client{ServerURL: server.URL + "/"}
+	board := []byte(`<time datetime="2026-01-02T03:04:05Z"></time>` + "\n<p>Hello.</p>")
+
+	assert.NoError(t, client.Publish(t.Context(), key, board))
+	assert.Equal(t, board, received)
+
+	sig, err := hex.DecodeString(signature)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), received, sig))
+
+	status = http.StatusConflict
+	assert.ErrorIs(t, client.Publish(t.Context(), key, board), ErrConflict)
+
+	status = http.StatusUnauthorized
+	err = client.Publish(t.Context(), key, board)
+	assert.ErrorContains(t, err, "(status 401): message from server")
+}
+
+func TestKeyExpiry(t *testing.T) {
+	{
+		expiry, err := KeyExpiry(strings.Repeat("a", 57) + "83e1226")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), expiry)
+	}
+
+	{
+		expiry, err := KeyExpiry(strings.Repeat("a", 57) + "83e0326")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), expiry)
+	}
+
+	{
+		_, err := KeyExpiry(strings.Repeat("a", 57) + "83e1326")
+		assert.ErrorContains(t, err, "key doesn't end with 83eMMYY")
+	}
+}
+
+func TestNewBoard(t *testing.T) {
+	modifiedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("PST", -8*60*60))
+
+	board, err := NewBoard("<p>Hello.</p>", modifiedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, `<time datetime="2026-01-02T11:04:05Z"></time>`+"\n<p>Hello.</p>", string(board))
+
+	_, err = NewBoard(strings.Repeat("a", MaxBoardLength), modifiedAt)
+	assert.ErrorContains(t, err, "board is greater than 2217 bytes")
+}
+
+func TestParseKey(t *testing.T) {
+	key := testKey()
+
+	{
+		parsed, err := ParseKey(hex.EncodeToString(key.Seed()))
+		assert.NoError(t, err)
+		assert.Equal(t, key, parsed)
+	}
+
+	{
+		parsed, err := ParseKey(hex.EncodeToString(key) + "\n")
+		assert.NoError(t, err)
+		assert.Equal(t, key, parsed)
+	}
+
+	{
+		mismatched := append(key.Seed(), make([]byte, ed25519.PublicKeySize)...)
+		_, err := ParseKey(hex.EncodeToString(mismatched))
+		assert.ErrorContains(t, err, "key's public half doesn't match its seed")
+	}
+
+	{
+		_, err := ParseKey("abcd")
+		assert.ErrorContains(t, err, "key should be 32 or 64 bytes (was 2)")
+	}
+
+	{
+		_, err := ParseKey("not hex")
+		assert.ErrorContains(t, err, "error decoding key as hex")
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	var (
+		key = strings.Repeat("a", 57) + "83e1226"
+		now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	assert.NoError(t, ValidateKey(key, now))
+	assert.NoError(t, ValidateKey(key, time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)))
+
+	assert.ErrorContains(t, ValidateKey(key, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)),
+		"key expired at 2027-01-01T00:00:00Z")
+
+	assert.ErrorContains(t, ValidateKey(strings.Repeat("a", 57)+"83e0130", now),
+		"key expires more than two years in the future")
+}
+
+func testKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+}
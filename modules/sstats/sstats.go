@@ -0,0 +1,152 @@
+// Package sstats computes statistics about a piece of rendered content, like
+// how many words it has and about how long it takes to read.
+package sstats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// WordsPerMinute is the reading speed that reading time is estimated with.
+const WordsPerMinute = 230
+
+// Stats are statistics about a piece of rendered content.
+type Stats struct {
+	// CodeBlocks is the number of code blocks in the content.
+	CodeBlocks int `json:"code_blocks"`
+
+	// Footnotes is the number of footnotes in the content.
+	Footnotes int `json:"footnotes"`
+
+	// Images is the number of images in the content.
+	Images int `json:"images"`
+
+	// ReadingTime is about how long the content takes to read at
+	// WordsPerMinute, rounded up to the minute.
+	ReadingTime time.Duration `json:"reading_time"`
+
+	// Words is the number of words in the content's prose. Code blocks
+	// aren't prose, so words in them aren't counted.
+	Words int `json:"words"`
+}
+
+// ReadingMinutes is ReadingTime in minutes, which is convenient for templates.
+func (s *Stats) ReadingMinutes() int {
+	return int(s.ReadingTime / time.Minute)
+}
+
+// Summary is a short human-readable summary of the content's length like
+// "12 min read, 2,740 words".
+func (s *Stats) Summary() string {
+	return fmt.Sprintf("%s min read, %s words", formatNumber(s.ReadingMinutes()), formatNumber(s.Words))
+}
+
+// Compute computes statistics for rendered HTML content. Content with its
+// footnotes split out should have them included again so that they're
+// counted.
+func Compute(content string) *Stats {
+	var (
+		ignored int
+		stats   Stats
+		text    strings.Builder
+		z       = html.NewTokenizer(strings.NewReader(content))
+	)
+
+	for {
+		switch tokenType := z.Next(); tokenType {
+		case html.ErrorToken:
+			stats.Words = countWords(text.String())
+			minutes := (stats.Words + WordsPerMinute - 1) / WordsPerMinute
+			stats.ReadingTime = time.Duration(minutes) * time.Minute
+			return &stats
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			a := atom.Lookup(name)
+
+			switch a {
+			case atom.Img:
+				stats.Images++
+
+			case atom.Pre:
+				stats.CodeBlocks++
+
+			case atom.Sup:
+				// Footnotes are numbered with a superscript carrying an ID
+				// like `footnote-1`, and references to them with one like
+				// `footnote-1-source`.
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == "id" && strings.HasPrefix(string(val), "footnote-") &&
+						!strings.HasSuffix(string(val), "-source") {
+						stats.Footnotes++
+					}
+				}
+			}
+
+			if ignoredElements[a] && tokenType == html.StartTagToken {
+				ignored++
+			}
+
+			// Tags may separate words (e.g. between paragraphs), so leave a
+			// space in their place.
+			text.WriteByte(' ')
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if ignoredElements[atom.Lookup(name)] && ignored > 0 {
+				ignored--
+			}
+			text.WriteByte(' ')
+
+		case html.TextToken:
+			if ignored == 0 {
+				text.Write(z.Text())
+			}
+
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+// formatNumber formats a non-negative number with commas between thousands.
+func formatNumber(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// countWords counts the words in plain text. Tags are replaced with spaces, so
+// punctuation after an inline element like `<em>` ends up on its own, and
+// isn't counted.
+func countWords(text string) int {
+	var words int
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, isWordRune) >= 0 {
+			words++
+		}
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Elements whose content isn't counted as words: code blocks, footnote
+// numbers, and anything that's not displayed.
+var ignoredElements = map[atom.Atom]bool{
+	atom.Pre:    true,
+	atom.Script: true,
+	atom.Style:  true,
+	atom.Sup:    true,
+}
@@ -0,0 +1,65 @@
+package sstats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	content := strings.TrimSpace(`
+<p>An article about <a href="/queues">job queues</a>
+<sup id="footnote-1-source"><a href="#footnote-1">1</a></sup> and
+<em>Postgres</em>.</p>
+
+<p><img src="/assets/images/queues/bloat.jpg" alt="Bloat"></p>
+<figure><img src="/assets/images/queues/vacuum.jpg" /></figure>
+
+<pre><code>SELECT * FROM jobs;
+</code></pre>
+
+<pre><code>VACUUM jobs;</code></pre>
+
+<div class="footnotes">
+<p><sup id="footnote-1"><a href="#footnote-1-source">1</a></sup> A footnote.</p>
+<p><sup id="footnote-2"><a href="#footnote-2-source">2</a></sup> Another.</p>
+</div>
+`)
+
+	stats := Compute(content)
+	assert.Equal(t, &Stats{
+		CodeBlocks:  2,
+		Footnotes:   2,
+		Images:      2,
+		ReadingTime: 1 * time.Minute,
+		Words:       10,
+	}, stats)
+	assert.Equal(t, 1, stats.ReadingMinutes())
+}
+
+func TestStatsSummary(t *testing.T) {
+	assert.Equal(t, "0 min read, 0 words", (&Stats{}).Summary())
+	assert.Equal(t, "12 min read, 2,740 words",
+		(&Stats{ReadingTime: 12 * time.Minute, Words: 2740}).Summary())
+	assert.Equal(t, "4,348 min read, 1,000,000 words",
+		(&Stats{ReadingTime: 4348 * time.Minute, Words: 1000000}).Summary())
+}
+
+func TestComputeReadingTime(t *testing.T) {
+	for _, tc := range []struct {
+		words    int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, 1 * time.Minute},
+		{WordsPerMinute, 1 * time.Minute},
+		{WordsPerMinute + 1, 2 * time.Minute},
+		{10 * WordsPerMinute, 10 * time.Minute},
+	} {
+		stats := Compute("<p>" + strings.Repeat("word ", tc.words) + "</p>")
+		assert.Equal(t, tc.words, stats.Words)
+		assert.Equal(t, tc.expected, stats.ReadingTime)
+	}
+}
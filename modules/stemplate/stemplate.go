@@ -14,12 +14,15 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/xerrors"
+
 	"github.com/brandur/modulir/modules/mtemplate"
 )
 
 // FuncMap is a set of helper functions to make available in templates for the
 // project.
 var FuncMap = template.FuncMap{
+	"Asset":                   asset,
 	"Downcase":                downcase,
 	"Favicon":                 favicon,
 	"FormatTimeLocal":         formatTimeLocal,
@@ -43,9 +46,27 @@ var FuncMap = template.FuncMap{
 	"URLBaseFile":             urlBaseFile,
 }
 
+// Assets maps the names of assets like `stylesheets/main.css` to their
+// fingerprinted names, like `stylesheets/main.0123abcdef.css`, for use with
+// Asset.
+var Assets map[string]string
+
 // LocalLocation is the location to show times in which use FormatTimeLocal.
 var LocalLocation *time.Location
 
+func asset(name string) (string, error) {
+	if Assets == nil {
+		panic("stemplate.Assets must be set")
+	}
+
+	fingerprintedName, ok := Assets[name]
+	if !ok {
+		return "", xerrors.Errorf("no such asset: %s", name)
+	}
+
+	return "/assets/" + fingerprintedName, nil
+}
+
 func downcase(s string) string {
 	return strings.ToLower(s)
 }
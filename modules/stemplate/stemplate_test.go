@@ -20,6 +20,18 @@ func init() {
 	}
 }
 
+func TestAsset(t *testing.T) {
+	Assets = map[string]string{"stylesheets/main.css": "stylesheets/main.0123abcdef.css"}
+	defer func() { Assets = nil }()
+
+	url, err := asset("stylesheets/main.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "/assets/stylesheets/main.0123abcdef.css", url)
+
+	_, err = asset("stylesheets/missing.css")
+	assert.ErrorContains(t, err, "no such asset: stylesheets/missing.css")
+}
+
 func TestDowncase(t *testing.T) {
 	assert.Equal(t, "hello", downcase("HeLlO"))
 }
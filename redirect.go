@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mfile"
+	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/sredirect"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// updatePublishedLedger adds the path of every article, fragment, and
+// newsletter issue that's not a draft to the ledger of published paths.
+// Builds only check the ledger, so this is run whenever new content is added
+// and the result checked in along with it. Scheduled content is included so
+// that the ledger's already up to date when its time comes.
+func updatePublishedLedger(c *modulir.Context) {
+	paths, err := contentSourcePaths(c)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	numAdded, err := addToPublishedLedger(path.Join(c.SourceDir, publishedLedgerFilename), paths)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	c.Log.Infof("Added %d path(s) to %s", numAdded, publishedLedgerFilename)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+type RedirectWrapper struct {
+	Redirects []*sredirect.Redirect `toml:"redirects" validate:"dive"`
+}
+
+func (w *RedirectWrapper) validate() error {
+	if err := validate.Struct(w); err != nil {
+		return xerrors.Errorf("error validating redirects: %w", err)
+	}
+
+	return nil
+}
+
+// publishedLedger is every path that content has ever been published at.
+// It's kept as a TOML file so that it can be checked in alongside content,
+// and is used to make sure that renaming or retiring content doesn't break
+// its old URL without a redirect in its place.
+type publishedLedger struct {
+	Paths []string `toml:"paths"`
+}
+
+// save writes the ledger to the given path. The file is written to a
+// temporary location and then renamed so that a failure midway through
+// doesn't corrupt a ledger that's already there.
+func (l *publishedLedger) save(target string) error {
+	// Paths go one per line so that additions are easy to review.
+	buf := bytes.NewBufferString(publishedLedgerHeader)
+	if err := toml.NewEncoder(buf).SetArraysMultiline(true).Encode(l); err != nil {
+		return xerrors.Errorf("error marshaling published ledger: %w", err)
+	}
+
+	if err := os.MkdirAll(path.Dir(target), 0o755); err != nil {
+		return xerrors.Errorf("error creating published ledger directory: %w", err)
+	}
+
+	tmpTarget := target + ".tmp"
+	if err := os.WriteFile(tmpTarget, buf.Bytes(), 0o600); err != nil {
+		return xerrors.Errorf("error writing published ledger: %w", err)
+	}
+
+	if err := os.Rename(tmpTarget, target); err != nil {
+		return xerrors.Errorf("error renaming published ledger: %w", err)
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Location of the ledger of published paths, relative to the source
+// directory.
+const publishedLedgerFilename = "data/published.toml"
+
+// Written at the top of the ledger of published paths because it's not
+// meant to be edited by hand.
+const publishedLedgerHeader = `# Every path that content has been published or scheduled at. Add new
+# content with ` + "`sorg published`" + ` and check in the result. A build fails if
+# content isn't in here, or if any of these stops existing without a redirect
+# in content/redirects.toml or an alias in the frontmatter of the content
+# that replaced it.
+
+`
+
+// Location of redirects, relative to the source directory.
+const redirectsFilename = "content/redirects.toml"
+
+// Names of the files in the target directory that redirects are written to
+// as configuration for various servers. Netlify requires that its file be
+// named `_redirects`, and the others are hidden so that they're not
+// deployed.
+const (
+	redirectsNetlifyFilename = "_redirects"
+	redirectsNginxFilename   = ".redirects.nginx.conf"
+	redirectsS3Filename      = ".redirects.s3"
+)
+
+// addToPublishedLedger adds any of the given paths that aren't in the ledger
+// at source to it, creating the ledger if it doesn't exist yet, and returns
+// the number added. The ledger is only written if something was added.
+func addToPublishedLedger(source string, paths []string) (int, error) {
+	ledger, err := loadPublishedLedger(source)
+	if err != nil {
+		return 0, err
+	}
+
+	numPaths := len(ledger.Paths)
+	for _, publishedPath := range paths {
+		if !slices.Contains(ledger.Paths, publishedPath) {
+			ledger.Paths = append(ledger.Paths, publishedPath)
+		}
+	}
+
+	numAdded := len(ledger.Paths) - numPaths
+	if numAdded == 0 {
+		return 0, nil
+	}
+
+	slices.Sort(ledger.Paths)
+	return numAdded, ledger.save(source)
+}
+
+// collectRedirects combines redirects from `redirects.toml` with those from
+// the aliases of published content, sorted by the path that they redirect
+// from. Returns an error if two redirects are from the same path, or a
+// redirect is from a path where content is published.
+func collectRedirects(redirects []*sredirect.Redirect, articles []*Article, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild,
+) ([]*sredirect.Redirect, error) {
+	all := slices.Clone(redirects)
+
+	addAliases := func(aliases []string, to string) {
+		for _, alias := range aliases {
+			all = append(all, &sredirect.Redirect{From: alias, To: to})
+		}
+	}
+
+	for _, article := range articles {
+		if !article.Draft {
+			addAliases(article.Aliases, "/"+article.Slug)
+		}
+	}
+
+	for _, fragment := range fragments {
+		if !fragment.Draft {
+			addAliases(fragment.Aliases, "/fragments/"+fragment.Slug)
+		}
+	}
+
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			if !issue.Draft {
+				addAliases(issue.Aliases, "/"+nb.newsletter.Slug+"/"+issue.Slug)
+			}
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b *sredirect.Redirect) int {
+		return strings.Compare(a.From, b.From)
+	})
+
+	published := make(map[string]struct{})
+	for _, publishedPath := range publishedPaths(articles, fragments, newsletterBuilds) {
+		published[publishedPath] = struct{}{}
+	}
+
+	for i, redirect := range all {
+		if !strings.HasPrefix(redirect.From, "/") {
+			return nil, xerrors.Errorf("redirect should be from a path starting with a slash: %s", redirect.From)
+		}
+
+		if redirect.From == redirect.To {
+			return nil, xerrors.Errorf("redirect from %s is to itself", redirect.From)
+		}
+
+		if i > 0 && all[i-1].From == redirect.From {
+			return nil, xerrors.Errorf("duplicate redirect from %s (to %s and %s)",
+				redirect.From, all[i-1].To, redirect.To)
+		}
+
+		if _, ok := published[redirect.From]; ok {
+			return nil, xerrors.Errorf("redirect from %s would replace content published there", redirect.From)
+		}
+	}
+
+	return all, nil
+}
+
+// checkPublishedLedger makes sure that every published or scheduled path is
+// in the ledger, and that every path in the ledger is either still published
+// or scheduled, or is redirected somewhere else. The ledger is never written
+// by a build; new paths are added to it with `sorg published`.
+func checkPublishedLedger(source string, paths []string, scheduledPaths map[string]struct{},
+	redirects []*sredirect.Redirect,
+) error {
+	ledger, err := loadPublishedLedger(source)
+	if err != nil {
+		return err
+	}
+
+	for scheduledPath := range scheduledPaths {
+		paths = append(paths, scheduledPath)
+	}
+
+	var unrecorded []string
+	for _, publishedPath := range paths {
+		if !slices.Contains(ledger.Paths, publishedPath) {
+			unrecorded = append(unrecorded, publishedPath)
+		}
+	}
+
+	if len(unrecorded) > 0 {
+		slices.Sort(unrecorded)
+		return xerrors.Errorf("published path(s) missing from %s: %s; "+
+			"run `sorg published` to add them and check in the result",
+			publishedLedgerFilename, strings.Join(unrecorded, ", "))
+	}
+
+	current := make(map[string]struct{}, len(paths)+len(redirects))
+	for _, publishedPath := range paths {
+		current[publishedPath] = struct{}{}
+	}
+	for _, redirect := range redirects {
+		current[redirect.From] = struct{}{}
+	}
+
+	var missing []string
+	for _, publishedPath := range ledger.Paths {
+		if _, ok := current[publishedPath]; !ok {
+			missing = append(missing, publishedPath)
+		}
+	}
+
+	if len(missing) > 0 {
+		return xerrors.Errorf("previously published path(s) no longer exist: %s; "+
+			"add a redirect to %s or an alias to the frontmatter of the content that replaced them",
+			strings.Join(missing, ", "), redirectsFilename)
+	}
+
+	return nil
+}
+
+// contentSourcePaths gets the paths of every article, fragment, and
+// newsletter issue in the source directory that's not a draft, including
+// those that are scheduled. Paths come from source file names, so content
+// doesn't have to be parsed.
+func contentSourcePaths(c *modulir.Context) ([]string, error) {
+	newsletters, err := loadNewsletters(c)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]string{
+		"/content/articles":  "/",
+		"/content/fragments": "/fragments/",
+	}
+	for _, newsletter := range newsletters {
+		dirs["/"+newsletter.ContentDir] = "/" + newsletter.Slug + "/"
+	}
+
+	var paths []string
+	for dir, prefix := range dirs {
+		sources, err := mfile.ReadDir(c, c.SourceDir+dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, source := range sources {
+			paths = append(paths, prefix+scommon.ExtractSlug(source))
+		}
+	}
+
+	return paths, nil
+}
+
+// loadPublishedLedger loads the ledger of published paths at the given path.
+// A ledger that doesn't exist yet is treated as empty.
+func loadPublishedLedger(source string) (*publishedLedger, error) {
+	data, err := os.ReadFile(source)
+	if errors.Is(err, os.ErrNotExist) {
+		return &publishedLedger{}, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading published ledger: %w", err)
+	}
+
+	var ledger publishedLedger
+	if err := toml.Unmarshal(data, &ledger); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling published ledger %q: %w", source, err)
+	}
+
+	return &ledger, nil
+}
+
+func loadRedirects(c *modulir.Context) ([]*sredirect.Redirect, error) {
+	var redirectWrapper RedirectWrapper
+	err := mtoml.ParseFile(c, c.SourceDir+"/"+redirectsFilename, &redirectWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redirectWrapper.validate(); err != nil {
+		return nil, err
+	}
+
+	return redirectWrapper.Redirects, nil
+}
+
+// publishedPaths gets the paths of all published articles, fragments, and
+// newsletter issues.
+func publishedPaths(articles []*Article, fragments []*Fragment, newsletterBuilds []*newsletterBuild) []string {
+	var paths []string
+
+	for _, article := range articles {
+		if !article.Draft {
+			paths = append(paths, "/"+article.Slug)
+		}
+	}
+
+	for _, fragment := range fragments {
+		if !fragment.Draft {
+			paths = append(paths, "/fragments/"+fragment.Slug)
+		}
+	}
+
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			if !issue.Draft {
+				paths = append(paths, "/"+nb.newsletter.Slug+"/"+issue.Slug)
+			}
+		}
+	}
+
+	return paths
+}
+
+// renderRedirects writes a stub page for every redirect from a page along
+// with configuration for servers that can redirect on their own, after
+// checking published content against the ledger of published paths.
+func renderRedirects(c *modulir.Context, redirects []*sredirect.Redirect, articles []*Article,
+	fragments []*Fragment, newsletterBuilds []*newsletterBuild, scheduledPaths map[string]struct{},
+	contentChanged bool,
+) (bool, error) {
+	if !contentChanged {
+		return false, nil
+	}
+
+	allRedirects, err := collectRedirects(redirects, articles, fragments, newsletterBuilds)
+	if err != nil {
+		return true, err
+	}
+
+	// A build with drafts includes drafts that are never meant to be in the
+	// ledger, so only other builds are checked against it.
+	if !conf.Drafts {
+		err = checkPublishedLedger(path.Join(c.SourceDir, publishedLedgerFilename),
+			publishedPaths(articles, fragments, newsletterBuilds), scheduledPaths, allRedirects)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	for _, redirect := range allRedirects {
+		if !redirect.HasStub() {
+			continue
+		}
+
+		target := path.Join(c.TargetDir, redirect.From)
+		if strings.HasSuffix(redirect.From, "/") {
+			target = path.Join(target, "index.html")
+		}
+
+		canonicalURL := redirect.To
+		if strings.HasPrefix(canonicalURL, "/") {
+			canonicalURL = conf.AbsoluteURL + canonicalURL
+		}
+
+		err := writeRedirectFile(target, func(w io.Writer) error {
+			return sredirect.WriteStub(w, redirect.To, canonicalURL)
+		})
+		if err != nil {
+			return true, err
+		}
+	}
+
+	for _, config := range []struct {
+		filename string
+		write    func(io.Writer, []*sredirect.Redirect) error
+	}{
+		{redirectsNetlifyFilename, sredirect.WriteNetlify},
+		{redirectsNginxFilename, sredirect.WriteNginx},
+		{redirectsS3Filename, sredirect.WriteS3},
+	} {
+		err := writeRedirectFile(path.Join(c.TargetDir, config.filename), func(w io.Writer) error {
+			return config.write(w, allRedirects)
+		})
+		if err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+func writeRedirectFile(target string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(path.Dir(target), 0o755); err != nil {
+		return xerrors.Errorf("error creating directory for '%s': %w", target, err)
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return xerrors.Errorf("error creating file '%s': %w", target, err)
+	}
+	defer file.Close()
+
+	return write(file)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+	"github.com/brandur/sorg/modules/sredirect"
+)
+
+func TestAddToPublishedLedger(t *testing.T) {
+	source := path.Join(t.TempDir(), publishedLedgerFilename)
+
+	// A ledger that doesn't exist yet is created.
+	numAdded, err := addToPublishedLedger(source, []string{"/postgres", "/glass"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, numAdded)
+
+	ledger, err := loadPublishedLedger(source)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/glass", "/postgres"}, ledger.Paths)
+
+	data, err := os.ReadFile(source)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), publishedLedgerHeader)
+
+	// New paths are added, and paths that are gone are kept.
+	numAdded, err = addToPublishedLedger(source, []string{"/atoms", "/postgres"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numAdded)
+
+	ledger, err = loadPublishedLedger(source)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/atoms", "/glass", "/postgres"}, ledger.Paths)
+
+	// Nothing is written when there's nothing new.
+	assert.NoError(t, os.Remove(source))
+	assert.NoError(t, os.WriteFile(source, data, 0o600))
+	numAdded, err = addToPublishedLedger(source, []string{"/glass"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, numAdded)
+
+	unchanged, err := os.ReadFile(source)
+	assert.NoError(t, err)
+	assert.Equal(t, data, unchanged)
+}
+
+func TestCheckPublishedLedger(t *testing.T) {
+	source := path.Join(t.TempDir(), publishedLedgerFilename)
+
+	// Content that's not in the ledger is an error, and the ledger isn't
+	// created.
+	err := checkPublishedLedger(source, []string{"/postgres", "/glass"}, nil, nil)
+	assert.ErrorContains(t, err, "published path(s) missing from data/published.toml: /glass, /postgres")
+	assert.NoFileExists(t, source)
+
+	_, err = addToPublishedLedger(source, []string{"/glass", "/postgres", "/scheduled"})
+	assert.NoError(t, err)
+
+	scheduledPaths := map[string]struct{}{"/scheduled": {}}
+	assert.NoError(t, checkPublishedLedger(source, []string{"/postgres", "/glass"}, scheduledPaths, nil))
+
+	// Scheduled content has to be in the ledger too.
+	err = checkPublishedLedger(source, []string{"/postgres", "/glass"},
+		map[string]struct{}{"/later": {}, "/scheduled": {}}, nil)
+	assert.ErrorContains(t, err, "published path(s) missing from data/published.toml: /later")
+
+	// A path disappearing without a redirect is an error.
+	err = checkPublishedLedger(source, []string{"/postgres"}, scheduledPaths, nil)
+	assert.ErrorContains(t, err, "previously published path(s) no longer exist: /glass")
+
+	// But it's fine with one.
+	assert.NoError(t, checkPublishedLedger(source, []string{"/postgres"}, scheduledPaths,
+		[]*sredirect.Redirect{{From: "/glass", To: "/newsletter"}}))
+
+	// The ledger is never written.
+	ledger, err := loadPublishedLedger(source)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/glass", "/postgres", "/scheduled"}, ledger.Paths)
+}
+
+func TestCollectRedirects(t *testing.T) {
+	articles := []*Article{
+		{Slug: "minimum-viable-unit", Aliases: []string{"/mvuss"}},
+		{Slug: "draft", Aliases: []string{"/old-draft"}, Draft: true},
+	}
+	fragments := []*Fragment{
+		{Slug: "in-defense-of-mail", Aliases: []string{"/fragments/mail"}},
+	}
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{Slug: "001", Aliases: []string{"/nanoglyphs/first"}},
+			},
+			newsletter: &Newsletter{Slug: "nanoglyphs"},
+		},
+	}
+
+	t.Run("Aliases", func(t *testing.T) {
+		redirects, err := collectRedirects(
+			[]*sredirect.Redirect{{From: "/glass", To: "/newsletter"}},
+			articles, fragments, newsletterBuilds)
+		assert.NoError(t, err)
+		assert.Equal(t, []*sredirect.Redirect{
+			{From: "/fragments/mail", To: "/fragments/in-defense-of-mail"},
+			{From: "/glass", To: "/newsletter"},
+			{From: "/mvuss", To: "/minimum-viable-unit"},
+			{From: "/nanoglyphs/first", To: "/nanoglyphs/001"},
+		}, redirects)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		_, err := collectRedirects(
+			[]*sredirect.Redirect{{From: "/mvuss", To: "/newsletter"}},
+			articles, fragments, newsletterBuilds)
+		assert.ErrorContains(t, err, "duplicate redirect from /mvuss")
+	})
+
+	t.Run("PublishedPath", func(t *testing.T) {
+		_, err := collectRedirects(
+			[]*sredirect.Redirect{{From: "/minimum-viable-unit", To: "/newsletter"}},
+			articles, fragments, newsletterBuilds)
+		assert.ErrorContains(t, err, "redirect from /minimum-viable-unit would replace content published there")
+	})
+
+	t.Run("ToItself", func(t *testing.T) {
+		_, err := collectRedirects(
+			[]*sredirect.Redirect{{From: "/glass", To: "/glass"}},
+			nil, nil, nil)
+		assert.ErrorContains(t, err, "redirect from /glass is to itself")
+	})
+}
+
+func TestContentSourcePaths(t *testing.T) {
+	c := &modulir.Context{Log: &modulir.Logger{Level: modulir.LevelInfo}, SourceDir: "."}
+
+	paths, err := contentSourcePaths(c)
+	assert.NoError(t, err)
+	assert.Contains(t, paths, "/10000-years")
+	assert.Contains(t, paths, "/fragments/1000-days")
+	assert.Contains(t, paths, "/nanoglyphs/001-initialize")
+	assert.NotContains(t, paths, "/black-boxes")
+}
+
+func TestPublishedPaths(t *testing.T) {
+	assert.Equal(t, []string{"/postgres", "/fragments/mail", "/nanoglyphs/001"}, publishedPaths(
+		[]*Article{{Slug: "postgres"}, {Slug: "draft", Draft: true}},
+		[]*Fragment{{Slug: "mail"}},
+		[]*newsletterBuild{
+			{
+				issues:     []*snewsletter.Issue{{Slug: "001"}, {Slug: "002", Draft: true}},
+				newsletter: &Newsletter{Slug: "nanoglyphs"},
+			},
+		},
+	))
+}
+
+func TestRenderRedirects(t *testing.T) {
+	c := modulir.NewContext(&modulir.Args{
+		Log:       &modulir.Logger{Level: modulir.LevelInfo},
+		SourceDir: t.TempDir(),
+		TargetDir: t.TempDir(),
+	})
+
+	redirects := []*sredirect.Redirect{
+		{From: "/glass", To: "/newsletter"},
+		{From: "/sequences/berlin.atom", To: "/sequences.atom"},
+	}
+	articles := []*Article{{Slug: "minimum-viable-unit", Aliases: []string{"/mvuss"}}}
+
+	executed, err := renderRedirects(c, redirects, articles, nil, nil, nil, false)
+	assert.NoError(t, err)
+	assert.False(t, executed)
+
+	ledgerSource := path.Join(c.SourceDir, publishedLedgerFilename)
+
+	// Content has to be in the ledger of published paths.
+	_, err = renderRedirects(c, redirects, articles, nil, nil, nil, true)
+	assert.ErrorContains(t, err, "published path(s) missing from data/published.toml: /minimum-viable-unit")
+
+	_, err = addToPublishedLedger(ledgerSource, []string{"/minimum-viable-unit"})
+	assert.NoError(t, err)
+
+	executed, err = renderRedirects(c, redirects, articles, nil, nil, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, executed)
+
+	stub, err := os.ReadFile(path.Join(c.TargetDir, "glass"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(stub), `<meta http-equiv="refresh" content="0; url=/newsletter">`)
+	assert.Contains(t, string(stub), `<link rel="canonical" href="`+conf.AbsoluteURL+`/newsletter">`)
+
+	assert.FileExists(t, path.Join(c.TargetDir, "mvuss"))
+	assert.NoFileExists(t, path.Join(c.TargetDir, "sequences/berlin.atom"))
+
+	netlify, err := os.ReadFile(path.Join(c.TargetDir, redirectsNetlifyFilename))
+	assert.NoError(t, err)
+	assert.Equal(t, `/glass /newsletter 301
+/mvuss /minimum-viable-unit 301
+/sequences/berlin.atom /sequences.atom 301
+`, string(netlify))
+
+	assert.FileExists(t, path.Join(c.TargetDir, redirectsNginxFilename))
+	assert.FileExists(t, path.Join(c.TargetDir, redirectsS3Filename))
+
+	t.Run("Drafts", func(t *testing.T) {
+		oldConf := conf
+		defer func() {
+			conf = oldConf
+		}()
+
+		conf.Drafts = true
+
+		// A build with drafts isn't checked against the ledger.
+		_, err := renderRedirects(c, redirects, []*Article{{Slug: "draft"}}, nil, nil, nil, true)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/sorg/modules/splaintext"
+	"github.com/brandur/sorg/modules/ssearch"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Name of the file in the target directory that the related content that
+// pages were last rendered with is persisted to, like backlinksFilename.
+const relatedFilename = ".related.json"
+
+// numRelated is the number of pieces of related content shown on an article
+// or fragment's page.
+const numRelated = 5
+
+// relatedTagWeight is how many occurrences of a term each of a document's tags
+// counts as, so that sharing a tag counts for more than sharing a few words.
+const relatedTagWeight = 5
+
+// relatedDocument is a piece of content in the related content model.
+type relatedDocument struct {
+	link *contentLink
+
+	// candidate is whether the document may be recommended as related to
+	// others. Drafts get related content of their own, but aren't
+	// recommended.
+	candidate bool
+
+	// hasRelated is whether the document's page shows related content,
+	// which only articles and fragments do.
+	hasRelated bool
+
+	// exclude and pins are paths from the document's `related_exclude` and
+	// `related` frontmatter.
+	exclude []string
+	pins    []string
+
+	// terms are the document's TF-IDF weighted terms, normalized to unit
+	// length and sorted by term so that scores are computed in the same order
+	// every time.
+	terms []relatedTerm
+}
+
+// relatedPosting is a document containing a term, and the term's weight in
+// it.
+type relatedPosting struct {
+	doc    int
+	weight float64
+}
+
+// relatedTerm is a term and its weight in a document.
+type relatedTerm struct {
+	term   string
+	weight float64
+}
+
+// collectRelated produces the related content of every article and fragment
+// using a TF-IDF model over the content and tags of all articles, fragments,
+// and newsletter issues, keyed by path.
+//
+// Content pinned with `related` frontmatter comes first in the order given,
+// followed by the most similar content (ties broken by path so that results
+// are stable between builds), leaving out anything in `related_exclude`.
+// Returns an error if a pin or exclusion isn't the path of any content. Pins
+// and exclusions of content in scheduledPaths, which has been left out
+// because it's scheduled, are dropped instead.
+func collectRelated(articles []*Article, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, scheduledPaths map[string]struct{},
+) (contentLinkGraph, error) {
+	var (
+		docs       []*relatedDocument
+		docsByPath = make(map[string]*relatedDocument)
+		termCounts []map[string]int
+	)
+
+	add := func(doc *relatedDocument, tags []Tag, title, content string) {
+		counts := make(map[string]int)
+		for _, term := range ssearch.Tokenize(title + " " + content) {
+			counts[term]++
+		}
+		for _, tag := range tags {
+			counts["#"+string(tag)] += relatedTagWeight
+		}
+
+		docs = append(docs, doc)
+		docsByPath[doc.link.URL] = doc
+		termCounts = append(termCounts, counts)
+	}
+
+	for _, article := range articles {
+		add(&relatedDocument{
+			link: &contentLink{
+				Kind:        "Article",
+				PublishedAt: article.PublishedAt,
+				Title:       article.Title,
+				URL:         "/" + article.Slug,
+			},
+			candidate:  !article.Draft,
+			hasRelated: true,
+			exclude:    article.RelatedExclude,
+			pins:       article.Related,
+		}, article.Tags, article.Title, splaintext.Text(string(article.Content)))
+	}
+
+	for _, fragment := range fragments {
+		add(&relatedDocument{
+			link: &contentLink{
+				Kind:        "Fragment",
+				PublishedAt: fragment.PublishedAt,
+				Title:       fragment.Title,
+				URL:         "/fragments/" + fragment.Slug,
+			},
+			candidate:  !fragment.Draft,
+			hasRelated: true,
+			exclude:    fragment.RelatedExclude,
+			pins:       fragment.Related,
+		}, fragment.Tags, fragment.Title, splaintext.Text(string(fragment.Content)))
+	}
+
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			title := fmt.Sprintf(nb.newsletter.TitleFormat, issue.Number, issue.Title)
+			add(&relatedDocument{
+				link: &contentLink{
+					Kind:        nb.newsletter.Name,
+					PublishedAt: issue.PublishedAt,
+					Title:       title,
+					URL:         "/" + nb.newsletter.Slug + "/" + issue.Slug,
+				},
+				candidate: !issue.Draft,
+			}, issueTags(issue), title, splaintext.Text(string(issue.Content)))
+		}
+	}
+
+	// Terms that appear in every document don't say anything about
+	// similarity, so they get no weight at all.
+	docFrequencies := make(map[string]int)
+	for _, counts := range termCounts {
+		for term := range counts {
+			docFrequencies[term]++
+		}
+	}
+
+	// An inverted index from term to the candidates containing it, so that
+	// only documents sharing a term with each other are ever compared.
+	postings := make(map[string][]relatedPosting)
+
+	for i, doc := range docs {
+		doc.terms = relatedTerms(termCounts[i], docFrequencies, len(docs))
+
+		if !doc.candidate {
+			continue
+		}
+		for _, term := range doc.terms {
+			postings[term.term] = append(postings[term.term], relatedPosting{doc: i, weight: term.weight})
+		}
+	}
+
+	graph := make(contentLinkGraph)
+
+	for _, doc := range docs {
+		if !doc.hasRelated {
+			continue
+		}
+
+		var related []*contentLink
+		seen := map[string]struct{}{doc.link.URL: {}}
+
+		for _, pin := range doc.pins {
+			if _, ok := scheduledPaths[pin]; ok {
+				continue
+			}
+
+			pinned, ok := docsByPath[pin]
+			if !ok {
+				return nil, xerrors.Errorf("related content %s of %s isn't the path of any content",
+					pin, doc.link.URL)
+			}
+			if _, ok := seen[pin]; ok {
+				continue
+			}
+			seen[pin] = struct{}{}
+
+			related = append(related, pinned.link)
+		}
+
+		for _, exclude := range doc.exclude {
+			if _, ok := scheduledPaths[exclude]; ok {
+				continue
+			}
+
+			if _, ok := docsByPath[exclude]; !ok {
+				return nil, xerrors.Errorf("excluded related content %s of %s isn't the path of any content",
+					exclude, doc.link.URL)
+			}
+			seen[exclude] = struct{}{}
+		}
+
+		// Terms are iterated in sorted order and postings in document order,
+		// so scores are summed in the same order on every build.
+		scores := make(map[int]float64)
+		for _, term := range doc.terms {
+			for _, posting := range postings[term.term] {
+				scores[posting.doc] += term.weight * posting.weight
+			}
+		}
+
+		candidates := make([]int, 0, len(scores))
+		for j := range scores {
+			if _, ok := seen[docs[j].link.URL]; ok {
+				continue
+			}
+			candidates = append(candidates, j)
+		}
+		slices.SortFunc(candidates, func(a, b int) int {
+			if scores[a] != scores[b] {
+				if scores[a] > scores[b] {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(docs[a].link.URL, docs[b].link.URL)
+		})
+
+		for _, j := range candidates {
+			if len(related) >= numRelated {
+				break
+			}
+			related = append(related, docs[j].link)
+		}
+
+		if len(related) > 0 {
+			graph[doc.link.URL] = related
+		}
+	}
+
+	return graph, nil
+}
+
+// relatedTerms weights a document's term counts by TF-IDF, using sublinear
+// term frequency, and normalizes them to unit length.
+func relatedTerms(counts map[string]int, docFrequencies map[string]int, numDocs int) []relatedTerm {
+	terms := make([]relatedTerm, 0, len(counts))
+	for term, count := range counts {
+		idf := math.Log(float64(numDocs) / float64(docFrequencies[term]))
+		if idf <= 0 {
+			continue
+		}
+
+		terms = append(terms, relatedTerm{term: term, weight: (1 + math.Log(float64(count))) * idf})
+	}
+
+	slices.SortFunc(terms, func(a, b relatedTerm) int { return strings.Compare(a.term, b.term) })
+
+	var norm float64
+	for _, term := range terms {
+		norm += term.weight * term.weight
+	}
+	norm = math.Sqrt(norm)
+
+	for i := range terms {
+		terms[i].weight /= norm
+	}
+
+	return terms
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"html/template"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+func TestCollectRelated(t *testing.T) {
+	publishedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newArticles := func() []*Article {
+		return []*Article{
+			{
+				Content:     template.HTML(`<p>Postgres queues and the bloat from dead tuples in a busy job queue table.</p>`),
+				PublishedAt: publishedAt,
+				Slug:        "postgres-queues",
+				Tags:        []Tag{"postgres"},
+				Title:       "Postgres Job Queues",
+			},
+			{
+				Content:     template.HTML(`<p>Transactional job drains stage jobs in a Postgres table.</p>`),
+				PublishedAt: publishedAt,
+				Slug:        "job-drain",
+				Tags:        []Tag{"postgres"},
+				Title:       "Job Drain",
+			},
+			{
+				Content:     template.HTML(`<p>A draft about Postgres job queue tables.</p>`),
+				Draft:       true,
+				PublishedAt: publishedAt,
+				Slug:        "draft",
+				Title:       "Draft",
+			},
+		}
+	}
+	newFragments := func() []*Fragment {
+		return []*Fragment{
+			{
+				Content:     template.HTML(`<p>Wireless earphones with decent battery life.</p>`),
+				PublishedAt: publishedAt,
+				Slug:        "airpods",
+				Title:       "AirPods",
+			},
+		}
+	}
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{
+					Content:     template.HTML(`<p>Queues in Postgres, and how bloat affects a job table.</p>`),
+					Number:      "001",
+					PublishedAt: publishedAt,
+					Slug:        "001-queues",
+					Tags:        []string{"postgres"},
+					Title:       "Queues",
+				},
+			},
+			newsletter: &Newsletter{Name: "Nanoglyph", Slug: "nanoglyphs", TitleFormat: "Nanoglyph %s — %s"},
+		},
+	}
+
+	urls := func(links []*contentLink) []string {
+		var urls []string
+		for _, link := range links {
+			urls = append(urls, link.URL)
+		}
+		return urls
+	}
+
+	t.Run("Similarity", func(t *testing.T) {
+		graph, err := collectRelated(newArticles(), newFragments(), newsletterBuilds, nil)
+		assert.NoError(t, err)
+
+		// Most similar first, and drafts are never recommended. Content
+		// without anything in common with other content has nothing
+		// related.
+		assert.Equal(t, []string{"/nanoglyphs/001-queues", "/job-drain"}, urls(graph["/postgres-queues"]))
+		assert.Equal(t, "Nanoglyph", graph["/postgres-queues"][0].Kind)
+		assert.Equal(t, "Nanoglyph 001 — Queues", graph["/postgres-queues"][0].Title)
+		assert.NotContains(t, graph, "/fragments/airpods")
+
+		// Drafts still get related content of their own, but newsletter
+		// issues don't.
+		assert.Contains(t, graph, "/draft")
+		assert.NotContains(t, graph, "/nanoglyphs/001-queues")
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		graph, err := collectRelated(newArticles(), newFragments(), newsletterBuilds, nil)
+		assert.NoError(t, err)
+
+		for range 10 {
+			other, err := collectRelated(newArticles(), newFragments(), newsletterBuilds, nil)
+			assert.NoError(t, err)
+
+			for contentPath := range graph {
+				assert.False(t, graph.changed(other, contentPath))
+			}
+		}
+	})
+
+	t.Run("PinAndExclude", func(t *testing.T) {
+		articles := newArticles()
+		articles[0].Related = []string{"/fragments/airpods", "/job-drain"}
+		articles[0].RelatedExclude = []string{"/nanoglyphs/001-queues"}
+
+		graph, err := collectRelated(articles, newFragments(), newsletterBuilds, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"/fragments/airpods", "/job-drain"}, urls(graph["/postgres-queues"]))
+	})
+
+	t.Run("UnknownPin", func(t *testing.T) {
+		articles := newArticles()
+		articles[0].Related = []string{"/not-content"}
+
+		_, err := collectRelated(articles, newFragments(), newsletterBuilds, nil)
+		assert.EqualError(t, err, "related content /not-content of /postgres-queues isn't the path of any content")
+	})
+
+	t.Run("ScheduledPinAndExclude", func(t *testing.T) {
+		articles := newArticles()
+		articles[0].Related = []string{"/fragments/scheduled", "/job-drain"}
+		articles[0].RelatedExclude = []string{"/scheduled"}
+
+		graph, err := collectRelated(articles, newFragments(), newsletterBuilds, map[string]struct{}{
+			"/fragments/scheduled": {},
+			"/scheduled":           {},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "/job-drain", graph["/postgres-queues"][0].URL)
+	})
+
+	t.Run("UnknownExclude", func(t *testing.T) {
+		fragments := newFragments()
+		fragments[0].RelatedExclude = []string{"/not-content"}
+
+		_, err := collectRelated(newArticles(), fragments, newsletterBuilds, nil)
+		assert.EqualError(t, err,
+			"excluded related content /not-content of /fragments/airpods isn't the path of any content")
+	})
+}
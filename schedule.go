@@ -0,0 +1,225 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/brandur/modulir"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Content with a `published_at` in the future is scheduled: it's left out of
+// every page, index, and feed until its time comes, except when drafts are
+// enabled, where it's shown with a banner. "Now" is when the current build
+// loop started so that every job in a loop agrees on what's scheduled.
+
+var (
+	// scheduledRebuild is a timer that triggers a rebuild when the next piece
+	// of scheduled content is due, or nil if there isn't one.
+	scheduledRebuild   *time.Timer
+	scheduledRebuildMu sync.Mutex
+
+	// scheduledRebuildShutdown is set once modulir starts shutting down, after
+	// which no more rebuilds are scheduled.
+	scheduledRebuildShutdown bool
+
+	// scheduledRebuildShutdownOnce starts watching for shutdown the first time
+	// a rebuild is scheduled.
+	scheduledRebuildShutdownOnce sync.Once
+)
+
+// How long a scheduled rebuild waits for the watcher to take its event before
+// giving up. The watcher only stops taking events when it's shutting down.
+const scheduledRebuildSendTimeout = 5 * time.Second
+
+// isScheduled returns true if content published at the given time is
+// scheduled for after the current build loop.
+func isScheduled(c *modulir.Context, publishedAt time.Time) bool {
+	return publishedAt.After(c.Stats.Start)
+}
+
+// newlyPublished returns true if content published at the given time was
+// scheduled as of the last build, which had published content up to
+// publishedThrough, but isn't anymore.
+func newlyPublished(c *modulir.Context, publishedAt, publishedThrough time.Time) bool {
+	return publishedAt.After(publishedThrough) && !isScheduled(c, publishedAt)
+}
+
+// nextScheduledAt gets the earliest time that any content is scheduled for,
+// or a zero time if none is.
+func nextScheduledAt(c *modulir.Context, articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, sequences []*SequenceEntry,
+) time.Time {
+	var next time.Time
+
+	consider := func(publishedAt time.Time) {
+		if isScheduled(c, publishedAt) && (next.IsZero() || publishedAt.Before(next)) {
+			next = publishedAt
+		}
+	}
+
+	for _, article := range articles {
+		consider(article.PublishedAt)
+	}
+	for _, atom := range atoms {
+		consider(atom.PublishedAt)
+	}
+	for _, fragment := range fragments {
+		consider(fragment.PublishedAt)
+	}
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			consider(issue.PublishedAt)
+		}
+	}
+	for _, entry := range sequences {
+		consider(entry.PublishedAt)
+	}
+
+	return next
+}
+
+// scheduleRebuild arranges for the build loop to run again at the given time
+// so that scheduled content appears on its own, replacing any rebuild that was
+// scheduled before. A zero time only cancels the previous one.
+//
+// Modulir has no hook to start a rebuild, and only rebuilds on changes from
+// its file watcher, so the rebuild is triggered by sending the watcher's
+// channel an event for a file that no build job depends on. Modulir closes
+// the watcher when it shuts down to re-exec itself on SIGUSR2, so a pending
+// rebuild is stopped then, and a send that loses the race is dropped by
+// sendRebuildEvent. Does nothing when not running in a loop.
+func scheduleRebuild(c *modulir.Context, at time.Time) {
+	if c.Watcher == nil {
+		return
+	}
+
+	scheduledRebuildShutdownOnce.Do(func() {
+		go stopScheduledRebuildOnShutdown()
+	})
+
+	scheduledRebuildMu.Lock()
+	defer scheduledRebuildMu.Unlock()
+
+	if scheduledRebuild != nil {
+		scheduledRebuild.Stop()
+		scheduledRebuild = nil
+	}
+
+	if at.IsZero() || scheduledRebuildShutdown {
+		return
+	}
+
+	c.Log.Infof("Scheduling rebuild for next scheduled content at %v", at)
+
+	var (
+		events = c.Watcher.Events
+		name   = filepath.Clean(filepath.Join(c.TargetDir, dependencyCacheFilename))
+		timer  *time.Timer
+	)
+	timer = time.AfterFunc(time.Until(at), func() {
+		scheduledRebuildMu.Lock()
+		defer scheduledRebuildMu.Unlock()
+
+		// Replaced or stopped after the timer had already fired.
+		if scheduledRebuild != timer || scheduledRebuildShutdown {
+			return
+		}
+		scheduledRebuild = nil
+
+		if !sendRebuildEvent(events, fsnotify.Event{Name: name, Op: fsnotify.Write}) {
+			c.Log.Infof("Watcher not taking events; dropping scheduled rebuild")
+		}
+	})
+	scheduledRebuild = timer
+}
+
+// sendRebuildEvent sends an event to a watcher's channel of events, returning
+// false if the channel was closed or the event wasn't taken before a timeout.
+func sendRebuildEvent(events chan fsnotify.Event, event fsnotify.Event) (sent bool) {
+	// Sending on a closed channel panics, and there's no way to check whether
+	// it's closed without receiving from it.
+	defer func() {
+		if recover() != nil {
+			sent = false
+		}
+	}()
+
+	select {
+	case events <- event:
+		return true
+	case <-time.After(scheduledRebuildSendTimeout):
+		return false
+	}
+}
+
+// stopScheduledRebuildOnShutdown waits for the signal that modulir shuts down
+// on and then stops any scheduled rebuild for good.
+func stopScheduledRebuildOnShutdown() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	<-signals
+	signal.Stop(signals)
+
+	scheduledRebuildMu.Lock()
+	defer scheduledRebuildMu.Unlock()
+
+	if scheduledRebuild != nil {
+		scheduledRebuild.Stop()
+		scheduledRebuild = nil
+	}
+	scheduledRebuildShutdown = true
+}
+
+// scheduledContentPaths gets the paths of the articles, fragments, and
+// newsletter issues that are scheduled, which are left out of the build but
+// may already be referred to by other content.
+func scheduledContentPaths(c *modulir.Context, articles []*Article, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild,
+) map[string]struct{} {
+	paths := make(map[string]struct{})
+
+	for _, article := range articles {
+		if isScheduled(c, article.PublishedAt) {
+			paths["/"+article.Slug] = struct{}{}
+		}
+	}
+	for _, fragment := range fragments {
+		if isScheduled(c, fragment.PublishedAt) {
+			paths["/fragments/"+fragment.Slug] = struct{}{}
+		}
+	}
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			if isScheduled(c, issue.PublishedAt) {
+				paths["/"+nb.newsletter.Slug+"/"+issue.Slug] = struct{}{}
+			}
+		}
+	}
+
+	return paths
+}
+
+// withoutScheduled returns a copy of the given content without anything
+// that's scheduled.
+func withoutScheduled[T any](c *modulir.Context, items []T, publishedAt func(T) time.Time) []T {
+	return slices.DeleteFunc(slices.Clone(items), func(item T) bool {
+		return isScheduled(c, publishedAt(item))
+	})
+}
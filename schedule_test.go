@@ -0,0 +1,154 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+func TestIsScheduled(t *testing.T) {
+	c := newScheduleContext(t, nil)
+
+	require.True(t, isScheduled(c, c.Stats.Start.Add(time.Minute)))
+	require.False(t, isScheduled(c, c.Stats.Start))
+	require.False(t, isScheduled(c, c.Stats.Start.Add(-time.Minute)))
+}
+
+func TestNewlyPublished(t *testing.T) {
+	c := newScheduleContext(t, nil)
+	publishedThrough := c.Stats.Start.Add(-time.Hour)
+
+	// Scheduled for after the last build, and now published.
+	require.True(t, newlyPublished(c, c.Stats.Start.Add(-time.Minute), publishedThrough))
+
+	// Already published as of the last build.
+	require.False(t, newlyPublished(c, publishedThrough, publishedThrough))
+
+	// Still scheduled.
+	require.False(t, newlyPublished(c, c.Stats.Start.Add(time.Minute), publishedThrough))
+
+	// Everything is newly published without a previous build.
+	require.True(t, newlyPublished(c, c.Stats.Start.Add(-24*time.Hour), time.Time{}))
+}
+
+func TestNextScheduledAt(t *testing.T) {
+	c := newScheduleContext(t, nil)
+
+	var (
+		past   = c.Stats.Start.Add(-time.Hour)
+		soon   = c.Stats.Start.Add(time.Hour)
+		future = c.Stats.Start.Add(24 * time.Hour)
+	)
+
+	require.True(t, nextScheduledAt(c,
+		[]*Article{{PublishedAt: past}},
+		nil, nil, nil, nil,
+	).IsZero())
+
+	require.Equal(t, soon, nextScheduledAt(c,
+		[]*Article{{PublishedAt: past}, {PublishedAt: future}},
+		[]*Atom{{PublishedAt: future}},
+		[]*Fragment{{PublishedAt: past}},
+		[]*newsletterBuild{{issues: []*snewsletter.Issue{{PublishedAt: soon}}}},
+		[]*SequenceEntry{{PublishedAt: future}},
+	))
+}
+
+func TestScheduledContentPaths(t *testing.T) {
+	c := newScheduleContext(t, nil)
+
+	var (
+		past   = c.Stats.Start.Add(-time.Hour)
+		future = c.Stats.Start.Add(time.Hour)
+	)
+
+	require.Equal(t, map[string]struct{}{
+		"/scheduled":              {},
+		"/fragments/scheduled":    {},
+		"/nanoglyphs/002-planned": {},
+	}, scheduledContentPaths(c,
+		[]*Article{{PublishedAt: past, Slug: "published"}, {PublishedAt: future, Slug: "scheduled"}},
+		[]*Fragment{{PublishedAt: future, Slug: "scheduled"}},
+		[]*newsletterBuild{{
+			issues: []*snewsletter.Issue{
+				{PublishedAt: past, Slug: "001-first"},
+				{PublishedAt: future, Slug: "002-planned"},
+			},
+			newsletter: &Newsletter{Slug: "nanoglyphs"},
+		}},
+	))
+}
+
+func TestScheduleRebuild(t *testing.T) {
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	c := newScheduleContext(t, watcher)
+
+	// A rebuild that's replaced never happens.
+	scheduleRebuild(c, time.Now().Add(50*time.Millisecond))
+	scheduleRebuild(c, time.Time{})
+
+	select {
+	case event := <-watcher.Events:
+		require.FailNow(t, "unexpected rebuild", "event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	scheduleRebuild(c, time.Now().Add(10*time.Millisecond))
+
+	select {
+	case event := <-watcher.Events:
+		require.Equal(t, filepath.Join(c.TargetDir, dependencyCacheFilename), event.Name)
+		require.Equal(t, fsnotify.Write, event.Op)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for rebuild")
+	}
+}
+
+func TestSendRebuildEvent(t *testing.T) {
+	event := fsnotify.Event{Name: "rebuild", Op: fsnotify.Write}
+
+	events := make(chan fsnotify.Event, 1)
+	require.True(t, sendRebuildEvent(events, event))
+	require.Equal(t, event, <-events)
+
+	// A watcher closes its channel when it shuts down.
+	close(events)
+	require.False(t, sendRebuildEvent(events, event))
+}
+
+func TestWithoutScheduled(t *testing.T) {
+	c := newScheduleContext(t, nil)
+
+	articles := []*Article{
+		{Slug: "published", PublishedAt: c.Stats.Start.Add(-time.Hour)},
+		{Slug: "scheduled", PublishedAt: c.Stats.Start.Add(time.Hour)},
+	}
+
+	published := withoutScheduled(c, articles, func(a *Article) time.Time { return a.PublishedAt })
+	require.Len(t, published, 1)
+	require.Equal(t, "published", published[0].Slug)
+
+	// The original is left alone.
+	require.Len(t, articles, 2)
+}
+
+func newScheduleContext(t *testing.T, watcher *fsnotify.Watcher) *modulir.Context {
+	t.Helper()
+
+	c := modulir.NewContext(&modulir.Args{
+		Log:       &modulir.Logger{Level: modulir.LevelInfo},
+		TargetDir: t.TempDir(),
+		Watcher:   watcher,
+	})
+	c.Stats.Start = time.Now()
+	return c
+}
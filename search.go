@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/splaintext"
+	"github.com/brandur/sorg/modules/ssearch"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// searchIndex queries the search index of a built site in TARGET_DIR and
+// prints the results. It uses the same index and ranking as the site's search
+// page, so it's useful for tuning results.
+func searchIndex(query string, limit int) {
+	results, err := searchIndexResults(conf.TargetDir, query, limit)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No results for: %s\n", query)
+		return
+	}
+
+	for i, result := range results {
+		doc := result.Document
+		fmt.Printf("%2d. %s (%.3f)\n", i+1, doc.Title, result.Score)
+		fmt.Printf("    %s, %s — %s%s\n", doc.Kind, doc.PublishedAt, conf.AbsoluteURL, doc.URL)
+		fmt.Printf("    %s\n\n", doc.Snippet)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// searchIndexDir is where the search index is written in the target
+// directory. The search page itself is at `/search`, so the index can't live
+// under the same path.
+const searchIndexDir = "search-index"
+
+// newSearchDocuments produces a search document for every piece of published
+// content, sorted newest first so that the most recent content wins a tie.
+// Content must already have been rendered.
+func newSearchDocuments(articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, sequences []*SequenceEntry,
+) []*ssearch.Document {
+	var docs []*ssearch.Document
+
+	for _, article := range articles {
+		if article.Draft {
+			continue
+		}
+
+		docs = append(docs, &ssearch.Document{
+			Content:     splaintext.Text(string(article.Content)),
+			Kind:        "Article",
+			PublishedAt: article.PublishedAt,
+			Slug:        article.Slug,
+			Snippet:     splaintext.Text(string(article.Hook)),
+			Title:       article.Title,
+			URL:         "/" + article.Slug,
+		})
+	}
+
+	for _, atom := range atoms {
+		docs = append(docs, &ssearch.Document{
+			Content:     splaintext.Text(string(atom.DescriptionHTML)),
+			Kind:        "Atom",
+			PublishedAt: atom.PublishedAt,
+			Slug:        atom.Slug,
+			Title:       atom.displayTitle(),
+			URL:         "/atoms/" + atom.Slug,
+		})
+	}
+
+	for _, fragment := range fragments {
+		if fragment.Draft {
+			continue
+		}
+
+		docs = append(docs, &ssearch.Document{
+			Content:     splaintext.Text(string(fragment.Content)),
+			Kind:        "Fragment",
+			PublishedAt: fragment.PublishedAt,
+			Slug:        fragment.Slug,
+			Snippet:     splaintext.Text(string(fragment.Hook)),
+			Title:       fragment.Title,
+			URL:         "/fragments/" + fragment.Slug,
+		})
+	}
+
+	for _, nb := range newsletterBuilds {
+		newsletter := nb.newsletter
+
+		for _, issue := range nb.issues {
+			if issue.Draft {
+				continue
+			}
+
+			docs = append(docs, &ssearch.Document{
+				Content:     splaintext.Text(string(issue.Content)),
+				Kind:        newsletter.Name,
+				PublishedAt: issue.PublishedAt,
+				Slug:        issue.Slug,
+				Snippet:     splaintext.Text(string(issue.Hook)),
+				Title:       fmt.Sprintf(newsletter.TitleFormat, issue.Number, issue.Title),
+				URL:         "/" + newsletter.Slug + "/" + issue.Slug,
+			})
+		}
+	}
+
+	for _, entry := range sequences {
+		docs = append(docs, &ssearch.Document{
+			Content:     splaintext.Text(string(entry.DescriptionHTML)),
+			Kind:        "Sequence",
+			PublishedAt: entry.PublishedAt,
+			Slug:        entry.Slug,
+			Title:       entry.Title,
+			URL:         "/sequences/" + entry.Slug,
+		})
+	}
+
+	slices.SortStableFunc(docs, func(a, b *ssearch.Document) int { return b.PublishedAt.Compare(a.PublishedAt) })
+
+	return docs
+}
+
+func renderSearch(ctx context.Context, c *modulir.Context) (bool, error) {
+	source := scommon.ViewsDir + "/search/index.tmpl.html"
+	viewsChanged := dependencies.viewChanged(c, source)
+	if !viewsChanged {
+		return false, nil
+	}
+
+	locals := getLocals(map[string]any{
+		"IndexURL": "/" + searchIndexDir,
+	})
+
+	return true, dependencies.renderGoTemplate(ctx, c, source, path.Join(c.TargetDir, "search"), locals)
+}
+
+// renderSearchIndex writes a search index covering all content to
+// `search-index/` in the target directory.
+func renderSearchIndex(c *modulir.Context, articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, sequences []*SequenceEntry, contentChanged bool,
+) (bool, error) {
+	if !contentChanged {
+		return false, nil
+	}
+
+	ix := ssearch.Build(newSearchDocuments(articles, atoms, fragments, newsletterBuilds, sequences))
+
+	if err := ix.Write(path.Join(c.TargetDir, searchIndexDir)); err != nil {
+		return true, xerrors.Errorf("error writing search index: %w", err)
+	}
+
+	return true, nil
+}
+
+func searchIndexResults(targetDir, query string, limit int) ([]*ssearch.Result, error) {
+	ix, err := ssearch.Open(path.Join(targetDir, searchIndexDir))
+	if err != nil {
+		return nil, xerrors.Errorf("error opening search index (has the site been built?): %w", err)
+	}
+
+	return ix.Search(strings.TrimSpace(query), limit)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+func TestNewSearchDocuments(t *testing.T) {
+	articles, atoms, fragments, newsletterBuilds, sequences := testSearchContent()
+
+	docs := newSearchDocuments(articles, atoms, fragments, newsletterBuilds, sequences)
+
+	var urls []string
+	for _, doc := range docs {
+		urls = append(urls, doc.URL)
+	}
+
+	// Drafts are left out, and everything else is sorted newest first.
+	assert.Equal(t, []string{
+		"/sequences/kyoto",
+		"/nanoglyphs/001-first",
+		"/fragments/queues",
+		"/atoms/abc",
+		"/postgres",
+	}, urls)
+
+	assert.Equal(t, "Nanoglyph 001 — First", docs[1].Title)
+	assert.Equal(t, "Nanoglyph", docs[1].Kind)
+	assert.Equal(t, "Issue content.", docs[1].Content)
+	assert.Equal(t, "An issue hook.", docs[1].Snippet)
+
+	assert.Equal(t, "Atom #abc", docs[3].Title)
+	assert.Equal(t, "A walk & a view.", docs[3].Content)
+}
+
+func TestRenderSearchIndex(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	articles, atoms, fragments, newsletterBuilds, sequences := testSearchContent()
+
+	{
+		executed, err := renderSearchIndex(c, articles, atoms, fragments, newsletterBuilds, sequences, false)
+		assert.NoError(t, err)
+		assert.False(t, executed)
+		assert.NoDirExists(t, path.Join(c.TargetDir, searchIndexDir))
+	}
+
+	{
+		executed, err := renderSearchIndex(c, articles, atoms, fragments, newsletterBuilds, sequences, true)
+		assert.NoError(t, err)
+		assert.True(t, executed)
+		assert.FileExists(t, path.Join(c.TargetDir, searchIndexDir, "index.json"))
+	}
+
+	results, err := searchIndexResults(c.TargetDir, "  Postgres ", 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "/postgres", results[0].Document.URL)
+	assert.Equal(t, "/fragments/queues", results[1].Document.URL)
+
+	// Drafts aren't indexed.
+	results, err = searchIndexResults(c.TargetDir, "draft", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = searchIndexResults(t.TempDir(), "postgres", 10)
+	assert.ErrorContains(t, err, "error opening search index (has the site been built?)")
+}
+
+func testSearchContent() ([]*Article, []*Atom, []*Fragment, []*newsletterBuild, []*SequenceEntry) {
+	articles := []*Article{
+		{
+			Content:     "<p>All about Postgres.</p>",
+			Hook:        "<em>Postgres</em> things.",
+			PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "postgres",
+			Title:       "Postgres",
+		},
+		{
+			Content:     "<p>A draft.</p>",
+			Draft:       true,
+			PublishedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "draft",
+			Title:       "Draft",
+		},
+	}
+
+	atoms := []*Atom{
+		{
+			DescriptionHTML: "<p>A walk &amp; a view.</p>",
+			PublishedAt:     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Slug:            "abc",
+		},
+	}
+
+	fragments := []*Fragment{
+		{
+			Content:     "<p>Queues in Postgres.</p>",
+			PublishedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+			Slug:        "queues",
+			Title:       "Queues",
+		},
+	}
+
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{
+					Content:     "<p>Issue content.</p>",
+					Hook:        "<p>An issue hook.</p>",
+					Number:      "001",
+					PublishedAt: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+					Slug:        "001-first",
+					Title:       "First",
+				},
+				{
+					Content:     "<p>A draft issue.</p>",
+					Draft:       true,
+					Number:      "002",
+					PublishedAt: time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC),
+					Slug:        "002-second",
+					Title:       "Second",
+				},
+			},
+			newsletter: &Newsletter{
+				Name:        "Nanoglyph",
+				Slug:        "nanoglyphs",
+				TitleFormat: "Nanoglyph %s — %s",
+			},
+		},
+	}
+
+	sequences := []*SequenceEntry{
+		{
+			DescriptionHTML: "<p>Temples.</p>",
+			PublishedAt:     time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			Slug:            "kyoto",
+			Title:           "Kyoto",
+		},
+	}
+
+	return articles, atoms, fragments, newsletterBuilds, sequences
+}
@@ -4,11 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aymerick/douceur/inliner"
-	"github.com/mailgun/mailgun-go/v4"
 	"golang.org/x/xerrors"
 
 	"github.com/brandur/modulir"
@@ -26,33 +27,65 @@ import (
 //
 //////////////////////////////////////////////////////////////////////////////
 
-func sendNewsletter(c *modulir.Context, source string, live, staging bool) {
+func sendNewsletter(c *modulir.Context, source string, live, staging, force bool) {
 	ctx := context.Background()
 
-	if err := renderAndSend(ctx, c, source, live, staging); err != nil {
+	if err := renderAndSend(ctx, c, source, live, staging, force); err != nil {
 		scommon.ExitWithError(err)
 	}
 }
 
-//////////////////////////////////////////////////////////////////////////////
-//
-//
-//
-// Types
-//
-//
-//
-//////////////////////////////////////////////////////////////////////////////
+// sendScheduled sends every newsletter issue whose publish time has passed
+// and which hasn't been sent to its live list yet. It's meant to be run
+// periodically from something like a cron job.
+func sendScheduled(c *modulir.Context) {
+	ctx := context.Background()
 
-// Contains some metadata for a newsletter around email addresses, paths, etc.
-type newsletterInfo struct {
-	ContentDir         string
-	ContentDirDrafts   string
-	Layout             string
-	MailAddress        string
-	MailAddressStaging string
-	TitleFormat        string
-	View               string
+	// Check mail configuration up front so that a misconfigured cron job fails
+	// on every run instead of only when an issue comes due.
+	if _, err := newMailer(); err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	sendLog, err := loadSendLog(conf.SendLogPath)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	newsletters, err := loadNewsletters(c)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	sources, err := findScheduledSends(c, newsletters, sendLog, time.Now())
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	if len(sources) < 1 {
+		c.Log.Infof("No newsletter issues due to be sent")
+		return
+	}
+
+	for _, source := range sources {
+		c.Log.Infof("Sending scheduled issue: %s", source)
+
+		if err := renderAndSend(ctx, c, source, true, false, false); err != nil {
+			scommon.ExitWithError(err)
+		}
+	}
+}
+
+// showSendHistory prints every newsletter send recorded in the send log.
+func showSendHistory() {
+	log, err := loadSendLog(conf.SendLogPath)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	if err := log.print(os.Stdout); err != nil {
+		scommon.ExitWithError(err)
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -71,67 +104,97 @@ const (
 	testAddress    = replyToAddress
 )
 
-var (
-	// Metadata for the Nanoglyph newsletter.
-	newsletterInfoNanoglyphs = &newsletterInfo{
-		ContentDir:         "./content/nanoglyphs",
-		ContentDirDrafts:   "./content/nanoglyphs-drafts",
-		Layout:             scommon.NanoglyphsLayout,
-		MailAddress:        "nanoglyph@" + mailDomain,
-		MailAddressStaging: "nanoglyph-staging@" + mailDomain,
-		TitleFormat:        "ⓝ Nanoglyph %s — %s",
-		View:               scommon.ViewsDir + "/nanoglyphs/show.tmpl.html",
-	}
-
-	// Metadata for the Passages & Glass newsletter.
-	newsletterInfoPassages = &newsletterInfo{
-		ContentDir:         "./content/passages",
-		ContentDirDrafts:   "./content/passages-drafts",
-		Layout:             scommon.PassagesLayout,
-		MailAddress:        "passages@" + mailDomain,
-		MailAddressStaging: "passages-staging@" + mailDomain,
-		TitleFormat:        "Passages & Glass %s — %s",
-		View:               scommon.ViewsDir + "/passages/show.tmpl.html",
-	}
-
-	// All infos combined into a slice.
-	newsletterInfos = []*newsletterInfo{
-		newsletterInfoNanoglyphs,
-		newsletterInfoPassages,
+// Issues published longer ago than this aren't sent by scheduled sends. This
+// keeps the first scheduled run from sending every issue in the archive that
+// went out before the send log existed.
+const scheduledSendWindow = 7 * 24 * time.Hour
+
+// findScheduledSends finds the sources of newsletter issues that are due to
+// be sent: those published in the last scheduledSendWindow (but not in the
+// future) that don't yet have a send to their live list in the send log.
+func findScheduledSends(c *modulir.Context, newsletters []*Newsletter, sendLog *sendLog,
+	now time.Time,
+) ([]string, error) {
+	var sources []string
+
+	for _, newsletter := range newsletters {
+		contentDir := c.SourceDir + "/" + newsletter.ContentDir
+
+		names, err := os.ReadDir(contentDir)
+		if err != nil {
+			return nil, xerrors.Errorf("error reading newsletter directory: %w", err)
+		}
+
+		for _, entry := range names {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+				continue
+			}
+
+			issue, err := snewsletter.Parse(c, contentDir, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case issue.PublishedAt.After(now):
+				c.Log.Debugf("Skipping issue scheduled for the future: %s (%s)",
+					issue.Slug, issue.PublishedAt.Format(time.RFC3339))
+				continue
+
+			case issue.PublishedAt.Before(now.Add(-scheduledSendWindow)):
+				continue
+
+			case sendLog.findLast(issue.Slug, newsletter.MailAddress) != nil:
+				c.Log.Debugf("Skipping issue already sent: %s", issue.Slug)
+				continue
+			}
+
+			sources = append(sources, filepath.Join(contentDir, entry.Name()))
+		}
 	}
-)
+
+	return sources, nil
+}
 
 // Matches a known newsletter based on the path to the source file. The second
 // return argument is true if the source is a draft.
-func matchNewsletter(source string) (*newsletterInfo, bool) {
+func matchNewsletter(sourceDir string, newsletters []*Newsletter, source string) (*Newsletter, bool) {
 	source = filepath.Clean(source)
 
-	for _, info := range newsletterInfos {
+	for _, newsletter := range newsletters {
 		{
-			dir := filepath.Clean(info.ContentDirDrafts)
+			dir := filepath.Clean(sourceDir + "/" + newsletter.ContentDirDrafts)
 			if strings.HasPrefix(source, dir) {
-				return info, true
+				return newsletter, true
 			}
 		}
 
 		{
-			dir := filepath.Clean(info.ContentDir)
+			dir := filepath.Clean(sourceDir + "/" + newsletter.ContentDir)
 			if strings.HasPrefix(source, dir) {
-				return info, false
+				return newsletter, false
 			}
 		}
 	}
 	return nil, false
 }
 
-func renderAndSend(ctx context.Context, c *modulir.Context, source string, live, staging bool) error {
-	if conf.MailgunAPIKey == "" {
-		return xerrors.Errorf(
-			"MAILGUN_API_KEY must be configured in the environment")
+// renderAndSend renders the newsletter issue at source and sends it to a
+// live list, staging list, or test address, recording the send in the send
+// log. A second live send of the same issue is refused unless force is set.
+func renderAndSend(ctx context.Context, c *modulir.Context, source string, live, staging, force bool) error {
+	transport, err := newMailer()
+	if err != nil {
+		return err
+	}
+
+	newsletters, err := loadNewsletters(c)
+	if err != nil {
+		return err
 	}
 
-	newsletterInfo, draft := matchNewsletter(source)
-	if newsletterInfo == nil {
+	newsletter, draft := matchNewsletter(c.SourceDir, newsletters, source)
+	if newsletter == nil {
 		return xerrors.Errorf("'%s' does not appear to be a known newsletter (check its path)",
 			source)
 	}
@@ -143,6 +206,19 @@ func renderAndSend(ctx context.Context, c *modulir.Context, source string, live,
 	dir := filepath.Dir(source)
 	name := filepath.Base(source)
 
+	sendLog, err := loadSendLog(conf.SendLogPath)
+	if err != nil {
+		return err
+	}
+
+	if live && !force {
+		slug := scommon.ExtractSlug(name)
+		if entry := sendLog.findLast(slug, newsletter.MailAddress); entry != nil {
+			return xerrors.Errorf("'%s' was already sent to %s at %s (message ID: %s); use --force to send it again",
+				slug, entry.List, entry.SentAt.Format(time.RFC3339), entry.MessageID)
+		}
+	}
+
 	issue, err := snewsletter.Render(c, dir, name, conf.AbsoluteURL, true)
 	if err != nil {
 		return err
@@ -160,7 +236,7 @@ func renderAndSend(ctx context.Context, c *modulir.Context, source string, live,
 		dependencies = NewDependencyRegistry()
 	)
 
-	err = dependencies.renderGoTemplateWriter(ctx, c, newsletterInfo.View, &b, locals)
+	err = dependencies.renderGoTemplateWriter(ctx, c, c.SourceDir+"/"+newsletter.View, &b, locals)
 	if err != nil {
 		return err
 	}
@@ -173,32 +249,44 @@ func renderAndSend(ctx context.Context, c *modulir.Context, source string, live,
 	var recipient string
 	switch {
 	case live:
-		recipient = newsletterInfo.MailAddress
+		recipient = newsletter.MailAddress
 	case staging:
-		recipient = newsletterInfo.MailAddressStaging
+		recipient = newsletter.MailAddressStaging
 	default:
 		recipient = testAddress
 	}
 
-	mg := mailgun.NewMailgun(mailDomain, conf.MailgunAPIKey)
+	id, err := transport.Send(ctx, &mailMessage{
+		From:    "Brandur <" + newsletter.MailAddress + ">",
+		HTML:    html,
+		ReplyTo: replyToAddress,
+		Subject: fmt.Sprintf(newsletter.EmailTitleFormat, issue.Number, issue.Title),
+		Text:    issue.ContentText,
+		To:      recipient,
+	})
+	if err != nil {
+		return xerrors.Errorf("error sending email: %w", err)
+	}
 
-	fromAddress := "Brandur <" + newsletterInfo.MailAddress + ">"
-	subject := fmt.Sprintf(newsletterInfo.TitleFormat,
-		issue.Number, issue.Title)
+	c.Log.Infof(`Sent to: %s via %s (message ID: %s)`, recipient, conf.MailTransport, id)
 
-	message := mg.NewMessage(
-		fromAddress,
-		subject,
-		issue.ContentRaw,
-		recipient)
-	message.SetReplyTo(replyToAddress)
-	message.SetHtml(html)
+	// The file transport doesn't send anything, so it's not recorded lest it
+	// block a real live send later on.
+	if conf.MailTransport == mailTransportFile {
+		return nil
+	}
 
-	resp, _, err := mg.Send(ctx, message)
-	if err != nil {
-		return xerrors.Errorf("error sending email: %w", err)
+	sendLog.Sends = append(sendLog.Sends, &sendLogEntry{
+		HTMLHash:  hashHTML(html),
+		List:      recipient,
+		MessageID: id,
+		SentAt:    time.Now().UTC(),
+		Slug:      issue.Slug,
+		Transport: conf.MailTransport,
+	})
+	if err := sendLog.save(conf.SendLogPath); err != nil {
+		return xerrors.Errorf("email was sent, but failed to record it in send log: %w", err)
 	}
 
-	c.Log.Infof(`Sent to: %s (response: "%s")`, recipient, resp)
 	return nil
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/xerrors"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// sendLog is a ledger of every newsletter email that's been sent. It's kept
+// as a TOML file so that it can be checked in alongside content, and is used
+// to make sure that an issue doesn't go out to a live list twice.
+type sendLog struct {
+	Sends []*sendLogEntry `toml:"sends"`
+}
+
+// findLast finds the most recent send of the issue with the given slug to
+// the given list address, returning nil if there wasn't one.
+func (l *sendLog) findLast(slug, list string) *sendLogEntry {
+	for i := len(l.Sends) - 1; i >= 0; i-- {
+		entry := l.Sends[i]
+		if entry.Slug == slug && entry.List == list {
+			return entry
+		}
+	}
+	return nil
+}
+
+// print prints a table of all sends, oldest first.
+func (l *sendLog) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SENT AT\tSLUG\tLIST\tTRANSPORT\tMESSAGE ID\tHTML HASH")
+	for _, entry := range l.Sends {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.SentAt.Format(time.RFC3339),
+			entry.Slug,
+			entry.List,
+			entry.Transport,
+			entry.MessageID,
+			entry.HTMLHash[0:min(12, len(entry.HTMLHash))])
+	}
+
+	if err := tw.Flush(); err != nil {
+		return xerrors.Errorf("error printing send log: %w", err)
+	}
+
+	return nil
+}
+
+// save writes the send log to the given path. The file is written to a
+// temporary location and then renamed so that a failure midway through
+// doesn't corrupt a ledger that's already there.
+func (l *sendLog) save(path string) error {
+	data, err := toml.Marshal(l)
+	if err != nil {
+		return xerrors.Errorf("error marshaling send log: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return xerrors.Errorf("error creating send log directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return xerrors.Errorf("error writing send log: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return xerrors.Errorf("error renaming send log: %w", err)
+	}
+
+	return nil
+}
+
+// sendLogEntry is a single send of a newsletter issue to a single list
+// address.
+type sendLogEntry struct {
+	// HTMLHash is a SHA-256 hash of the HTML that was sent, which is useful
+	// for telling whether an issue changed between sends.
+	HTMLHash string `toml:"html_hash"`
+
+	// List is the address that the issue was sent to. It's a live list,
+	// staging list, or test address.
+	List string `toml:"list"`
+
+	// MessageID is the identifier for the message that was assigned by the
+	// mail transport.
+	MessageID string `toml:"message_id"`
+
+	// SentAt is when the issue was sent.
+	SentAt time.Time `toml:"sent_at"`
+
+	// Slug is the slug of the issue that was sent, like `001-initialize`.
+	Slug string `toml:"slug"`
+
+	// Transport is the mail transport that the issue was sent through.
+	Transport string `toml:"transport"`
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// hashHTML produces a hash of sent HTML suitable for sendLogEntry.HTMLHash.
+func hashHTML(html string) string {
+	sum := sha256.Sum256([]byte(html))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadSendLog loads the send log at the given path. A log that doesn't exist
+// yet is treated as empty.
+func loadSendLog(path string) (*sendLog, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &sendLog{}, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading send log: %w", err)
+	}
+
+	var log sendLog
+	if err := toml.Unmarshal(data, &log); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling send log %q: %w", path, err)
+	}
+
+	return &log, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSendLogFindLast(t *testing.T) {
+	log := &sendLog{Sends: []*sendLogEntry{
+		{List: "a@example.com", MessageID: "1", Slug: "001-first"},
+		{List: "b@example.com", MessageID: "2", Slug: "001-first"},
+		{List: "a@example.com", MessageID: "3", Slug: "001-first"},
+		{List: "a@example.com", MessageID: "4", Slug: "002-second"},
+	}}
+
+	assert.Equal(t, "3", log.findLast("001-first", "a@example.com").MessageID)
+	assert.Equal(t, "2", log.findLast("001-first", "b@example.com").MessageID)
+	assert.Nil(t, log.findLast("002-second", "b@example.com"))
+	assert.Nil(t, log.findLast("003-third", "a@example.com"))
+}
+
+func TestSendLogPrint(t *testing.T) {
+	log := &sendLog{Sends: []*sendLogEntry{
+		{
+			HTMLHash:  hashHTML("<p>Hello.</p>"),
+			List:      "passages@list.brandur.org",
+			MessageID: "<123@example.com>",
+			SentAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Slug:      "001-first",
+			Transport: mailTransportMailgun,
+		},
+	}}
+
+	var b bytes.Buffer
+	assert.NoError(t, log.print(&b))
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, []string{"SENT", "AT", "SLUG", "LIST", "TRANSPORT", "MESSAGE", "ID", "HTML", "HASH"},
+		strings.Fields(lines[0]))
+	assert.Equal(t, []string{
+		"2026-01-02T03:04:05Z",
+		"001-first",
+		"passages@list.brandur.org",
+		"mailgun",
+		"<123@example.com>",
+		hashHTML("<p>Hello.</p>")[0:12],
+	}, strings.Fields(lines[1]))
+}
+
+func TestSendLogSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sends.toml")
+
+	{
+		log, err := loadSendLog(path)
+		assert.NoError(t, err)
+		assert.Empty(t, log.Sends)
+	}
+
+	entry := &sendLogEntry{
+		HTMLHash:  hashHTML("<p>Hello.</p>"),
+		List:      "passages@list.brandur.org",
+		MessageID: "<123@example.com>",
+		SentAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Slug:      "001-first",
+		Transport: mailTransportSMTP,
+	}
+	assert.NoError(t, (&sendLog{Sends: []*sendLogEntry{entry}}).save(path))
+
+	{
+		log, err := loadSendLog(path)
+		assert.NoError(t, err)
+		assert.Equal(t, []*sendLogEntry{entry}, log.Sends)
+	}
+}
@@ -1,68 +1,219 @@
 package main
 
 import (
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
 )
 
 func TestMatchNewsletter(t *testing.T) {
+	newsletters, err := loadNewsletters(testSendContext())
+	assert.NoError(t, err)
+
+	nanoglyphs := findNewsletter(newsletters, "nanoglyphs")
+	passages := findNewsletter(newsletters, "passages")
+	assert.NotNil(t, nanoglyphs)
+	assert.NotNil(t, passages)
+
 	{
-		info, draft := matchNewsletter("./content/nanoglyphs-drafts/999-placeholder.md")
-		assert.Equal(t, newsletterInfoNanoglyphs, info)
+		newsletter, draft := matchNewsletter(".", newsletters, "./content/nanoglyphs-drafts/999-placeholder.md")
+		assert.Equal(t, nanoglyphs, newsletter)
 		assert.True(t, draft)
 	}
 
 	{
-		info, draft := matchNewsletter("./content/nanoglyphs/001-first.md")
-		assert.Equal(t, newsletterInfoNanoglyphs, info)
+		newsletter, draft := matchNewsletter(".", newsletters, "./content/nanoglyphs/001-first.md")
+		assert.Equal(t, nanoglyphs, newsletter)
 		assert.False(t, draft)
 	}
 
 	{
-		info, draft := matchNewsletter("./content/passages-drafts/999-placeholder.md")
-		assert.Equal(t, newsletterInfoPassages, info)
+		newsletter, draft := matchNewsletter(".", newsletters, "./content/passages-drafts/999-placeholder.md")
+		assert.Equal(t, passages, newsletter)
 		assert.True(t, draft)
 	}
 
 	{
-		info, draft := matchNewsletter("./content/passages/001-first.md")
-		assert.Equal(t, newsletterInfoPassages, info)
+		newsletter, draft := matchNewsletter(".", newsletters, "content/passages/001-first.md")
+		assert.Equal(t, passages, newsletter)
 		assert.False(t, draft)
 	}
 
 	{
-		info, _ := matchNewsletter("./content/articles/article.md")
-		assert.Equal(t, (*newsletterInfo)(nil), info)
+		newsletter, _ := matchNewsletter(".", newsletters, "./content/articles/article.md")
+		assert.Equal(t, (*Newsletter)(nil), newsletter)
 	}
 }
 
 func TestRenderAndSend(t *testing.T) {
-	ctx := t.Context()
+	var (
+		c   = testSendContext()
+		ctx = t.Context()
+	)
 
-	oldMailgunKey := conf.MailgunAPIKey
+	oldConf := conf
 	defer func() {
-		conf.MailgunAPIKey = oldMailgunKey
+		conf = oldConf
 	}()
 
+	conf.MailTransport = mailTransportMailgun
 	conf.MailgunAPIKey = ""
+	conf.SendLogPath = filepath.Join(t.TempDir(), "sends.toml")
 
 	{
-		err := renderAndSend(ctx, nil, "./content/passages/001-first.md", true, false)
+		err := renderAndSend(ctx, c, "./content/passages/001-first.md", true, false, false)
 		assert.Error(t, err, "MAILGUN_API_KEY must be configured in the environment")
 	}
 
 	conf.MailgunAPIKey = "key"
 
 	{
-		err := renderAndSend(ctx, nil, "./content/articles/article.md", true, false)
+		err := renderAndSend(ctx, c, "./content/articles/article.md", true, false, false)
 		assert.Error(t, err,
 			"'./content/articles/article.md' does not appear to be a known newsletter (check its path)")
 	}
 
 	{
-		err := renderAndSend(ctx, nil, "./content/passages-drafts/999-placeholder.md", true, false)
+		err := renderAndSend(ctx, c, "./content/passages-drafts/999-placeholder.md", true, false, false)
 		assert.Error(t, err,
 			"refusing to send a draft newsletter to a live list")
 	}
+
+	{
+		sendLog := &sendLog{Sends: []*sendLogEntry{
+			{
+				List:      "passages@" + mailDomain,
+				MessageID: "<123@example.com>",
+				SentAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				Slug:      "001-first",
+			},
+		}}
+		assert.NoError(t, sendLog.save(conf.SendLogPath))
+
+		err := renderAndSend(ctx, c, "./content/passages/001-first.md", true, false, false)
+		assert.EqualError(t, err,
+			"'001-first' was already sent to passages@list.brandur.org at 2026-01-02T03:04:05Z "+
+				"(message ID: <123@example.com>); use --force to send it again")
+	}
+}
+
+func TestRenderAndSendSendLog(t *testing.T) {
+	ctx := t.Context()
+
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	conf.MailTransport = mailTransportSMTP
+	conf.SMTPAddr = listener.Addr().String()
+	conf.SendLogPath = filepath.Join(t.TempDir(), "sends.toml")
+
+	c := testSendContext()
+	source := "./content/passages/001-portland.md"
+
+	send := func(live, force bool) error {
+		received := make(chan smtpSinkMessage, 1)
+		go runSMTPSink(t, listener, received)
+
+		if err := renderAndSend(ctx, c, source, live, false, force); err != nil {
+			// Unblock the sink, which is still waiting for a connection.
+			conn, _ := net.Dial("tcp", conf.SMTPAddr)
+			if conn != nil {
+				conn.Close()
+			}
+			return err
+		}
+
+		<-received
+		return nil
+	}
+
+	assert.NoError(t, send(false, false))
+	assert.NoError(t, send(true, false))
+
+	err = send(true, false)
+	assert.ErrorContains(t, err, "'001-portland' was already sent to passages@list.brandur.org")
+
+	assert.NoError(t, send(true, true))
+
+	sendLog, err := loadSendLog(conf.SendLogPath)
+	assert.NoError(t, err)
+	assert.Len(t, sendLog.Sends, 3)
+
+	for i, list := range []string{testAddress, "passages@" + mailDomain, "passages@" + mailDomain} {
+		entry := sendLog.Sends[i]
+		assert.Equal(t, list, entry.List)
+		assert.Equal(t, "001-portland", entry.Slug)
+		assert.Equal(t, mailTransportSMTP, entry.Transport)
+		assert.NotEmpty(t, entry.MessageID)
+		assert.Len(t, entry.HTMLHash, 64)
+	}
+}
+
+func TestFindScheduledSends(t *testing.T) {
+	var (
+		c   = &modulir.Context{Log: &modulir.Logger{Level: modulir.LevelWarn}, SourceDir: t.TempDir()}
+		dir = c.SourceDir + "/content/test"
+		now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	newsletter := &Newsletter{
+		ContentDir:  "content/test",
+		MailAddress: "test@" + mailDomain,
+	}
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+
+	writeIssue := func(name string, publishedAt time.Time) {
+		data := "+++\ntitle = \"Test\"\npublished_at = " + publishedAt.Format(time.RFC3339) + "\n+++\n\nContent.\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+	}
+
+	writeIssue("001-archived.md", now.Add(-30*24*time.Hour))
+	writeIssue("002-sent.md", now.Add(-2*time.Hour))
+	writeIssue("003-due.md", now.Add(-1*time.Hour))
+	writeIssue("004-future.md", now.Add(1*time.Hour))
+
+	sendLog := &sendLog{Sends: []*sendLogEntry{
+		{List: newsletter.MailAddress, Slug: "002-sent"},
+
+		// Only a send to the live list counts.
+		{List: testAddress, Slug: "003-due"},
+	}}
+
+	sources, err := findScheduledSends(c, []*Newsletter{newsletter}, sendLog, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "003-due.md")}, sources)
+}
+
+//
+// Private
+//
+
+func findNewsletter(newsletters []*Newsletter, slug string) *Newsletter {
+	for _, newsletter := range newsletters {
+		if newsletter.Slug == slug {
+			return newsletter
+		}
+	}
+	return nil
+}
+
+// testSendContext produces a context like the one used by the `send` command.
+func testSendContext() *modulir.Context {
+	return &modulir.Context{
+		Forced:    true,
+		Log:       &modulir.Logger{Level: modulir.LevelWarn},
+		SourceDir: ".",
+	}
 }
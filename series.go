@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"path"
+	"regexp"
+	"slices"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/scommon"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// SeriesDefinition is a series in the allowlist.
+//
+// A series is a set of articles and fragments meant to be read in order, like
+// a multi-part article. Content joins a series with a `series = "<name>"`
+// frontmatter field, and is linked to the rest of the series on its page.
+// Each series gets a landing page at `/series/<name>` and a feed at
+// `/series/<name>.atom`.
+type SeriesDefinition struct {
+	// Description is an optional sentence or two about the series, shown on
+	// its landing page.
+	Description string `toml:"description"`
+
+	// Name is the series' name as it appears in content frontmatter and URLs.
+	Name string `toml:"name" validate:"required"`
+
+	// Title is a human-readable name for the series, like "Postgres
+	// Transactions".
+	Title string `toml:"title" validate:"required"`
+}
+
+type SeriesDefinitionWrapper struct {
+	Series []*SeriesDefinition `toml:"series" validate:"required,dive"`
+}
+
+func (w *SeriesDefinitionWrapper) validate() error {
+	if err := validate.Struct(w); err != nil {
+		return xerrors.Errorf("error validating series: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(w.Series))
+	for _, definition := range w.Series {
+		if !seriesNameRE.MatchString(definition.Name) {
+			return xerrors.Errorf("series name should contain only lowercase letters, numbers, and dashes: %s",
+				definition.Name)
+		}
+
+		if _, ok := names[definition.Name]; ok {
+			return xerrors.Errorf("duplicate series: %s", definition.Name)
+		}
+		names[definition.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// seriesBuild holds all the content in a particular series during a build.
+type seriesBuild struct {
+	definition *SeriesDefinition
+
+	// items are every piece of content in the series, in reading order.
+	items []*seriesItem
+}
+
+// nav produces navigation for the item in the series at the given index, to
+// be shown on its page.
+func (b *seriesBuild) nav(i int) *seriesNav {
+	nav := &seriesNav{
+		Definition: b.definition,
+		Items:      b.items,
+		NumParts:   len(b.items),
+		Part:       b.items[i].Part,
+	}
+
+	if i > 0 {
+		nav.Previous = b.items[i-1]
+	}
+	if i < len(b.items)-1 {
+		nav.Next = b.items[i+1]
+	}
+
+	return nav
+}
+
+// seriesItem is a piece of content of any kind that's part of a series.
+type seriesItem struct {
+	// Hook is an optional introduction to the content.
+	Hook template.HTML
+
+	// Kind is a human-readable name for the type of content, like "Article".
+	Kind string
+
+	// Part is the content's position in the series, starting from 1.
+	Part int
+
+	// PublishedAt is when the content was published.
+	PublishedAt time.Time
+
+	// Title is the content's title.
+	Title string
+
+	// URL is the content's path on the site.
+	URL string
+
+	// Only one of these is set, depending on the kind of content.
+	article  *Article
+	fragment *Fragment
+
+	// index is the content's explicit position in the series, or zero if it
+	// doesn't have one.
+	index int
+}
+
+// feedEntry produces an entry for the item in its series' feed.
+func (i *seriesItem) feedEntry() *feedEntry {
+	if i.article != nil {
+		return articleFeedEntry(i.article)
+	}
+	return fragmentFeedEntry(i.fragment)
+}
+
+// seriesNav is navigation for a piece of content within its series.
+type seriesNav struct {
+	// Definition is the series that the content is part of.
+	Definition *SeriesDefinition
+
+	// Items are every piece of content in the series, in reading order.
+	Items []*seriesItem
+
+	// Next is the content that comes after this one, or nil if this is the
+	// series' last part.
+	Next *seriesItem
+
+	// NumParts is the number of parts in the series.
+	NumParts int
+
+	// Part is the content's position in the series, starting from 1.
+	Part int
+
+	// Previous is the content that comes before this one, or nil if this is
+	// the series' first part.
+	Previous *seriesItem
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Series names must be slugs because they're used in URLs.
+var seriesNameRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// checkSeries returns an error if the given series isn't in the allowlist.
+// Content that's not in a series has an empty series, which is fine.
+func checkSeries(definitions []*SeriesDefinition, name string) error {
+	if name != "" && findSeriesDefinition(definitions, name) == nil {
+		return xerrors.Errorf("series %q isn't in the allowlist in %s", name, seriesFilename)
+	}
+	return nil
+}
+
+func findSeriesDefinition(definitions []*SeriesDefinition, name string) *SeriesDefinition {
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return definition
+		}
+	}
+	return nil
+}
+
+// groupSeriesContent discovers the series in use across articles and
+// fragments, producing a seriesBuild for each one in the same order as the
+// allowlist.
+//
+// A series is ordered by its members' `series_index` if they have one, and by
+// when they were published otherwise. Returns an error if any content is in
+// a series that's not in the allowlist, or if only some of a series' members
+// have an index, or two have the same one.
+func groupSeriesContent(definitions []*SeriesDefinition, articles []*Article,
+	fragments []*Fragment,
+) ([]*seriesBuild, error) {
+	builds := make(map[string]*seriesBuild)
+
+	add := func(description, name string, item *seriesItem) error {
+		if name == "" {
+			return nil
+		}
+
+		if err := checkSeries(definitions, name); err != nil {
+			return xerrors.Errorf("error checking series of %s: %w", description, err)
+		}
+
+		build, ok := builds[name]
+		if !ok {
+			build = &seriesBuild{definition: findSeriesDefinition(definitions, name)}
+			builds[name] = build
+		}
+
+		build.items = append(build.items, item)
+		return nil
+	}
+
+	for _, article := range articles {
+		err := add("article "+article.Slug, article.Series, &seriesItem{
+			Hook:        article.Hook,
+			Kind:        "Article",
+			PublishedAt: article.PublishedAt,
+			Title:       article.Title,
+			URL:         "/" + article.Slug,
+			article:     article,
+			index:       article.SeriesIndex,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fragment := range fragments {
+		err := add("fragment "+fragment.Slug, fragment.Series, &seriesItem{
+			Hook:        fragment.Hook,
+			Kind:        "Fragment",
+			PublishedAt: fragment.PublishedAt,
+			Title:       fragment.Title,
+			URL:         "/fragments/" + fragment.Slug,
+			fragment:    fragment,
+			index:       fragment.SeriesIndex,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var seriesBuilds []*seriesBuild
+	for _, definition := range definitions {
+		build, ok := builds[definition.Name]
+		if !ok {
+			continue
+		}
+
+		if err := sortSeriesItems(definition.Name, build.items); err != nil {
+			return nil, err
+		}
+		for i, item := range build.items {
+			item.Part = i + 1
+		}
+
+		seriesBuilds = append(seriesBuilds, build)
+	}
+
+	return seriesBuilds, nil
+}
+
+func loadSeriesDefinitions(c *modulir.Context) ([]*SeriesDefinition, error) {
+	var seriesWrapper SeriesDefinitionWrapper
+	err := mtoml.ParseFile(c, c.SourceDir+"/"+seriesFilename, &seriesWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := seriesWrapper.validate(); err != nil {
+		return nil, err
+	}
+
+	return seriesWrapper.Series, nil
+}
+
+// renderSeries renders a series' landing page and feed.
+func renderSeries(ctx context.Context, c *modulir.Context, sb *seriesBuild, contentChanged bool) (bool, error) {
+	sourceTmpl := scommon.ViewsDir + "/series/show.tmpl.html"
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
+	if !contentChanged && !viewsChanged {
+		return false, nil
+	}
+
+	name := sb.definition.Name
+
+	locals := getLocals(map[string]any{
+		"FeedURL": "/" + seriesFeedName(name) + ".atom",
+		"Items":   sb.items,
+		"Series":  sb.definition,
+	})
+
+	err := dependencies.renderGoTemplate(ctx, c, sourceTmpl, path.Join(c.TargetDir, "series", name), locals)
+	if err != nil {
+		return true, err
+	}
+
+	f := &feed{
+		ID:    "tag:" + scommon.AtomTag + ",2013:/" + seriesFeedName(name),
+		Name:  seriesFeedName(name),
+		Title: sb.definition.Title + scommon.TitleSuffix,
+	}
+
+	for _, item := range sb.items {
+		f.Entries = append(f.Entries, item.feedEntry())
+	}
+
+	// Feeds are newest first, unlike the series itself.
+	slices.SortStableFunc(f.Entries, func(a, b *feedEntry) int { return b.PublishedAt.Compare(a.PublishedAt) })
+
+	return true, writeFeed(f)
+}
+
+// seriesNavs produces navigation for every piece of content in a series,
+// keyed by its path on the site.
+func seriesNavs(seriesBuilds []*seriesBuild) map[string]*seriesNav {
+	navs := make(map[string]*seriesNav)
+	for _, sb := range seriesBuilds {
+		for i, item := range sb.items {
+			navs[item.URL] = sb.nav(i)
+		}
+	}
+	return navs
+}
+
+// seriesFeedName is the name of a series' feed, like `series/postgres` for
+// `/series/postgres.atom`.
+func seriesFeedName(name string) string {
+	return "series/" + name
+}
+
+// sortSeriesItems puts a series' items in reading order.
+func sortSeriesItems(name string, items []*seriesItem) error {
+	var numIndexed int
+	indexes := make(map[int]struct{}, len(items))
+	for _, item := range items {
+		if item.index == 0 {
+			continue
+		}
+
+		if _, ok := indexes[item.index]; ok {
+			return xerrors.Errorf("series %q has more than one member with series_index %d", name, item.index)
+		}
+		indexes[item.index] = struct{}{}
+		numIndexed++
+	}
+
+	switch numIndexed {
+	case 0:
+		slices.SortStableFunc(items, func(a, b *seriesItem) int { return a.PublishedAt.Compare(b.PublishedAt) })
+	case len(items):
+		slices.SortStableFunc(items, func(a, b *seriesItem) int { return a.index - b.index })
+	default:
+		return xerrors.Errorf("series %q should have a series_index on all of its members or none of them", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+)
+
+func TestCheckSeries(t *testing.T) {
+	definitions := []*SeriesDefinition{{Name: "kinesis", Title: "Kinesis"}}
+
+	require.NoError(t, checkSeries(definitions, ""))
+	require.NoError(t, checkSeries(definitions, "kinesis"))
+	require.EqualError(t, checkSeries(definitions, "sqs"),
+		`series "sqs" isn't in the allowlist in content/series.toml`)
+}
+
+func TestGroupSeriesContent(t *testing.T) {
+	var (
+		kinesis      = &SeriesDefinition{Name: "kinesis", Title: "Kinesis"}
+		transactions = &SeriesDefinition{Name: "postgres-transactions", Title: "Postgres Transactions"}
+		unused       = &SeriesDefinition{Name: "unused", Title: "Unused"}
+	)
+
+	definitions := []*SeriesDefinition{kinesis, transactions, unused}
+
+	articles := []*Article{
+		{Slug: "idempotency-keys", PublishedAt: time.Date(2017, 10, 27, 0, 0, 0, 0, time.UTC), Series: "postgres-transactions", SeriesIndex: 2},
+		{Slug: "job-drain", PublishedAt: time.Date(2017, 9, 20, 0, 0, 0, 0, time.UTC), Series: "postgres-transactions", SeriesIndex: 3},
+		{Slug: "http-transactions", PublishedAt: time.Date(2017, 9, 6, 0, 0, 0, 0, time.UTC), Series: "postgres-transactions", SeriesIndex: 1},
+		{Slug: "kinesis-in-production", PublishedAt: time.Date(2015, 5, 3, 0, 0, 0, 0, time.UTC), Series: "kinesis"},
+		{Slug: "standalone", PublishedAt: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	fragments := []*Fragment{
+		{Slug: "kinesis-by-example", PublishedAt: time.Date(2015, 3, 19, 0, 0, 0, 0, time.UTC), Series: "kinesis"},
+	}
+
+	seriesBuilds, err := groupSeriesContent(definitions, articles, fragments)
+	require.NoError(t, err)
+
+	urls := func(sb *seriesBuild) []string {
+		var urls []string
+		for _, item := range sb.items {
+			urls = append(urls, item.URL)
+		}
+		return urls
+	}
+
+	// In allowlist order, and only series that are in use.
+	require.Len(t, seriesBuilds, 2)
+
+	{
+		sb := seriesBuilds[0]
+		require.Equal(t, kinesis, sb.definition)
+
+		// Oldest first without indexes.
+		require.Equal(t, []string{"/fragments/kinesis-by-example", "/kinesis-in-production"}, urls(sb))
+		require.Equal(t, "Fragment", sb.items[0].Kind)
+		require.Equal(t, 2, sb.items[1].Part)
+	}
+
+	{
+		sb := seriesBuilds[1]
+		require.Equal(t, transactions, sb.definition)
+
+		// By index otherwise.
+		require.Equal(t, []string{"/http-transactions", "/idempotency-keys", "/job-drain"}, urls(sb))
+	}
+
+	t.Run("DuplicateIndex", func(t *testing.T) {
+		articles[1].SeriesIndex = 2
+		defer func() { articles[1].SeriesIndex = 3 }()
+
+		_, err := groupSeriesContent(definitions, articles, fragments)
+		require.EqualError(t, err,
+			`series "postgres-transactions" has more than one member with series_index 2`)
+	})
+
+	t.Run("PartialIndex", func(t *testing.T) {
+		articles[1].SeriesIndex = 0
+		defer func() { articles[1].SeriesIndex = 3 }()
+
+		_, err := groupSeriesContent(definitions, articles, fragments)
+		require.EqualError(t, err,
+			`series "postgres-transactions" should have a series_index on all of its members or none of them`)
+	})
+
+	t.Run("NotInAllowlist", func(t *testing.T) {
+		fragments[0].Series = "sqs"
+		defer func() { fragments[0].Series = "kinesis" }()
+
+		_, err := groupSeriesContent(definitions, articles, fragments)
+		require.EqualError(t, err,
+			`error checking series of fragment kinesis-by-example: series "sqs" isn't in the allowlist in content/series.toml`)
+	})
+}
+
+func TestLoadSeriesDefinitions(t *testing.T) {
+	c := modulir.NewContext(&modulir.Args{Log: &modulir.Logger{Level: modulir.LevelInfo}, SourceDir: "."})
+
+	definitions, err := loadSeriesDefinitions(c)
+	require.NoError(t, err)
+	require.NotNil(t, findSeriesDefinition(definitions, "postgres-transactions"))
+}
+
+func TestSeriesBuildNav(t *testing.T) {
+	sb := &seriesBuild{
+		definition: &SeriesDefinition{Name: "kinesis", Title: "Kinesis"},
+		items: []*seriesItem{
+			{Part: 1, URL: "/kinesis-by-example"},
+			{Part: 2, URL: "/kinesis-in-production"},
+			{Part: 3, URL: "/kinesis-retired"},
+		},
+	}
+
+	{
+		nav := sb.nav(0)
+		require.Equal(t, 1, nav.Part)
+		require.Equal(t, 3, nav.NumParts)
+		require.Nil(t, nav.Previous)
+		require.Equal(t, "/kinesis-in-production", nav.Next.URL)
+	}
+
+	{
+		nav := sb.nav(1)
+		require.Equal(t, 2, nav.Part)
+		require.Equal(t, "/kinesis-by-example", nav.Previous.URL)
+		require.Equal(t, "/kinesis-retired", nav.Next.URL)
+	}
+
+	{
+		nav := sb.nav(2)
+		require.Equal(t, "/kinesis-in-production", nav.Previous.URL)
+		require.Nil(t, nav.Next)
+	}
+}
+
+func TestSeriesDefinitionWrapperValidate(t *testing.T) {
+	require.NoError(t, (&SeriesDefinitionWrapper{Series: []*SeriesDefinition{
+		{Name: "kinesis", Title: "Kinesis"},
+		{Name: "postgres-transactions", Title: "Postgres Transactions"},
+	}}).validate())
+
+	require.EqualError(t, (&SeriesDefinitionWrapper{Series: []*SeriesDefinition{
+		{Name: "kinesis", Title: "Kinesis"},
+		{Name: "kinesis", Title: "Kinesis"},
+	}}).validate(), "duplicate series: kinesis")
+
+	require.EqualError(t, (&SeriesDefinitionWrapper{Series: []*SeriesDefinition{
+		{Name: "Kinesis", Title: "Kinesis"},
+	}}).validate(), "series name should contain only lowercase letters, numbers, and dashes: Kinesis")
+
+	require.ErrorContains(t, (&SeriesDefinitionWrapper{Series: []*SeriesDefinition{
+		{Name: "kinesis"},
+	}}).validate(), "title")
+}
+
+func TestSeriesFeedName(t *testing.T) {
+	require.Equal(t, "series/kinesis", seriesFeedName("kinesis"))
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/ssitemap"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// sitemapSection is a sitemap covering one section of the site, like
+// articles or photos. Each one is written to `/sitemaps/<name>.xml` and listed
+// in the sitemap index at `/sitemap.xml`.
+type sitemapSection struct {
+	// Name determines where the sitemap is written, like `articles` for
+	// `/sitemaps/articles.xml`.
+	Name string
+
+	// URLs are the section's pages, with paths on the site as locations.
+	// They're made absolute when the sitemap is written.
+	URLs []*ssitemap.URL
+}
+
+// add adds a page to the section. lastMod and any images are optional.
+func (s *sitemapSection) add(loc string, lastMod time.Time, images ...string) {
+	s.URLs = append(s.URLs, newSitemapURL(loc, lastMod, images...))
+}
+
+// addIndexes adds index pages like `/articles` to the start of the section.
+// They're last modified along with the most recent page already in it.
+func (s *sitemapSection) addIndexes(locs ...string) {
+	var lastMod time.Time
+	if t := s.lastMod(); t != nil {
+		lastMod = *t
+	}
+
+	indexes := make([]*ssitemap.URL, len(locs))
+	for i, loc := range locs {
+		indexes[i] = newSitemapURL(loc, lastMod)
+	}
+
+	s.URLs = slices.Insert(s.URLs, 0, indexes...)
+}
+
+// lastMod is the most recent modification time of any page in the section,
+// or nil if none of them have one.
+func (s *sitemapSection) lastMod() *time.Time {
+	var lastMod *time.Time
+	for _, u := range s.URLs {
+		if u.LastMod != nil && (lastMod == nil || u.LastMod.After(*lastMod)) {
+			lastMod = u.LastMod
+		}
+	}
+	return lastMod
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// newSitemapSections produces a sitemap section for each kind of content.
+// Drafts are left out, so pageSources should only include sources from
+// `./pages` and not `./pages-drafts`.
+func newSitemapSections(articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, pageSources []string, photos []*Photo,
+	sequences []*SequenceEntry, tagDefinitions []*TagDefinition,
+) ([]*sitemapSection, error) {
+	// Tags are collected along with the rest of the content so that a tag
+	// only applied to drafts isn't included.
+	tagsLastMod := make(map[Tag]time.Time)
+	addTags := func(tags []Tag, publishedAt time.Time) {
+		for _, tag := range tags {
+			if publishedAt.After(tagsLastMod[tag]) {
+				tagsLastMod[tag] = publishedAt
+			}
+		}
+	}
+
+	articlesSection := &sitemapSection{Name: "articles"}
+	{
+		for _, article := range articles {
+			if article.Draft {
+				continue
+			}
+
+			// Content that's been revised is last modified when it was last
+			// updated.
+			articlesSection.add("/"+article.Slug, latestTime(article.PublishedAt, article.lastUpdatedAt()))
+			addTags(article.Tags, article.PublishedAt)
+		}
+		articlesSection.addIndexes("/articles")
+	}
+
+	atomsSection := &sitemapSection{Name: "atoms"}
+	{
+		for _, atom := range atoms {
+			var images []string
+			for _, photo := range atom.Photos {
+				images = append(images, "/photographs/atoms/"+atom.Slug+"/"+photo.Slug+"_large"+photo.TargetExt())
+			}
+
+			atomsSection.add("/atoms/"+atom.Slug, atom.PublishedAt, images...)
+			addTags(atom.Tags, atom.PublishedAt)
+		}
+		atomsSection.addIndexes("/atoms", "/atoms/archive")
+	}
+
+	fragmentsSection := &sitemapSection{Name: "fragments"}
+	{
+		for _, fragment := range fragments {
+			if fragment.Draft {
+				continue
+			}
+
+			fragmentsSection.add("/fragments/"+fragment.Slug,
+				latestTime(fragment.PublishedAt, fragment.lastUpdatedAt()))
+			addTags(fragment.Tags, fragment.PublishedAt)
+		}
+		fragmentsSection.addIndexes("/fragments")
+	}
+
+	newsletterSections := make([]*sitemapSection, len(newsletterBuilds))
+	for i, nb := range newsletterBuilds {
+		newsletter := nb.newsletter
+		section := &sitemapSection{Name: newsletter.Slug}
+
+		for _, issue := range nb.issues {
+			if issue.Draft {
+				continue
+			}
+
+			section.add("/"+newsletter.Slug+"/"+issue.Slug, issue.PublishedAt)
+			addTags(issueTags(issue), issue.PublishedAt)
+		}
+		section.addIndexes("/" + newsletter.Slug)
+
+		newsletterSections[i] = section
+	}
+
+	// Photos are all on one page, so they're listed as its images. Note that
+	// robots.txt disallows them for most crawlers.
+	photosSection := &sitemapSection{Name: "photos"}
+	{
+		var lastMod time.Time
+		var images []string
+		for _, photo := range photos {
+			images = append(images, "/photographs/"+photo.Slug+"_large"+photo.TargetExt())
+			lastMod = latestTime(lastMod, photo.OccurredAt)
+		}
+		photosSection.add("/photos", lastMod, images...)
+	}
+
+	sequencesSection := &sitemapSection{Name: "sequences"}
+	{
+		for _, entry := range sequences {
+			var images []string
+			for _, photo := range entry.Photos {
+				images = append(images, "/photographs/sequences/"+photo.Slug+"_large"+photo.TargetExt())
+			}
+
+			sequencesSection.add("/sequences/"+entry.Slug, entry.PublishedAt, images...)
+		}
+		sequencesSection.addIndexes("/sequences")
+	}
+
+	tagsSection := &sitemapSection{Name: "tags"}
+	for _, definition := range tagDefinitions {
+		if lastMod, ok := tagsLastMod[definition.Tag]; ok {
+			tagsSection.add("/tags/"+string(definition.Tag), lastMod)
+		}
+	}
+
+	// Pages are everything else: the home page, pages from `./pages`, and a
+	// few one-off views. The home page shows recent content of every kind.
+	pagesSection := &sitemapSection{Name: "pages"}
+	{
+		var homeLastMod time.Time
+		for _, section := range append([]*sitemapSection{articlesSection, atomsSection, fragmentsSection, sequencesSection},
+			newsletterSections...) {
+			if lastMod := section.lastMod(); lastMod != nil {
+				homeLastMod = latestTime(homeLastMod, *lastMod)
+			}
+		}
+		pagesSection.add("/", homeLastMod)
+
+		for _, source := range pageSources {
+			info, err := os.Stat(source)
+			if err != nil {
+				return nil, xerrors.Errorf("error getting info on page source '%s': %w", source, err)
+			}
+
+			pagesSection.add(pageURLPath(pagePathKey(source)), info.ModTime().UTC())
+		}
+
+		for _, loc := range []string{"/reading", "/runs", "/twitter", "/twitter/with-replies"} {
+			pagesSection.add(loc, time.Time{})
+		}
+	}
+
+	sections := []*sitemapSection{articlesSection, atomsSection, fragmentsSection}
+	sections = append(sections, newsletterSections...)
+	sections = append(sections, pagesSection, photosSection, sequencesSection)
+
+	if len(tagsSection.URLs) > 0 {
+		sections = append(sections, tagsSection)
+	}
+
+	return sections, nil
+}
+
+// latestTime returns whichever of the given times is later.
+func latestTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// pageURLPath produces the path on the site for a page from its path key (see
+// pagePathKey). An index page is served at its directory's path.
+func pageURLPath(pagePath string) string {
+	if path.Base(pagePath) == "index" {
+		pagePath = path.Dir(pagePath)
+	}
+
+	if pagePath == "." {
+		return "/"
+	}
+
+	return "/" + pagePath
+}
+
+// renderSitemaps writes a sitemap for each section of the site to
+// `/sitemaps/` in the target directory, along with an index of them at
+// `/sitemap.xml`.
+func renderSitemaps(c *modulir.Context, articles []*Article, atoms []*Atom, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, pageSources []string, photos []*Photo,
+	sequences []*SequenceEntry, tagDefinitions []*TagDefinition, contentChanged bool,
+) (bool, error) {
+	if !contentChanged {
+		return false, nil
+	}
+
+	sections, err := newSitemapSections(articles, atoms, fragments, newsletterBuilds, pageSources, photos,
+		sequences, tagDefinitions)
+	if err != nil {
+		return true, err
+	}
+
+	sitemapsDir := path.Join(c.TargetDir, "sitemaps")
+	if err := os.MkdirAll(sitemapsDir, 0o755); err != nil {
+		return true, xerrors.Errorf("error creating directory '%s': %w", sitemapsDir, err)
+	}
+
+	index := &ssitemap.Index{}
+
+	for _, section := range sections {
+		urlSet := &ssitemap.URLSet{URLs: make([]*ssitemap.URL, len(section.URLs))}
+		for i, u := range section.URLs {
+			absoluteURL := *u
+			absoluteURL.Loc = conf.AbsoluteURL + u.Loc
+
+			absoluteURL.Images = make([]*ssitemap.Image, len(u.Images))
+			for j, image := range u.Images {
+				absoluteURL.Images[j] = &ssitemap.Image{Loc: conf.AbsoluteURL + image.Loc}
+			}
+
+			urlSet.URLs[i] = &absoluteURL
+		}
+
+		sitemapPath := "/sitemaps/" + section.Name + ".xml"
+		if err := writeSitemap(c.TargetDir+sitemapPath, urlSet.Encode); err != nil {
+			return true, err
+		}
+
+		index.Sitemaps = append(index.Sitemaps, &ssitemap.Sitemap{
+			Loc:     conf.AbsoluteURL + sitemapPath,
+			LastMod: section.lastMod(),
+		})
+	}
+
+	if err := writeSitemap(c.TargetDir+"/sitemap.xml", index.Encode); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// newSitemapURL produces a sitemap URL. lastMod and any images are optional.
+func newSitemapURL(loc string, lastMod time.Time, images ...string) *ssitemap.URL {
+	u := &ssitemap.URL{Loc: loc}
+
+	if !lastMod.IsZero() {
+		u.LastMod = &lastMod
+	}
+
+	for _, image := range images {
+		u.Images = append(u.Images, &ssitemap.Image{Loc: image})
+	}
+
+	return u
+}
+
+func writeSitemap(target string, encode func(w io.Writer, indent string) error) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return xerrors.Errorf("error creating sitemap '%s': %w", target, err)
+	}
+	defer f.Close()
+
+	if err := encode(f, "  "); err != nil {
+		return xerrors.Errorf("error writing sitemap '%s': %w", target, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+func TestNewSitemapSections(t *testing.T) {
+	articles, atoms, fragments, newsletterBuilds, pageSources, photos, sequences, tagDefinitions := testSitemapContent()
+
+	sections, err := newSitemapSections(articles, atoms, fragments, newsletterBuilds, pageSources, photos,
+		sequences, tagDefinitions)
+	assert.NoError(t, err)
+
+	locs := make(map[string][]string)
+	var names []string
+	for _, section := range sections {
+		names = append(names, section.Name)
+		for _, u := range section.URLs {
+			locs[section.Name] = append(locs[section.Name], u.Loc)
+		}
+	}
+
+	assert.Equal(t, []string{"articles", "atoms", "fragments", "nanoglyphs", "pages", "photos", "sequences", "tags"}, names)
+
+	// Drafts are left out.
+	assert.Equal(t, []string{"/articles", "/postgres"}, locs["articles"])
+	assert.Equal(t, []string{"/fragments", "/fragments/queues"}, locs["fragments"])
+	assert.Equal(t, []string{"/nanoglyphs", "/nanoglyphs/001-first"}, locs["nanoglyphs"])
+
+	assert.Equal(t, []string{"/atoms", "/atoms/archive", "/atoms/abc"}, locs["atoms"])
+	assert.Equal(t, []string{"/", "/about", "/belize/01", "/reading", "/runs", "/twitter", "/twitter/with-replies"},
+		locs["pages"])
+	assert.Equal(t, []string{"/photos"}, locs["photos"])
+	assert.Equal(t, []string{"/sequences", "/sequences/kyoto"}, locs["sequences"])
+
+	// The tag that's only on a draft is left out.
+	assert.Equal(t, []string{"/tags/postgres"}, locs["tags"])
+
+	sectionsByName := make(map[string]*sitemapSection)
+	for _, section := range sections {
+		sectionsByName[section.Name] = section
+	}
+
+	// Indexes are last modified along with their newest content, and the
+	// home page along with the newest content of any kind.
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *sectionsByName["articles"].URLs[0].LastMod)
+	assert.Equal(t, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), *sectionsByName["pages"].URLs[0].LastMod)
+
+	// Revised content is last modified when it was last updated.
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *sectionsByName["articles"].URLs[1].LastMod)
+	assert.Equal(t, time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC), *sectionsByName["fragments"].URLs[1].LastMod)
+
+	// Pages are last modified along with their sources.
+	info, err := os.Stat(pageSources[0])
+	assert.NoError(t, err)
+	assert.Equal(t, info.ModTime().UTC(), *sectionsByName["pages"].URLs[1].LastMod)
+	assert.Nil(t, sectionsByName["pages"].URLs[3].LastMod)
+
+	assert.Equal(t, "/photographs/atoms/abc/walk_large.jpg", sectionsByName["atoms"].URLs[2].Images[0].Loc)
+	assert.Equal(t, "/photographs/sequences/temple_large.jpg", sectionsByName["sequences"].URLs[1].Images[0].Loc)
+	assert.Equal(t, "/photographs/lake_large.jpg", sectionsByName["photos"].URLs[0].Images[0].Loc)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), *sectionsByName["photos"].URLs[0].LastMod)
+}
+
+func TestPageURLPath(t *testing.T) {
+	assert.Equal(t, "/about", pageURLPath("about"))
+	assert.Equal(t, "/belize/01", pageURLPath("belize/01"))
+	assert.Equal(t, "/belize", pageURLPath("belize/index"))
+	assert.Equal(t, "/", pageURLPath("index"))
+}
+
+func TestRenderSitemaps(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	articles, atoms, fragments, newsletterBuilds, pageSources, photos, sequences, tagDefinitions := testSitemapContent()
+
+	{
+		executed, err := renderSitemaps(c, articles, atoms, fragments, newsletterBuilds, pageSources, photos,
+			sequences, tagDefinitions, false)
+		assert.NoError(t, err)
+		assert.False(t, executed)
+		assert.NoFileExists(t, path.Join(c.TargetDir, "sitemap.xml"))
+	}
+
+	executed, err := renderSitemaps(c, articles, atoms, fragments, newsletterBuilds, pageSources, photos,
+		sequences, tagDefinitions, true)
+	assert.NoError(t, err)
+	assert.True(t, executed)
+
+	index, err := os.ReadFile(path.Join(c.TargetDir, "sitemap.xml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	assert.Contains(t, string(index), `
+  <sitemap>
+    <loc>`+conf.AbsoluteURL+`/sitemaps/articles.xml</loc>
+    <lastmod>2026-01-01T00:00:00Z</lastmod>
+  </sitemap>`)
+
+	articlesSitemap, err := os.ReadFile(path.Join(c.TargetDir, "sitemaps", "articles.xml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(articlesSitemap), `
+  <url>
+    <loc>`+conf.AbsoluteURL+`/postgres</loc>
+    <lastmod>2026-01-01T00:00:00Z</lastmod>
+  </url>`)
+
+	photosSitemap, err := os.ReadFile(path.Join(c.TargetDir, "sitemaps", "photos.xml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(photosSitemap), `
+    <image:image>
+      <image:loc>`+conf.AbsoluteURL+`/photographs/lake_large.jpg</image:loc>
+    </image:image>`)
+}
+
+func testSitemapContent() ([]*Article, []*Atom, []*Fragment, []*newsletterBuild,
+	[]string, []*Photo, []*SequenceEntry, []*TagDefinition,
+) {
+	articles := []*Article{
+		{
+			PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "postgres",
+			Tags:        []Tag{"postgres"},
+		},
+		{
+			Draft:       true,
+			PublishedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "draft",
+			Tags:        []Tag{"drafts"},
+		},
+	}
+
+	atoms := []*Atom{
+		{
+			Photos:      []*Photo{{OriginalImageURL: "https://example.com/walk.jpg", Slug: "walk"}},
+			PublishedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Slug:        "abc",
+		},
+	}
+
+	fragments := []*Fragment{
+		{
+			Changelog: []*ChangelogEntry{
+				{Description: "Revised.", UpdatedAt: time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)},
+			},
+			PublishedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+			Slug:        "queues",
+			Tags:        []Tag{"postgres"},
+		},
+		{
+			Draft:       true,
+			PublishedAt: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+			Slug:        "draft",
+		},
+	}
+
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{
+					PublishedAt: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+					Slug:        "001-first",
+				},
+				{
+					Draft:       true,
+					PublishedAt: time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC),
+					Slug:        "002-second",
+				},
+			},
+			newsletter: &Newsletter{Slug: "nanoglyphs"},
+		},
+	}
+
+	pageSources := []string{
+		"./pages/about.tmpl.html",
+		"./pages/belize/01.tmpl.html",
+	}
+
+	photos := []*Photo{
+		{OccurredAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), OriginalImageURL: "https://example.com/lake.jpg", Slug: "lake"},
+		{OccurredAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), OriginalImageURL: "https://example.com/hill.jpg", Slug: "hill"},
+	}
+
+	sequences := []*SequenceEntry{
+		{
+			Photos:      []*Photo{{OriginalImageURL: "https://example.com/temple.jpg", Slug: "temple"}},
+			PublishedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			Slug:        "kyoto",
+		},
+	}
+
+	tagDefinitions := []*TagDefinition{
+		{Tag: "drafts"},
+		{Tag: "postgres"},
+	}
+
+	return articles, atoms, fragments, newsletterBuilds, pageSources, photos, sequences, tagDefinitions
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"html/template"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mmarkdown"
+	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/sspring83"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// publishSpring83 publishes a board made from the latest atom to the Spring
+// '83 server at SPRING83_SERVER_URL.
+func publishSpring83(c *modulir.Context) {
+	ctx := context.Background()
+
+	client := &sspring83.Client{ServerURL: conf.Spring83ServerURL}
+
+	if err := publishSpring83Board(ctx, c, client, time.Now()); err != nil {
+		scommon.ExitWithError(err)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// loadAtoms reads, validates, and renders every atom outside of a build,
+// sorted newest first.
+func loadAtoms(c *modulir.Context) ([]*Atom, error) {
+	var atomsWrapper AtomWrapper
+	err := mtoml.ParseFile(c, c.SourceDir+"/content/atoms/_meta.toml", &atomsWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := atomsWrapper.validate(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(atomsWrapper.Atoms, func(a, b *Atom) int { return b.PublishedAt.Compare(a.PublishedAt) })
+
+	for _, atom := range atomsWrapper.Atoms {
+		atom.DescriptionHTML = template.HTML(string(mmarkdown.Render(c, []byte(atom.Description))))
+		atom.Slug = atomSlug(atom.PublishedAt)
+	}
+
+	return atomsWrapper.Atoms, nil
+}
+
+// loadSpring83Key parses the Spring '83 private key from configuration.
+func loadSpring83Key() (ed25519.PrivateKey, error) {
+	if conf.Spring83PrivateKey == "" {
+		return nil, xerrors.New("SPRING83_PRIVATE_KEY must be configured in the environment")
+	}
+
+	key, err := sspring83.ParseKey(conf.Spring83PrivateKey)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing SPRING83_PRIVATE_KEY: %w", err)
+	}
+
+	return key, nil
+}
+
+// newSpring83Board produces a Spring '83 board from the latest atom. Atoms
+// that are exempted from the length limit are skipped because they won't fit
+// in a board. Atoms are assumed to be sorted newest first and have their
+// descriptions rendered.
+func newSpring83Board(atoms []*Atom) (*Atom, []byte, error) {
+	for _, atom := range atoms {
+		if atom.LengthExempted {
+			continue
+		}
+
+		board, err := sspring83.NewBoard(string(atom.DescriptionHTML), atom.PublishedAt)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("error creating Spring '83 board for atom %s: %w", atom.Slug, err)
+		}
+
+		return atom, board, nil
+	}
+
+	return nil, nil, xerrors.New("no atom that fits in a Spring '83 board")
+}
+
+func publishSpring83Board(ctx context.Context, c *modulir.Context, client *sspring83.Client, now time.Time) error {
+	key, err := loadSpring83Key()
+	if err != nil {
+		return err
+	}
+
+	// Check the key before doing anything else because a server will reject
+	// a board signed by an expired key.
+	publicKeyHex := sspring83.PublicKeyHex(key)
+	if err := sspring83.ValidateKey(publicKeyHex, now); err != nil {
+		return err
+	}
+
+	atoms, err := loadAtoms(c)
+	if err != nil {
+		return err
+	}
+
+	// Like a build, leave out atoms that are scheduled for later, which
+	// servers would reject anyway because of their future timestamp.
+	atoms = slices.DeleteFunc(atoms, func(atom *Atom) bool {
+		return atom.PublishedAt.After(now)
+	})
+
+	atom, board, err := newSpring83Board(atoms)
+	if err != nil {
+		return err
+	}
+
+	err = client.Publish(ctx, key, board)
+	if errors.Is(err, sspring83.ErrConflict) {
+		c.Log.Infof("Server already has board for atom %s; nothing to publish", atom.Slug)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Log.Infof("Published board for atom %s to: %s/%s", atom.Slug, client.ServerURL, publicKeyHex)
+	return nil
+}
+
+// renderSpring83Board writes a signed Spring '83 board for the latest atom to
+// `spring83/board.html` in the target directory along with its signature in
+// `spring83/board.html.sig`. Does nothing unless a key is configured.
+func renderSpring83Board(c *modulir.Context, atoms []*Atom, atomsChanged bool) (bool, error) {
+	if conf.Spring83PrivateKey == "" || !atomsChanged {
+		return false, nil
+	}
+
+	key, err := loadSpring83Key()
+	if err != nil {
+		return true, err
+	}
+
+	_, board, err := newSpring83Board(atoms)
+	if err != nil {
+		return true, err
+	}
+
+	dir := path.Join(c.TargetDir, "spring83")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return true, xerrors.Errorf("error creating directory '%s': %w", dir, err)
+	}
+
+	// Like other built files, the board and its signature are world readable.
+	for filename, data := range map[string][]byte{
+		"board.html":     board,
+		"board.html.sig": []byte(sspring83.Sign(key, board) + "\n"),
+	} {
+		filePath := path.Join(dir, filename)
+		if err := os.WriteFile(filePath, data, 0o644); err != nil { //nolint:gosec
+			return true, xerrors.Errorf("error writing file '%s': %w", filePath, err)
+		}
+	}
+
+	return true, nil
+}
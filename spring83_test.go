@@ -0,0 +1,209 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/sspring83"
+)
+
+// A key whose public half ends in `83e0728`, making it valid through July
+// 2028.
+const testSpring83Key = "0000000000000000000000000000000000000000000000000000000000008f33"
+
+func TestLoadAtoms(t *testing.T) {
+	c := &modulir.Context{Log: &modulir.Logger{Level: modulir.LevelWarn}, SourceDir: "."}
+
+	atoms, err := loadAtoms(c)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, atoms)
+
+	for i, atom := range atoms {
+		assert.NotEmpty(t, atom.DescriptionHTML)
+		assert.Equal(t, atomSlug(atom.PublishedAt), atom.Slug)
+
+		if i > 0 {
+			assert.False(t, atom.PublishedAt.After(atoms[i-1].PublishedAt))
+		}
+	}
+}
+
+func TestNewSpring83Board(t *testing.T) {
+	atoms := []*Atom{
+		{DescriptionHTML: "<p>Long.</p>", LengthExempted: true, PublishedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), Slug: "c"},
+		{DescriptionHTML: "<p>Latest.</p>", PublishedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Slug: "b"},
+		{DescriptionHTML: "<p>Older.</p>", PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Slug: "a"},
+	}
+
+	{
+		atom, board, err := newSpring83Board(atoms)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", atom.Slug)
+		assert.Equal(t, `<time datetime="2026-01-02T00:00:00Z"></time>`+"\n<p>Latest.</p>", string(board))
+	}
+
+	{
+		_, _, err := newSpring83Board(atoms[0:1])
+		assert.EqualError(t, err, "no atom that fits in a Spring '83 board")
+	}
+
+	{
+		tooLong := &Atom{DescriptionHTML: template.HTML(strings.Repeat("a", sspring83.MaxBoardLength)), Slug: "d"}
+		_, _, err := newSpring83Board([]*Atom{tooLong})
+		assert.ErrorContains(t, err, "error creating Spring '83 board for atom d: board is greater than 2217 bytes")
+	}
+}
+
+func TestPublishSpring83Board(t *testing.T) {
+	var (
+		c   = &modulir.Context{Log: &modulir.Logger{Level: modulir.LevelWarn}, SourceDir: "."}
+		ctx = t.Context()
+		now = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	// A stand-in server that keeps one board and, like a real one, refuses a
+	// board that's not newer than the one it has.
+	var (
+		lastBoard []byte
+		received  = make(map[string]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		board, _ := io.ReadAll(r.Body)
+		sig, _ := hex.DecodeString(r.Header.Get("Spring-Signature"))
+		pub, _ := hex.DecodeString(strings.TrimPrefix(r.URL.Path, "/"))
+
+		if !ed25519.Verify(pub, board, sig) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if string(board) == string(lastBoard) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		lastBoard = board
+		received[r.URL.Path] = string(board)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &sspring83.Client{ServerURL: server.URL}
+
+	{
+		conf.Spring83PrivateKey = ""
+		err := publishSpring83Board(ctx, c, client, now)
+		assert.EqualError(t, err, "SPRING83_PRIVATE_KEY must be configured in the environment")
+	}
+
+	conf.Spring83PrivateKey = testSpring83Key
+
+	{
+		err := publishSpring83Board(ctx, c, client, time.Date(2028, 8, 1, 0, 0, 0, 0, time.UTC))
+		assert.ErrorContains(t, err, "key expired at 2028-08-01T00:00:00Z")
+	}
+
+	assert.NoError(t, publishSpring83Board(ctx, c, client, now))
+	assert.Len(t, received, 1)
+	for path, board := range received {
+		assert.True(t, strings.HasSuffix(path, "83e0728"))
+		assert.True(t, strings.HasPrefix(board, `<time datetime="`))
+	}
+
+	// Publishing the same board again is a no-op.
+	assert.NoError(t, publishSpring83Board(ctx, c, client, now))
+}
+
+func TestPublishSpring83BoardScheduled(t *testing.T) {
+	var (
+		c   = &modulir.Context{Log: &modulir.Logger{Level: modulir.LevelWarn}, SourceDir: t.TempDir()}
+		ctx = t.Context()
+		now = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+	conf.Spring83PrivateKey = testSpring83Key
+
+	assert.NoError(t, os.MkdirAll(path.Join(c.SourceDir, "content/atoms"), 0o755))
+	assert.NoError(t, os.WriteFile(path.Join(c.SourceDir, "content/atoms/_meta.toml"), []byte(`
+[[atoms]]
+description = "Scheduled."
+published_at = 2027-06-01T00:00:00Z
+
+[[atoms]]
+description = "Published."
+published_at = 2026-06-01T00:00:00Z
+`), 0o600))
+
+	var board string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		board = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The scheduled atom is left out.
+	assert.NoError(t, publishSpring83Board(ctx, c, &sspring83.Client{ServerURL: server.URL}, now))
+	assert.Equal(t, `<time datetime="2026-06-01T00:00:00Z"></time>`+"\n<p>Published.</p>\n", board)
+}
+
+func TestRenderSpring83Board(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	oldConf := conf
+	defer func() {
+		conf = oldConf
+	}()
+
+	atoms := []*Atom{
+		{DescriptionHTML: "<p>Latest.</p>", PublishedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Slug: "b"},
+	}
+
+	{
+		conf.Spring83PrivateKey = ""
+		executed, err := renderSpring83Board(c, atoms, true)
+		assert.NoError(t, err)
+		assert.False(t, executed)
+		assert.NoDirExists(t, path.Join(c.TargetDir, "spring83"))
+	}
+
+	conf.Spring83PrivateKey = testSpring83Key
+
+	{
+		executed, err := renderSpring83Board(c, atoms, true)
+		assert.NoError(t, err)
+		assert.True(t, executed)
+
+		board, err := os.ReadFile(path.Join(c.TargetDir, "spring83/board.html"))
+		assert.NoError(t, err)
+		assert.Equal(t, `<time datetime="2026-01-02T00:00:00Z"></time>`+"\n<p>Latest.</p>", string(board))
+
+		sig, err := os.ReadFile(path.Join(c.TargetDir, "spring83/board.html.sig"))
+		assert.NoError(t, err)
+
+		key, err := sspring83.ParseKey(testSpring83Key)
+		assert.NoError(t, err)
+		assert.Equal(t, sspring83.Sign(key, board)+"\n", string(sig))
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/sstats"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// printStats prints statistics about the content of a built site in
+// TARGET_DIR totaled by year and by kind of content, along with the longest
+// pieces. Only content published in the given year is included unless it's
+// zero.
+func printStats(year, limit int) {
+	entries, err := loadContentStats(conf.TargetDir)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	if year != 0 {
+		entries = slices.DeleteFunc(entries, func(entry *contentStatsEntry) bool {
+			return entry.PublishedAt.Year() != year
+		})
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No content")
+		return
+	}
+
+	if err := printContentStats(os.Stdout, entries, limit); err != nil {
+		scommon.ExitWithError(err)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// contentStatsEntry is the statistics of a single piece of content.
+type contentStatsEntry struct {
+	// Kind is a human-readable name for the type of the content, like
+	// "Article".
+	Kind string `json:"kind"`
+
+	// PublishedAt is when the content was published.
+	PublishedAt time.Time `json:"published_at"`
+
+	// Stats are the content's statistics.
+	Stats *sstats.Stats `json:"stats"`
+
+	// Title is the content's title.
+	Title string `json:"title"`
+
+	// URL is the content's path on the site.
+	URL string `json:"url"`
+}
+
+// contentStatsTotals is the sum of the statistics of a number of pieces of
+// content.
+type contentStatsTotals struct {
+	sstats.Stats
+
+	// Pieces is the number of pieces of content.
+	Pieces int
+}
+
+func (t *contentStatsTotals) add(stats *sstats.Stats) {
+	t.CodeBlocks += stats.CodeBlocks
+	t.Footnotes += stats.Footnotes
+	t.Images += stats.Images
+	t.Pieces++
+	t.ReadingTime += stats.ReadingTime
+	t.Words += stats.Words
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Name of the file in the target directory that content statistics are
+// written to for the `stats` command.
+const contentStatsFilename = ".stats.json"
+
+// formatReadingTime formats a total reading time in hours and minutes like
+// "3h 05m".
+func formatReadingTime(d time.Duration) string {
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", hours, minutes)
+}
+
+// loadContentStats loads the content statistics of a site built to the given
+// target directory.
+func loadContentStats(targetDir string) ([]*contentStatsEntry, error) {
+	data, err := os.ReadFile(path.Join(targetDir, contentStatsFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, xerrors.Errorf("no content statistics in %q (has the site been built?)", targetDir)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("error reading content statistics: %w", err)
+	}
+
+	var entries []*contentStatsEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, xerrors.Errorf("error unmarshaling content statistics: %w", err)
+	}
+
+	return entries, nil
+}
+
+// newContentStats produces statistics for every published article, fragment,
+// and newsletter issue, sorted newest first. Content must already have been
+// rendered.
+func newContentStats(articles []*Article, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild,
+) []*contentStatsEntry {
+	var entries []*contentStatsEntry
+
+	for _, article := range articles {
+		if article.Draft {
+			continue
+		}
+
+		entries = append(entries, &contentStatsEntry{
+			Kind:        "Article",
+			PublishedAt: article.PublishedAt,
+			Stats:       article.Stats,
+			Title:       article.Title,
+			URL:         "/" + article.Slug,
+		})
+	}
+
+	for _, fragment := range fragments {
+		if fragment.Draft {
+			continue
+		}
+
+		entries = append(entries, &contentStatsEntry{
+			Kind:        "Fragment",
+			PublishedAt: fragment.PublishedAt,
+			Stats:       fragment.Stats,
+			Title:       fragment.Title,
+			URL:         "/fragments/" + fragment.Slug,
+		})
+	}
+
+	for _, nb := range newsletterBuilds {
+		for _, issue := range nb.issues {
+			if issue.Draft {
+				continue
+			}
+
+			entries = append(entries, &contentStatsEntry{
+				Kind:        nb.newsletter.Name,
+				PublishedAt: issue.PublishedAt,
+				Stats:       issue.Stats,
+				Title:       fmt.Sprintf(nb.newsletter.TitleFormat, issue.Number, issue.Title),
+				URL:         "/" + nb.newsletter.Slug + "/" + issue.Slug,
+			})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *contentStatsEntry) int {
+		if c := b.PublishedAt.Compare(a.PublishedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	return entries
+}
+
+// printContentStats prints tables of the given content's statistics totaled
+// by year and by kind of content, followed by the given number of its longest
+// pieces. A limit of zero or less shows every piece.
+func printContentStats(w io.Writer, entries []*contentStatsEntry, limit int) error {
+	type yearKind struct {
+		kind string
+		year int
+	}
+
+	var (
+		byKind     = make(map[string]*contentStatsTotals)
+		byYearKind = make(map[yearKind]*contentStatsTotals)
+		total      contentStatsTotals
+	)
+
+	for _, entry := range entries {
+		key := yearKind{kind: entry.Kind, year: entry.PublishedAt.Year()}
+		if byYearKind[key] == nil {
+			byYearKind[key] = &contentStatsTotals{}
+		}
+		byYearKind[key].add(entry.Stats)
+
+		if byKind[entry.Kind] == nil {
+			byKind[entry.Kind] = &contentStatsTotals{}
+		}
+		byKind[entry.Kind].add(entry.Stats)
+
+		total.add(entry.Stats)
+	}
+
+	// Newest years first, and kinds in alphabetical order within a year.
+	yearKinds := make([]yearKind, 0, len(byYearKind))
+	for key := range byYearKind {
+		yearKinds = append(yearKinds, key)
+	}
+	slices.SortFunc(yearKinds, func(a, b yearKind) int {
+		return cmp.Or(cmp.Compare(b.year, a.year), strings.Compare(a.kind, b.kind))
+	})
+
+	kinds := make([]string, 0, len(byKind))
+	for kind := range byKind {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+
+	longest := slices.Clone(entries)
+	slices.SortStableFunc(longest, func(a, b *contentStatsEntry) int {
+		return cmp.Compare(b.Stats.Words, a.Stats.Words)
+	})
+	if limit > 0 && len(longest) > limit {
+		longest = longest[0:limit]
+	}
+
+	totalsColumns := "PIECES\tWORDS\tREADING TIME\tCODE BLOCKS\tIMAGES\tFOOTNOTES"
+	printTotals := func(tw io.Writer, label string, totals *contentStatsTotals) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%d\t%d\n",
+			label,
+			totals.Pieces,
+			totals.Words,
+			formatReadingTime(totals.ReadingTime),
+			totals.CodeBlocks,
+			totals.Images,
+			totals.Footnotes)
+	}
+
+	for i, printTable := range []func(tw io.Writer){
+		func(tw io.Writer) {
+			fmt.Fprintln(tw, "YEAR\tKIND\t"+totalsColumns)
+			for _, key := range yearKinds {
+				printTotals(tw, fmt.Sprintf("%d\t%s", key.year, key.kind), byYearKind[key])
+			}
+		},
+		func(tw io.Writer) {
+			fmt.Fprintln(tw, "KIND\t"+totalsColumns)
+			for _, kind := range kinds {
+				printTotals(tw, kind, byKind[kind])
+			}
+			printTotals(tw, "Total", &total)
+		},
+		func(tw io.Writer) {
+			fmt.Fprintln(tw, "LONGEST\tWORDS\tREADING TIME\tKIND\tPUBLISHED\tURL")
+			for i, entry := range longest {
+				fmt.Fprintf(tw, "%d. %s\t%d\t%s\t%s\t%s\t%s\n",
+					i+1,
+					entry.Title,
+					entry.Stats.Words,
+					formatReadingTime(entry.Stats.ReadingTime),
+					entry.Kind,
+					entry.PublishedAt.Format("2006-01-02"),
+					entry.URL)
+			}
+		},
+	} {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		printTable(tw)
+		if err := tw.Flush(); err != nil {
+			return xerrors.Errorf("error printing content statistics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// renderContentStats writes statistics for all published articles, fragments,
+// and newsletter issues to the target directory for the `stats` command.
+func renderContentStats(c *modulir.Context, articles []*Article, fragments []*Fragment,
+	newsletterBuilds []*newsletterBuild, contentChanged bool,
+) (bool, error) {
+	if !contentChanged {
+		return false, nil
+	}
+
+	data, err := json.Marshal(newContentStats(articles, fragments, newsletterBuilds))
+	if err != nil {
+		return true, xerrors.Errorf("error marshaling content statistics: %w", err)
+	}
+
+	target := path.Join(c.TargetDir, contentStatsFilename)
+
+	tmpTarget := target + ".tmp"
+	if err := os.WriteFile(tmpTarget, data, 0o600); err != nil {
+		return true, xerrors.Errorf("error writing content statistics: %w", err)
+	}
+
+	if err := os.Rename(tmpTarget, target); err != nil {
+		return true, xerrors.Errorf("error renaming content statistics: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"path"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+	"github.com/brandur/sorg/modules/sstats"
+)
+
+func TestFormatReadingTime(t *testing.T) {
+	assert.Equal(t, "0m", formatReadingTime(0))
+	assert.Equal(t, "45m", formatReadingTime(45*time.Minute))
+	assert.Equal(t, "1h 00m", formatReadingTime(60*time.Minute))
+	assert.Equal(t, "31h 05m", formatReadingTime(31*time.Hour+5*time.Minute))
+}
+
+func TestNewContentStats(t *testing.T) {
+	articles, fragments, newsletterBuilds := testContentStatsContent()
+
+	entries := newContentStats(articles, fragments, newsletterBuilds)
+
+	var urls []string
+	for _, entry := range entries {
+		urls = append(urls, entry.URL)
+	}
+
+	// Drafts are left out, and everything else is sorted newest first.
+	assert.Equal(t, []string{"/nanoglyphs/001-first", "/fragments/queues", "/postgres"}, urls)
+
+	assert.Equal(t, "Nanoglyph", entries[0].Kind)
+	assert.Equal(t, "Nanoglyph 001 — First", entries[0].Title)
+	assert.Equal(t, 1500, entries[0].Stats.Words)
+}
+
+func TestPrintContentStats(t *testing.T) {
+	articles, fragments, newsletterBuilds := testContentStatsContent()
+
+	var b bytes.Buffer
+	assert.NoError(t, printContentStats(&b, newContentStats(articles, fragments, newsletterBuilds), 2))
+	assert.Equal(t, `YEAR  KIND       PIECES  WORDS  READING TIME  CODE BLOCKS  IMAGES  FOOTNOTES
+2026  Fragment   1       500    3m            0            0       0
+2026  Nanoglyph  1       1500   7m            0            4       2
+2025  Article    1       3000   14m           5            2       1
+
+KIND       PIECES  WORDS  READING TIME  CODE BLOCKS  IMAGES  FOOTNOTES
+Article    1       3000   14m           5            2       1
+Fragment   1       500    3m            0            0       0
+Nanoglyph  1       1500   7m            0            4       2
+Total      3       5000   24m           5            6       3
+
+LONGEST                   WORDS  READING TIME  KIND       PUBLISHED   URL
+1. Postgres               3000   14m           Article    2025-06-01  /postgres
+2. Nanoglyph 001 — First  1500   7m            Nanoglyph  2026-03-01  /nanoglyphs/001-first
+`, b.String())
+
+	// No limit shows every piece.
+	for _, limit := range []int{-1, 0} {
+		b.Reset()
+		assert.NoError(t, printContentStats(&b, newContentStats(articles, fragments, newsletterBuilds), limit))
+		assert.Contains(t, b.String(), "3. Queues")
+	}
+}
+
+func TestRenderContentStats(t *testing.T) {
+	c := &modulir.Context{TargetDir: t.TempDir()}
+
+	articles, fragments, newsletterBuilds := testContentStatsContent()
+
+	{
+		executed, err := renderContentStats(c, articles, fragments, newsletterBuilds, false)
+		assert.NoError(t, err)
+		assert.False(t, executed)
+		assert.NoFileExists(t, path.Join(c.TargetDir, contentStatsFilename))
+
+		_, err = loadContentStats(c.TargetDir)
+		assert.ErrorContains(t, err, "has the site been built?")
+	}
+
+	{
+		executed, err := renderContentStats(c, articles, fragments, newsletterBuilds, true)
+		assert.NoError(t, err)
+		assert.True(t, executed)
+	}
+
+	entries, err := loadContentStats(c.TargetDir)
+	assert.NoError(t, err)
+	assert.Equal(t, newContentStats(articles, fragments, newsletterBuilds), entries)
+}
+
+func testContentStatsContent() ([]*Article, []*Fragment, []*newsletterBuild) {
+	articles := []*Article{
+		{
+			PublishedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "postgres",
+			Stats:       &sstats.Stats{CodeBlocks: 5, Footnotes: 1, Images: 2, ReadingTime: 14 * time.Minute, Words: 3000},
+			Title:       "Postgres",
+		},
+		{
+			Draft:       true,
+			PublishedAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "draft",
+			Stats:       &sstats.Stats{ReadingTime: 44 * time.Minute, Words: 10000},
+			Title:       "Draft",
+		},
+	}
+	fragments := []*Fragment{
+		{
+			PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Slug:        "queues",
+			Stats:       &sstats.Stats{ReadingTime: 3 * time.Minute, Words: 500},
+			Title:       "Queues",
+		},
+	}
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{
+					Number:      "001",
+					PublishedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+					Slug:        "001-first",
+					Stats:       &sstats.Stats{Footnotes: 2, Images: 4, ReadingTime: 7 * time.Minute, Words: 1500},
+					Title:       "First",
+				},
+			},
+			newsletter: &Newsletter{Name: "Nanoglyph", Slug: "nanoglyphs", TitleFormat: "Nanoglyph %s — %s"},
+		},
+	}
+
+	return articles, fragments, newsletterBuilds
+}
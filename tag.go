@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"path"
+	"regexp"
+	"slices"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Tag is a symbol assigned to content to categorize it.
+//
+// This feature is not meant to be overused. Tags have to be in the allowlist
+// in `content/tags.toml`, and each one gets an index page at `/tags/<tag>`
+// along with feeds like `/articles-<tag>.atom` for aggregators that only want
+// a particular subject (so far just Planet Postgres).
+type Tag string
+
+// TagDefinition is a tag in the allowlist.
+type TagDefinition struct {
+	// Description is an optional sentence or two about the tag, shown on its
+	// index page.
+	Description string `toml:"description"`
+
+	// Tag is the tag itself, as it appears in content frontmatter and URLs.
+	Tag Tag `toml:"tag" validate:"required"`
+
+	// Title is a human-readable name for the tag, like "Postgres".
+	Title string `toml:"title" validate:"required"`
+}
+
+type TagDefinitionWrapper struct {
+	Tags []*TagDefinition `toml:"tags" validate:"required,dive"`
+}
+
+func (w *TagDefinitionWrapper) validate() error {
+	if err := validate.Struct(w); err != nil {
+		return xerrors.Errorf("error validating tags: %w", err)
+	}
+
+	tags := make(map[Tag]struct{}, len(w.Tags))
+	for _, definition := range w.Tags {
+		if !tagRE.MatchString(string(definition.Tag)) {
+			return xerrors.Errorf("tag should contain only lowercase letters, numbers, and dashes: %s",
+				definition.Tag)
+		}
+
+		if _, ok := tags[definition.Tag]; ok {
+			return xerrors.Errorf("duplicate tag: %s", definition.Tag)
+		}
+		tags[definition.Tag] = struct{}{}
+	}
+
+	return nil
+}
+
+// tagBuild holds all the content with a particular tag during a build.
+type tagBuild struct {
+	definition *TagDefinition
+
+	// feeds are the names of the tag-specific feeds that have at least one
+	// entry, like `articles-postgres`.
+	feeds []string
+
+	// items are every piece of tagged content, sorted newest first.
+	items []*taggedItem
+}
+
+// hasFeed returns true if the tag-specific feed with the given name has at
+// least one entry.
+func (b *tagBuild) hasFeed(name string) bool {
+	return slices.Contains(b.feeds, name)
+}
+
+// taggedItem is a piece of content of any kind that's been tagged, for
+// display on a tag's index page.
+type taggedItem struct {
+	// Hook is an optional introduction to the content.
+	Hook template.HTML
+
+	// Kind is a human-readable name for the type of content, like "Article".
+	Kind string
+
+	// PublishedAt is when the content was published.
+	PublishedAt time.Time
+
+	// Title is the content's title.
+	Title string
+
+	// URL is the content's path on the site.
+	URL string
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Tags must be slugs because they're used in URLs.
+var tagRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// checkTags returns an error if any of the given tags isn't in the allowlist.
+func checkTags(definitions []*TagDefinition, tags []Tag) error {
+	for _, tag := range tags {
+		if findTagDefinition(definitions, tag) == nil {
+			return xerrors.Errorf("tag %q isn't in the allowlist in %s", tag, tagsFilename)
+		}
+	}
+	return nil
+}
+
+func findTagDefinition(definitions []*TagDefinition, tag Tag) *TagDefinition {
+	for _, definition := range definitions {
+		if definition.Tag == tag {
+			return definition
+		}
+	}
+	return nil
+}
+
+// groupTaggedContent discovers the tags in use across all content, producing
+// a tagBuild for each one in the same order as the allowlist. Returns an error
+// if any content is tagged with something that's not in the allowlist.
+func groupTaggedContent(definitions []*TagDefinition, articles []*Article, atoms []*Atom,
+	fragments []*Fragment, newsletterBuilds []*newsletterBuild,
+) ([]*tagBuild, error) {
+	builds := make(map[Tag]*tagBuild)
+
+	add := func(description string, tags []Tag, feedBase string, item *taggedItem) error {
+		if err := checkTags(definitions, tags); err != nil {
+			return xerrors.Errorf("error checking tags of %s: %w", description, err)
+		}
+
+		for _, tag := range tags {
+			build, ok := builds[tag]
+			if !ok {
+				build = &tagBuild{definition: findTagDefinition(definitions, tag)}
+				builds[tag] = build
+			}
+
+			feed := tagFeedName(feedBase, tag)
+			if !build.hasFeed(feed) {
+				build.feeds = append(build.feeds, feed)
+			}
+
+			build.items = append(build.items, item)
+		}
+
+		return nil
+	}
+
+	for _, article := range articles {
+		err := add("article "+article.Slug, article.Tags, "articles", &taggedItem{
+			Hook:        article.Hook,
+			Kind:        "Article",
+			PublishedAt: article.PublishedAt,
+			Title:       article.Title,
+			URL:         "/" + article.Slug,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, atom := range atoms {
+		err := add("atom "+atom.Slug, atom.Tags, "atoms", &taggedItem{
+			Kind:        "Atom",
+			PublishedAt: atom.PublishedAt,
+			Title:       atom.displayTitle(),
+			URL:         "/atoms/" + atom.Slug,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fragment := range fragments {
+		err := add("fragment "+fragment.Slug, fragment.Tags, "fragments", &taggedItem{
+			Hook:        fragment.Hook,
+			Kind:        "Fragment",
+			PublishedAt: fragment.PublishedAt,
+			Title:       fragment.Title,
+			URL:         "/fragments/" + fragment.Slug,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, nb := range newsletterBuilds {
+		newsletter := nb.newsletter
+
+		for _, issue := range nb.issues {
+			err := add(newsletter.Kind+" "+issue.Slug, issueTags(issue), newsletter.Slug, &taggedItem{
+				Hook:        issue.Hook,
+				Kind:        newsletter.Name,
+				PublishedAt: issue.PublishedAt,
+				Title:       issue.Title,
+				URL:         "/" + newsletter.Slug + "/" + issue.Slug,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var tagBuilds []*tagBuild
+	for _, definition := range definitions {
+		build, ok := builds[definition.Tag]
+		if !ok {
+			continue
+		}
+
+		slices.SortStableFunc(build.items, func(a, b *taggedItem) int { return b.PublishedAt.Compare(a.PublishedAt) })
+		tagBuilds = append(tagBuilds, build)
+	}
+
+	return tagBuilds, nil
+}
+
+// issueTags gets a newsletter issue's tags, which the snewsletter package
+// keeps as plain strings.
+func issueTags(issue *snewsletter.Issue) []Tag {
+	tags := make([]Tag, len(issue.Tags))
+	for i, tag := range issue.Tags {
+		tags[i] = Tag(tag)
+	}
+	return tags
+}
+
+func loadTagDefinitions(c *modulir.Context) ([]*TagDefinition, error) {
+	var tagsWrapper TagDefinitionWrapper
+	err := mtoml.ParseFile(c, c.SourceDir+"/"+tagsFilename, &tagsWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tagsWrapper.validate(); err != nil {
+		return nil, err
+	}
+
+	return tagsWrapper.Tags, nil
+}
+
+func renderTag(ctx context.Context, c *modulir.Context, tb *tagBuild, contentChanged bool) (bool, error) {
+	sourceTmpl := scommon.ViewsDir + "/tags/show.tmpl.html"
+	viewsChanged := dependencies.viewChanged(c, sourceTmpl)
+	if !contentChanged && !viewsChanged {
+		return false, nil
+	}
+
+	locals := getLocals(map[string]any{
+		"Feeds": tb.feeds,
+		"Items": tb.items,
+		"Tag":   tb.definition,
+	})
+
+	return true, dependencies.renderGoTemplate(ctx, c, sourceTmpl,
+		path.Join(c.TargetDir, "tags", string(tb.definition.Tag)), locals)
+}
+
+// tagStrings converts tags to plain strings, as used in feeds.
+func tagStrings(tags []Tag) []string {
+	strs := make([]string, len(tags))
+	for i, tag := range tags {
+		strs[i] = string(tag)
+	}
+	return strs
+}
+
+// tagFeedName is the name of a feed that's been filtered to a tag, like
+// `articles-postgres` for `articles`.
+func tagFeedName(name string, tag Tag) string {
+	return name + "-" + string(tag)
+}
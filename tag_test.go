@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+func TestCheckTags(t *testing.T) {
+	definitions := []*TagDefinition{{Tag: "postgres", Title: "Postgres"}}
+
+	require.NoError(t, checkTags(definitions, nil))
+	require.NoError(t, checkTags(definitions, []Tag{"postgres"}))
+	require.EqualError(t, checkTags(definitions, []Tag{"postgres", "mysql"}),
+		`tag "mysql" isn't in the allowlist in content/tags.toml`)
+}
+
+func TestGroupTaggedContent(t *testing.T) {
+	var (
+		postgres = &TagDefinition{Tag: "postgres", Title: "Postgres"}
+		ruby     = &TagDefinition{Tag: "ruby", Title: "Ruby"}
+		unused   = &TagDefinition{Tag: "unused", Title: "Unused"}
+	)
+
+	definitions := []*TagDefinition{postgres, ruby, unused}
+
+	articles := []*Article{
+		{Slug: "postgres-queues", PublishedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), Tags: []Tag{"postgres"}},
+		{Slug: "untagged", PublishedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	atoms := []*Atom{
+		{Slug: "abc", PublishedAt: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC), Tags: []Tag{"postgres", "ruby"}},
+	}
+	fragments := []*Fragment{
+		{Slug: "rails", PublishedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Tags: []Tag{"ruby"}},
+	}
+	newsletterBuilds := []*newsletterBuild{
+		{
+			issues: []*snewsletter.Issue{
+				{Slug: "001-first", PublishedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Tags: []string{"postgres"}},
+			},
+			newsletter: &Newsletter{Kind: "nanoglyph", Name: "Nanoglyph", Slug: "nanoglyphs"},
+		},
+	}
+
+	tagBuilds, err := groupTaggedContent(definitions, articles, atoms, fragments, newsletterBuilds)
+	require.NoError(t, err)
+
+	// In allowlist order, and only tags that are in use.
+	require.Len(t, tagBuilds, 2)
+
+	{
+		tb := tagBuilds[0]
+		require.Equal(t, postgres, tb.definition)
+		require.Equal(t, []string{"articles-postgres", "atoms-postgres", "nanoglyphs-postgres"}, tb.feeds)
+
+		// Newest first.
+		var urls []string
+		for _, item := range tb.items {
+			urls = append(urls, item.URL)
+		}
+		require.Equal(t, []string{"/nanoglyphs/001-first", "/atoms/abc", "/postgres-queues"}, urls)
+		require.Equal(t, "Nanoglyph", tb.items[0].Kind)
+		require.Equal(t, "Atom #abc", tb.items[1].Title)
+	}
+
+	{
+		tb := tagBuilds[1]
+		require.Equal(t, ruby, tb.definition)
+		require.Equal(t, []string{"atoms-ruby", "fragments-ruby"}, tb.feeds)
+		require.True(t, tb.hasFeed("fragments-ruby"))
+		require.False(t, tb.hasFeed("articles-ruby"))
+		require.Len(t, tb.items, 2)
+	}
+
+	fragments[0].Tags = append(fragments[0].Tags, "mysql")
+	_, err = groupTaggedContent(definitions, articles, atoms, fragments, newsletterBuilds)
+	require.EqualError(t, err,
+		`error checking tags of fragment rails: tag "mysql" isn't in the allowlist in content/tags.toml`)
+}
+
+func TestLoadTagDefinitions(t *testing.T) {
+	c := modulir.NewContext(&modulir.Args{Log: &modulir.Logger{Level: modulir.LevelInfo}, SourceDir: "."})
+
+	definitions, err := loadTagDefinitions(c)
+	require.NoError(t, err)
+	require.NotNil(t, findTagDefinition(definitions, "postgres"))
+}
+
+func TestTagDefinitionWrapperValidate(t *testing.T) {
+	require.NoError(t, (&TagDefinitionWrapper{Tags: []*TagDefinition{
+		{Tag: "postgres", Title: "Postgres"},
+		{Tag: "stripe-api", Title: "Stripe API"},
+	}}).validate())
+
+	require.EqualError(t, (&TagDefinitionWrapper{Tags: []*TagDefinition{
+		{Tag: "postgres", Title: "Postgres"},
+		{Tag: "postgres", Title: "Postgres"},
+	}}).validate(), "duplicate tag: postgres")
+
+	require.EqualError(t, (&TagDefinitionWrapper{Tags: []*TagDefinition{
+		{Tag: "Postgres", Title: "Postgres"},
+	}}).validate(), "tag should contain only lowercase letters, numbers, and dashes: Postgres")
+
+	require.ErrorContains(t, (&TagDefinitionWrapper{Tags: []*TagDefinition{
+		{Tag: "postgres"},
+	}}).validate(), "title")
+}
+
+func TestTagFeedName(t *testing.T) {
+	require.Equal(t, "articles-postgres", tagFeedName("articles", "postgres"))
+}
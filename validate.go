@@ -0,0 +1,432 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+	"github.com/brandur/modulir/modules/mfile"
+	"github.com/brandur/modulir/modules/mmarkdown"
+	"github.com/brandur/modulir/modules/mtoml"
+	"github.com/brandur/sorg/modules/scommon"
+	"github.com/brandur/sorg/modules/snewsletter"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Public
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// validateAll parses and validates every piece of content without rendering
+// any of it, printing a report of every problem found. Exits with a non-zero
+// status if there were any problems.
+func validateAll(c *modulir.Context) {
+	problems, err := validateContent(c)
+	if err != nil {
+		scommon.ExitWithError(err)
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+	}
+
+	if len(problems) > 0 {
+		scommon.ExitWithError(xerrors.Errorf("found %d problem(s) in content", len(problems)))
+	}
+
+	c.Log.Infof("All content valid")
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// contentAllowlists are the allowlists that content frontmatter is checked
+// against.
+type contentAllowlists struct {
+	series []*SeriesDefinition
+	tags   []*TagDefinition
+}
+
+// validationProblem is a single problem found in a content source.
+type validationProblem struct {
+	// Err is the problem that was found.
+	Err error
+
+	// Line is the line in the source where the problem was found. It's a best
+	// effort value, and will be zero if no line could be determined.
+	Line int
+
+	// Source is the path to the source file where the problem was found.
+	Source string
+}
+
+func (p *validationProblem) String() string {
+	source := filepath.Clean(p.Source)
+
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", source, p.Line, p.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", source, p.Err)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// validateContent checks the tag and series allowlists, redirects, and every
+// article, fragment, newsletter issue, atom, sequence entry, and photo, and
+// returns all problems found. An error is only returned in case of a problem
+// reading content directories.
+func validateContent(c *modulir.Context) ([]*validationProblem, error) {
+	var problems []*validationProblem
+
+	//
+	// Tags (`tags.toml`)
+	//
+
+	tagDefinitions, err := loadTagDefinitions(c)
+	problems = append(problems, validationProblems(c.SourceDir+"/"+tagsFilename, 0, err)...)
+
+	//
+	// Series (`series.toml`)
+	//
+
+	seriesDefinitions, err := loadSeriesDefinitions(c)
+	problems = append(problems, validationProblems(c.SourceDir+"/"+seriesFilename, 0, err)...)
+
+	//
+	// Redirects (`redirects.toml`)
+	//
+
+	_, err = loadRedirects(c)
+	problems = append(problems, validationProblems(c.SourceDir+"/"+redirectsFilename, 0, err)...)
+
+	//
+	// Articles, fragments, and newsletters (`newsletters.toml` and
+	// frontmatter)
+	//
+
+	{
+		allowlists := &contentAllowlists{series: seriesDefinitions, tags: tagDefinitions}
+
+		type frontmatterDir struct {
+			dir       string
+			dirDrafts string
+			validate  func(c *modulir.Context, source string, allowlists *contentAllowlists) error
+		}
+
+		frontmatterDirs := []frontmatterDir{
+			{"/content/articles", "/content/drafts", validateArticleSource},
+			{"/content/fragments", "/content/fragments-drafts", validateFragmentSource},
+		}
+
+		newsletters, err := loadNewsletters(c)
+		problems = append(problems, validationProblems(c.SourceDir+"/"+newslettersFilename, 0, err)...)
+
+		for _, newsletter := range newsletters {
+			frontmatterDirs = append(frontmatterDirs, frontmatterDir{
+				"/" + newsletter.ContentDir, "/" + newsletter.ContentDirDrafts, validateIssueSource,
+			})
+		}
+
+		for _, d := range frontmatterDirs {
+			sources, err := mfile.ReadDir(c, c.SourceDir+d.dir)
+			if err != nil {
+				return nil, err
+			}
+
+			if conf.Drafts {
+				drafts, err := mfile.ReadDir(c, c.SourceDir+d.dirDrafts)
+				if err != nil {
+					return nil, err
+				}
+				sources = append(sources, drafts...)
+			}
+
+			for _, source := range sources {
+				// Frontmatter starts after the opening `+++` line.
+				problems = append(problems, validationProblems(source, 1, d.validate(c, source, allowlists))...)
+			}
+		}
+	}
+
+	//
+	// Atoms (`_meta.toml`)
+	//
+
+	{
+		source := c.SourceDir + "/content/atoms/_meta.toml"
+
+		var atomsWrapper AtomWrapper
+		err := mtoml.ParseFile(c, source, &atomsWrapper)
+		if err == nil {
+			err = atomsWrapper.validate()
+		}
+		problems = append(problems, validationProblems(source, 0, err)...)
+
+		for i, atom := range atomsWrapper.Atoms {
+			atom.DescriptionHTML = template.HTML(string(mmarkdown.Render(c, []byte(atom.Description))))
+
+			if err := atom.validateLength(); err != nil {
+				problems = append(problems, &validationProblem{
+					Err:    err,
+					Line:   sourceLineForNamespace(source, fmt.Sprintf("AtomWrapper.atoms[%d]", i)),
+					Source: source,
+				})
+			}
+
+			if err := checkTags(tagDefinitions, atom.Tags); err != nil {
+				problems = append(problems, &validationProblem{
+					Err:    err,
+					Line:   sourceLineForNamespace(source, fmt.Sprintf("AtomWrapper.atoms[%d].tags", i)),
+					Source: source,
+				})
+			}
+		}
+	}
+
+	//
+	// Photos (`_meta.toml` and `_other_meta.toml`)
+	//
+
+	for _, name := range []string{"_meta.toml", "_other_meta.toml"} {
+		source := c.SourceDir + "/content/photographs/" + name
+
+		var photosWrapper PhotoWrapper
+		err := mtoml.ParseFile(c, source, &photosWrapper)
+		if err == nil {
+			err = photosWrapper.validate()
+		}
+		problems = append(problems, validationProblems(source, 0, err)...)
+	}
+
+	//
+	// Sequences (`_meta.toml`)
+	//
+
+	{
+		source := c.SourceDir + "/content/sequences/_meta.toml"
+
+		var sequenceWrapper SequenceWrapper
+		err := mtoml.ParseFile(c, source, &sequenceWrapper)
+		if err == nil {
+			err = sequenceWrapper.validate()
+		}
+		problems = append(problems, validationProblems(source, 0, err)...)
+	}
+
+	return problems, nil
+}
+
+func validateArticleSource(c *modulir.Context, source string, allowlists *contentAllowlists) error {
+	var article Article
+	if _, err := mtoml.ParseFileFrontmatter(c, source, &article); err != nil {
+		return err
+	}
+
+	if err := article.validate(source); err != nil {
+		return err
+	}
+
+	if err := checkSeries(allowlists.series, article.Series); err != nil {
+		return err
+	}
+
+	return checkTags(allowlists.tags, article.Tags)
+}
+
+func validateFragmentSource(c *modulir.Context, source string, allowlists *contentAllowlists) error {
+	var fragment Fragment
+	if _, err := mtoml.ParseFileFrontmatter(c, source, &fragment); err != nil {
+		return err
+	}
+
+	if err := fragment.validate(source); err != nil {
+		return err
+	}
+
+	if err := checkSeries(allowlists.series, fragment.Series); err != nil {
+		return err
+	}
+
+	return checkTags(allowlists.tags, fragment.Tags)
+}
+
+func validateIssueSource(c *modulir.Context, source string, allowlists *contentAllowlists) error {
+	issue, err := snewsletter.Parse(c, filepath.Dir(source), filepath.Base(source))
+	if err != nil {
+		return err
+	}
+
+	return checkTags(allowlists.tags, issueTags(issue))
+}
+
+// validationProblems breaks an error returned while parsing or validating a
+// source into one or more problems, trying to find a line number for each.
+// Struct validation errors are split into one problem per failed field.
+//
+// lineOffset is added to line numbers reported by the TOML parser, and is
+// used to account for frontmatter not starting on the first line of a file.
+func validationProblems(source string, lineOffset int, err error) []*validationProblem {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		problems := make([]*validationProblem, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			problems[i] = &validationProblem{
+				Err:    fieldErr,
+				Line:   sourceLineForNamespace(source, fieldErr.Namespace()),
+				Source: source,
+			}
+		}
+		return problems
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		row, _ := decodeErr.Position()
+		return []*validationProblem{{Err: err, Line: row + lineOffset, Source: source}}
+	}
+
+	return []*validationProblem{{Err: err, Source: source}}
+}
+
+// sourceLineForNamespace reads a TOML source (or a Markdown source with TOML
+// frontmatter) and finds the line corresponding to a validator namespace like
+// `SequenceWrapper.entries[3].photographs[0].slug`. Returns zero if no line
+// could be found.
+func sourceLineForNamespace(source, namespace string) int {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return 0
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	// Only consider the frontmatter of files that have it so that a key that
+	// happens to appear in content isn't matched.
+	if len(lines) > 0 && lines[0] == "+++" {
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "+++" {
+				lines = lines[0:i]
+				break
+			}
+		}
+	}
+
+	return tomlLineForNamespace(lines, namespace)
+}
+
+var namespaceIndexRE = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// tomlLineForNamespace finds the 1-indexed line in a set of TOML lines that
+// corresponds to a validator namespace. Array elements like `entries[3]` are
+// matched to their `[[entries]]` table header, and plain fields to their
+// `key = ...` line within the current table. If a field can't be found (as
+// is the case for a missing required field), the line of its containing table
+// is returned instead, or zero if it's at the top level.
+func tomlLineForNamespace(lines []string, namespace string) int {
+	parts := strings.Split(namespace, ".")
+	if len(parts) < 2 {
+		return 0
+	}
+
+	var (
+		line  int    // 1-indexed line of the closest match so far
+		table string // fully qualified name of the current table
+	)
+
+	// The first part is always the name of the root struct, so skip it.
+	for _, part := range parts[1:] {
+		if matches := namespaceIndexRE.FindStringSubmatch(part); matches != nil {
+			if table == "" {
+				table = matches[1]
+			} else {
+				table += "." + matches[1]
+			}
+
+			index, _ := strconv.Atoi(matches[2])
+			header := "[[" + table + "]]"
+
+			var found bool
+			for i := line; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) != header {
+					continue
+				}
+
+				if index == 0 {
+					line = i + 1
+					found = true
+					break
+				}
+				index--
+			}
+
+			if !found {
+				return line
+			}
+
+			continue
+		}
+
+		keyRE := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(part) + `\s*=`)
+		for i := line; i < len(lines); i++ {
+			// Stop at the start of the next table.
+			if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+				break
+			}
+
+			if keyRE.MatchString(lines[i]) {
+				return i + 1
+			}
+		}
+
+		return line
+	}
+
+	return line
+}
+
+// tomlFieldName is a tag name function for the validator that makes it report
+// fields by their TOML key instead of their Go name, which is what a user
+// editing a content file will recognize.
+func tomlFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
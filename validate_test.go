@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/brandur/modulir"
+)
+
+func TestTomlLineForNamespace(t *testing.T) {
+	lines := strings.Split(`title = "Sequences"
+
+[[entries]]
+  slug = "001"
+  title = "First"
+  [[entries.photographs]]
+    slug = "001a"
+
+[[entries]]
+  slug = "002"
+  [[entries.photographs]]
+    slug = "002a"
+  [[entries.photographs]]
+    original_image_url = "https://example.com/002b.jpg"`, "\n")
+
+	require.Equal(t, 1, tomlLineForNamespace(lines, "SequenceWrapper.title"))
+	require.Equal(t, 3, tomlLineForNamespace(lines, "SequenceWrapper.entries[0]"))
+	require.Equal(t, 5, tomlLineForNamespace(lines, "SequenceWrapper.entries[0].title"))
+	require.Equal(t, 7, tomlLineForNamespace(lines, "SequenceWrapper.entries[0].photographs[0].slug"))
+	require.Equal(t, 10, tomlLineForNamespace(lines, "SequenceWrapper.entries[1].slug"))
+	require.Equal(t, 12, tomlLineForNamespace(lines, "SequenceWrapper.entries[1].photographs[0].slug"))
+
+	// Missing fields fall back to the line of their table.
+	require.Equal(t, 9, tomlLineForNamespace(lines, "SequenceWrapper.entries[1].title"))
+	require.Equal(t, 13, tomlLineForNamespace(lines, "SequenceWrapper.entries[1].photographs[1].slug"))
+
+	// Nothing to be found.
+	require.Equal(t, 0, tomlLineForNamespace(lines, "SequenceWrapper.description"))
+	require.Equal(t, 0, tomlLineForNamespace(lines, "SequenceWrapper"))
+}
+
+func TestValidateContent(t *testing.T) {
+	c := modulir.NewContext(&modulir.Args{
+		Log:       &modulir.Logger{Level: modulir.LevelInfo},
+		SourceDir: ".",
+	})
+
+	// All content checked into the repository should be valid.
+	problems, err := validateContent(c)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
+
+func TestValidationProblemString(t *testing.T) {
+	require.Equal(t, "content/articles/a.md:3: bad",
+		(&validationProblem{Err: xerrors.New("bad"), Line: 3, Source: "./content/articles/a.md"}).String())
+	require.Equal(t, "content/articles/a.md: bad",
+		(&validationProblem{Err: xerrors.New("bad"), Source: "./content/articles/a.md"}).String())
+}
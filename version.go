@@ -1,7 +0,0 @@
-package main
-
-const (
-	// Release is the asset version of the site. Bump when any assets are
-	// updated to blow away any browser caches.
-	Release = "100"
-)